@@ -2,67 +2,269 @@ package main
 
 import (
 	"context"
-	"fullcycle-auction_go/configuration/database/mongodb"
+	"errors"
+	"expvar"
+	"flag"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/blob_entity"
 	"fullcycle-auction_go/internal/infra/api/web/controller/auction_controller"
 	"fullcycle-auction_go/internal/infra/api/web/controller/bid_controller"
+	"fullcycle-auction_go/internal/infra/api/web/controller/category_controller"
+	"fullcycle-auction_go/internal/infra/api/web/controller/job_controller"
+	"fullcycle-auction_go/internal/infra/api/web/controller/leader_controller"
 	"fullcycle-auction_go/internal/infra/api/web/controller/user_controller"
-	"fullcycle-auction_go/internal/infra/database/auction"
-	"fullcycle-auction_go/internal/infra/database/bid"
-	"fullcycle-auction_go/internal/infra/database/user"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"fullcycle-auction_go/internal/infra/database/backend"
+	"fullcycle-auction_go/internal/infra/database/migration"
+	"fullcycle-auction_go/internal/infra/lifecycle"
+	"fullcycle-auction_go/internal/infra/storage"
+	"fullcycle-auction_go/internal/infra/wal"
 	"fullcycle-auction_go/internal/usecase/auction_usecase"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"fullcycle-auction_go/internal/usecase/category_usecase"
+	"fullcycle-auction_go/internal/usecase/job_usecase"
+	"fullcycle-auction_go/internal/usecase/leader_usecase"
 	"fullcycle-auction_go/internal/usecase/user_usecase"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func main() {
 	ctx := context.Background()
 
-	if err := godotenv.Load("cmd/auction/.env"); err != nil {
-		log.Fatal("Error trying to load env variables")
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	} else if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
 		return
 	}
+	config.Set(cfg)
+	log.Printf("Effective configuration:\n%s", strings.Join(cfg.Dump(), "\n"))
+
+	manager := lifecycle.NewManager()
+	manager.Go("config reloader", func(ctx context.Context) {
+		reloadConfig(ctx, os.Args[1:])
+	})
 
-	databaseConnection, err := mongodb.NewMongoDBConnection(ctx)
+	repositories, closeDatabase, err := backend.Open(ctx, cfg, clock.Real)
 	if err != nil {
 		log.Fatal(err.Error())
 		return
 	}
+	manager.OnShutdown("database", closeDatabase)
+
+	instanceId := getInstanceId(cfg)
+	if repositories.Mongo != nil {
+		if err := migrateMongo(ctx, cfg, repositories.Mongo, instanceId); err != nil {
+			log.Fatal(err.Error())
+			return
+		}
+	}
 
 	router := gin.Default()
 
-	userController, bidController, auctionsController := initDependencies(databaseConnection)
+	blobStore, err := storage.NewBlobStore(cfg, repositories.Mongo)
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	bidJournal, err := wal.NewBidJournal(cfg.BidWALDir, cfg.BidWALSegmentSize)
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+	manager.OnShutdown("bid journal", func(context.Context) error {
+		return bidJournal.Close()
+	})
+
+	userController, bidController, auctionsController, categoryController, leaderController, jobController :=
+		initDependencies(
+			ctx, manager, clock.Real, instanceId, repositories, blobStore, bidJournal)
 
 	router.GET("/auction", auctionsController.FindAuctions)
+	router.GET("/auction/suggest", auctionsController.SuggestProductNames)
+	router.GET("/auction/export", middleware.AdminOnly(), auctionsController.ExportAuctions)
 	router.GET("/auction/:auctionId", auctionsController.FindAuctionById)
 	router.POST("/auction", auctionsController.CreateAuction)
+	router.POST("/auction/import", auctionsController.ImportAuctions)
 	router.GET("/auction/winner/:auctionId", auctionsController.FindWinningBidByAuctionId)
+	router.POST("/auction/:auctionId/cancel", middleware.AdminOnly(), auctionsController.CancelAuction)
+	router.POST("/auction/:auctionId/extend", middleware.AdminOnly(), auctionsController.ExtendAuction)
+	router.POST("/auction/:auctionId/images", middleware.AdminOnly(), auctionsController.AddAuctionImage)
+	router.GET("/auction/:auctionId/images/:imageId", auctionsController.FindAuctionImage)
+	router.GET("/auction/:auctionId/images/:imageId/thumbnail", auctionsController.FindAuctionImageThumbnail)
+	router.DELETE("/auction/:auctionId/images/:imageId", middleware.AdminOnly(), auctionsController.RemoveAuctionImage)
 	router.POST("/bid", bidController.CreateBid)
 	router.GET("/bid/:auctionId", bidController.FindBidByAuctionId)
+	router.GET("/user", middleware.AdminOnly(), userController.FindUsers)
 	router.GET("/user/:userId", userController.FindUserById)
+	router.GET("/user/:userId/bids", userController.FindUserBids)
+	router.GET("/user/:userId/auctions/won", userController.FindUserWonAuctions)
 	router.POST("/user", userController.CreateUser)
+	router.PATCH("/user/:userId", middleware.AdminOnly(), userController.UpdateUser)
+	router.DELETE("/user/:userId", middleware.AdminOnly(), userController.DeactivateUser)
+	router.GET("/category", categoryController.FindCategoryTree)
+	router.GET("/category/:categoryId", categoryController.FindCategoryById)
+	router.POST("/category", middleware.AdminOnly(), categoryController.CreateCategory)
+	router.PATCH("/category/:categoryId", middleware.AdminOnly(), categoryController.UpdateCategory)
+	router.DELETE("/category/:categoryId", middleware.AdminOnly(), categoryController.DeleteCategory)
+	router.GET("/leader", leaderController.FindLeader)
+	router.GET("/job", middleware.AdminOnly(), jobController.FindJobs)
+	router.GET("/job/:jobId", middleware.AdminOnly(), jobController.FindJobById)
+	router.POST("/job/:jobId/retry", middleware.AdminOnly(), jobController.RetryJob)
+	router.POST("/job/:jobId/cancel", middleware.AdminOnly(), jobController.CancelJob)
+	router.GET("/debug/vars", middleware.AdminOnly(), gin.WrapH(expvar.Handler()))
+
+	server := &http.Server{Addr: ":8080", Handler: router}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err.Error())
+		}
+	}()
+	manager.OnShutdown("http server", server.Shutdown)
+
+	received := manager.Wait(syscall.SIGINT, syscall.SIGTERM)
+	logger.Info(fmt.Sprintf("Received %s, shutting down", received))
 
-	router.Run(":8080")
+	if err := manager.Shutdown(cfg.ShutdownTimeout); err != nil {
+		log.Fatal(err.Error())
+	}
 }
 
-func initDependencies(database *mongo.Database) (
+// initDependencies wires the repositories, use cases and controllers of the
+// selected backend, registering their background workers with manager.
+func initDependencies(
+	ctx context.Context,
+	manager *lifecycle.Manager,
+	clock clock.Clock,
+	instanceId string,
+	repositories *backend.Repositories,
+	blobStore blob_entity.BlobStore,
+	bidJournal bid_entity.BidJournal) (
 	userController *user_controller.UserController,
 	bidController *bid_controller.BidController,
-	auctionController *auction_controller.AuctionController) {
+	auctionController *auction_controller.AuctionController,
+	categoryController *category_controller.CategoryController,
+	leaderController *leader_controller.LeaderController,
+	jobController *job_controller.JobController) {
+
+	auctionRepository := repositories.Auctions
+	bidRepository := repositories.Bids
+	userRepository := repositories.Users
+	categoryRepository := repositories.Categories
+	leaseRepository := repositories.Leases
+	jobRepository := repositories.Jobs
 
-	auctionRepository := auction.NewAuctionRepository(database)
-	bidRepository := bid.NewBidRepository(database, auctionRepository)
-	userRepository := user.NewUserRepository(database)
+	// Only the instance holding the lease closes expired auctions.
+	closureScheduler := auction_usecase.NewAuctionClosureScheduler(auctionRepository, clock)
+	leaderUseCase := leader_usecase.NewLeaderUseCase(
+		leaseRepository, "auction-closure", instanceId, clock)
+	manager.Go("auction closure scheduler", func(ctx context.Context) {
+		leaderUseCase.RunElected(ctx, closureScheduler.Run)
+	})
+	leaderController = leader_controller.NewLeaderController(leaderUseCase)
+
+	jobUseCase := job_usecase.NewJobUseCase(jobRepository, clock, instanceId)
+	jobController = job_controller.NewJobController(jobUseCase)
 
 	userController = user_controller.NewUserController(
-		user_usecase.NewUserUseCase(userRepository))
-	auctionController = auction_controller.NewAuctionController(
-		auction_usecase.NewAuctionUseCase(auctionRepository, bidRepository))
-	bidController = bid_controller.NewBidController(bid_usecase.NewBidUseCase(bidRepository))
+		user_usecase.NewUserUseCase(userRepository, auctionRepository, bidRepository))
+	auctionUseCase := auction_usecase.NewAuctionUseCase(
+		auctionRepository, bidRepository, categoryRepository, blobStore, closureScheduler, jobUseCase, clock)
+	if err := auctionUseCase.ScheduleStartSweep(ctx); err != nil {
+		logger.Error("Error trying to schedule the sweep of overdue auctions", err)
+	}
+	auctionController = auction_controller.NewAuctionController(auctionUseCase)
+	bidUseCase := bid_usecase.NewBidUseCase(
+		bidRepository, userRepository, auctionRepository, bidJournal, clock)
+	manager.Go("bid batcher", bidUseCase.ProcessBids)
+	bidController = bid_controller.NewBidController(bidUseCase)
+	categoryController = category_controller.NewCategoryController(
+		category_usecase.NewCategoryUseCase(categoryRepository, auctionRepository, clock))
+
+	// Every instance runs jobs, once the use cases registered their handlers;
+	// claims keep each run to a single worker.
+	manager.Go("job runner", jobUseCase.Run)
 
 	return
 }
+
+// migrateMongo applies the pending MongoDB migrations, or only reports them
+// when MIGRATE_ON_STARTUP is off.
+func migrateMongo(ctx context.Context, cfg *config.Config, database *mongo.Database, instanceId string) error {
+	runner := migration.NewRunner(database, clock.Real, instanceId)
+
+	if !cfg.MigrateOnStartup {
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			if status.AppliedAt == nil {
+				log.Printf("Migration %d (%s) is pending; apply it with auctionctl migrate up",
+					status.Version, status.Description)
+			}
+		}
+		return nil
+	}
+
+	applied, err := runner.Up(ctx)
+	for _, step := range applied {
+		log.Printf("Applied migration %d (%s)", step.Version, step.Description)
+	}
+	return err
+}
+
+// reloadConfig loads the configuration again on every SIGHUP, applying the
+// tunables that changed; the other settings keep their values until a restart.
+func reloadConfig(ctx context.Context, args []string) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	defer signal.Stop(hangups)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangups:
+		}
+
+		cfg, err := config.Load(args)
+		if err != nil {
+			log.Printf("Invalid configuration, keeping the current one: %v", err)
+			continue
+		}
+
+		applied, ignored := config.Reload(cfg)
+		log.Printf("Configuration reloaded, applied %v; restart required for %v", applied, ignored)
+	}
+}
+
+// getInstanceId identifies this replica in leader election and job claims; it
+// must differ between replicas sharing a database.
+func getInstanceId(cfg *config.Config) string {
+	if cfg.InstanceId != "" {
+		return cfg.InstanceId
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "auction"
+	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
+}
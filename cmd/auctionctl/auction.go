@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func createAuction(ctx context.Context, app *app, args []string) error {
+	flags, format := newFlagSet("auction create")
+	productName := flags.String("product-name", "", "name of the product (required)")
+	categoryId := flags.String("category", "", "id of the category (required)")
+	description := flags.String("description", "", "description, 10 to 200 characters (required)")
+	condition := flags.String("condition", "new", "product condition, new or used")
+	startsAt := flags.String("starts-at", "", "RFC 3339 time the auction opens at; right away when empty")
+	if err := parseFlags(flags, format, args); err != nil {
+		return err
+	}
+
+	input := auction_usecase.AuctionInputDTO{
+		ProductName: *productName,
+		CategoryId:  *categoryId,
+		Description: *description,
+	}
+	if input.ProductName == "" || input.CategoryId == "" || input.Description == "" {
+		return errors.New("-product-name, -category and -description are required")
+	}
+
+	var err error
+	if input.Condition, err = parseCondition(*condition); err != nil {
+		return err
+	}
+
+	if *startsAt != "" {
+		startTime, err := time.Parse(time.RFC3339, *startsAt)
+		if err != nil {
+			return fmt.Errorf("invalid -starts-at: %w", err)
+		}
+		input.StartsAt = &startTime
+	}
+
+	if err := validateInput(&input); err != nil {
+		return err
+	}
+
+	auction, createErr := app.auctionUseCase.CreateAuction(ctx, input)
+	if createErr != nil {
+		return createErr
+	}
+
+	return write(app.out, *format, auction, auctionTable([]auction_usecase.AuctionOutputDTO{*auction}))
+}
+
+// validateInput applies the binding rules of the API to input, so the CLI
+// refuses what POST /auction would.
+func validateInput(input interface{}) error {
+	err := binding.Validator.ValidateStruct(input)
+	if err == nil {
+		return nil
+	}
+
+	restErr := validation.ValidateErr(err)
+	if len(restErr.Causes) == 0 {
+		return errors.New(restErr.Message)
+	}
+
+	messages := make([]string, 0, len(restErr.Causes))
+	for _, cause := range restErr.Causes {
+		messages = append(messages, cause.Message)
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+func listAuctions(ctx context.Context, app *app, args []string) error {
+	flags, format := newFlagSet("auction list")
+	status := flags.String("status", "", "only auctions with this status: active, completed, cancelled or scheduled")
+	query := flags.String("q", "", "full-text search on product, category and description")
+	categoryId := flags.String("category", "", "only auctions in this category or its subcategories")
+	sort := flags.String("sort", "", "newest, ending_soonest, starting_soonest, highest_bid, most_bids or relevance")
+	limit := flags.Int64("limit", 20, "auctions per page, 1 to 100")
+	after := flags.String("after", "", "cursor of the page to list")
+	all := flags.Bool("all", false, "follow the cursor through every page")
+	if err := parseFlags(flags, format, args); err != nil {
+		return err
+	}
+
+	if *limit < 1 || *limit > 100 {
+		return errors.New("-limit must be between 1 and 100")
+	}
+
+	input := auction_usecase.AuctionSearchInputDTO{
+		Query:    *query,
+		Category: *categoryId,
+		Sort:     *sort,
+		Limit:    *limit,
+		After:    *after,
+	}
+	if *status != "" {
+		auctionStatus, err := parseStatus(*status)
+		if err != nil {
+			return err
+		}
+		input.Status = &auctionStatus
+	}
+
+	list := &auction_usecase.AuctionListOutputDTO{Auctions: []auction_usecase.AuctionOutputDTO{}}
+	for {
+		page, err := app.auctionUseCase.FindAuctions(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		list.Auctions = append(list.Auctions, page.Auctions...)
+		list.NextCursor = page.NextCursor
+		list.Total = page.Total
+
+		if !*all || page.NextCursor == "" {
+			break
+		}
+		input.After = page.NextCursor
+	}
+
+	result := auctionTable(list.Auctions)
+	result.footer = fmt.Sprintf("%d of %d auctions", len(list.Auctions), list.Total)
+	if list.NextCursor != "" {
+		result.footer += ", next page: -after " + list.NextCursor
+	}
+
+	return write(app.out, *format, list, result)
+}
+
+func cancelAuctions(ctx context.Context, app *app, args []string) error {
+	return app.updateAuctions(ctx, "auction cancel", args,
+		func(auctionId string) (*auction_usecase.AuctionOutputDTO, *internal_error.InternalError) {
+			if err := app.auctionUseCase.CancelAuction(ctx, auctionId); err != nil {
+				return nil, err
+			}
+			return app.auctionUseCase.FindAuctionById(ctx, auctionId)
+		})
+}
+
+func closeAuctions(ctx context.Context, app *app, args []string) error {
+	return app.updateAuctions(ctx, "auction close", args,
+		func(auctionId string) (*auction_usecase.AuctionOutputDTO, *internal_error.InternalError) {
+			return app.auctionUseCase.ForceCloseAuction(ctx, auctionId)
+		})
+}
+
+// updateAuctions applies update to every auction id given to the command,
+// printing the updated auctions before the first failure, if any.
+func (app *app) updateAuctions(
+	ctx context.Context,
+	name string,
+	args []string,
+	update func(auctionId string) (*auction_usecase.AuctionOutputDTO, *internal_error.InternalError)) error {
+	flags, format := newFlagSet(name)
+	if err := parseFlags(flags, format, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("%s expects at least one auction id", name)
+	}
+
+	auctions := []auction_usecase.AuctionOutputDTO{}
+	var failure error
+	for _, auctionId := range flags.Args() {
+		auction, err := update(auctionId)
+		if err != nil {
+			failure = fmt.Errorf("auction %s: %w", auctionId, err)
+			break
+		}
+		auctions = append(auctions, *auction)
+	}
+
+	if failure != nil && len(auctions) == 0 {
+		return failure
+	}
+	if err := write(app.out, *format, auctions, auctionTable(auctions)); err != nil {
+		return err
+	}
+	return failure
+}
+
+func recomputeAuctions(ctx context.Context, app *app, args []string) error {
+	flags, format := newFlagSet("auction recompute")
+	all := flags.Bool("all", false, "recompute every completed auction instead of the given ids")
+	if err := parseFlags(flags, format, args); err != nil {
+		return err
+	}
+
+	auctionIds := flags.Args()
+	if *all {
+		var err error
+		if auctionIds, err = app.completedAuctionIds(ctx); err != nil {
+			return err
+		}
+	} else if len(auctionIds) == 0 {
+		return errors.New("auction recompute expects auction ids or -all")
+	}
+
+	winners := []auction_usecase.WinningInfoOutputDTO{}
+	result := table{header: []string{"AUCTION", "PRODUCT", "STATUS", "HIGHEST BID", "BIDS", "WINNER", "WINNING BID"}}
+	var failure error
+	for _, auctionId := range auctionIds {
+		winner, err := app.auctionUseCase.RecomputeWinner(ctx, auctionId)
+		if err != nil {
+			failure = fmt.Errorf("auction %s: %w", auctionId, err)
+			break
+		}
+		winners = append(winners, *winner)
+
+		auction := winner.Auction
+		row := []string{
+			auction.Id,
+			auction.ProductName,
+			auction_entity.AuctionStatus(auction.Status).String(),
+			formatAmount(auction.HighestBid),
+			strconv.FormatInt(auction.BidCount, 10),
+			"-",
+			"-",
+		}
+		if winner.Bid != nil {
+			row[5] = winner.Bid.UserId
+			row[6] = formatAmount(winner.Bid.Amount)
+		}
+		result.rows = append(result.rows, row)
+	}
+
+	if failure != nil && len(winners) == 0 {
+		return failure
+	}
+	if err := write(app.out, *format, winners, result); err != nil {
+		return err
+	}
+	return failure
+}
+
+func (app *app) completedAuctionIds(ctx context.Context) ([]string, error) {
+	status := auction_usecase.AuctionStatus(auction_entity.Completed)
+	input := auction_usecase.AuctionSearchInputDTO{Status: &status, Limit: 100}
+
+	var auctionIds []string
+	for {
+		page, err := app.auctionUseCase.FindAuctions(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, auction := range page.Auctions {
+			auctionIds = append(auctionIds, auction.Id)
+		}
+
+		if page.NextCursor == "" {
+			return auctionIds, nil
+		}
+		input.After = page.NextCursor
+	}
+}
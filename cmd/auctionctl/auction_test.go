@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/infra/database/backend"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"fullcycle-auction_go/internal/infra/storage"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"fullcycle-auction_go/internal/usecase/category_usecase"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestApp builds the commands over the memory repositories and a fake
+// clock, writing their output to the returned buffer.
+func newTestApp(t *testing.T) (*app, *bytes.Buffer) {
+	t.Helper()
+
+	fake := clock.NewFake(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	blobStore, err := storage.NewFileSystemBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileSystemBlobStore: %v", err)
+	}
+
+	auctions := memory.NewAuctionRepository(fake)
+	repositories := &backend.Repositories{
+		Auctions:   auctions,
+		Bids:       memory.NewBidRepository(auctions),
+		Users:      memory.NewUserRepository(),
+		Categories: memory.NewCategoryRepository(),
+		Leases:     memory.NewLeaseRepository(fake),
+		Jobs:       memory.NewJobRepository(),
+	}
+
+	out := &bytes.Buffer{}
+	return newApp(out, repositories, blobStore, fake), out
+}
+
+func mustCreateCategory(t *testing.T, app *app) string {
+	t.Helper()
+
+	category, err := app.categoryUseCase.CreateCategory(context.Background(), category_usecase.CategoryInputDTO{
+		Name: "Watches",
+	})
+	if err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	return category.Id
+}
+
+func TestCreateAuctionAppliesTheAPIRules(t *testing.T) {
+	app, out := newTestApp(t)
+	categoryId := mustCreateCategory(t, app)
+	args := []string{"-o", "json", "-product-name", "Pocket Watch", "-category", categoryId,
+		"-description", "A pocket watch from 1960"}
+
+	err := createAuction(context.Background(), app, append(args, "-condition", "refurbished"))
+	if err == nil || !strings.Contains(err.Error(), "Condition") {
+		t.Fatalf("expected the condition POST /auction refuses to be refused, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no output for a refused auction, got %q", out.String())
+	}
+
+	if err := createAuction(context.Background(), app, append(args, "-condition", "used")); err != nil {
+		t.Fatalf("createAuction: %v", err)
+	}
+
+	var created auction_usecase.AuctionOutputDTO
+	if err := json.Unmarshal(out.Bytes(), &created); err != nil {
+		t.Fatalf("decoding the output: %v", err)
+	}
+
+	stored, findErr := app.repositories.Auctions.FindAuctionById(context.Background(), created.Id)
+	if findErr != nil {
+		t.Fatalf("FindAuctionById: %v", findErr)
+	}
+	if stored.Condition != auction_entity.Used || stored.Status != auction_entity.Active {
+		t.Errorf("unexpected auction %+v", stored)
+	}
+}
+
+func TestCancelAndCloseAuctions(t *testing.T) {
+	app, out := newTestApp(t)
+	categoryId := mustCreateCategory(t, app)
+
+	var ids []string
+	for _, product := range []string{"Pocket Watch", "Film Camera"} {
+		auction, err := app.auctionUseCase.CreateAuction(context.Background(), auction_usecase.AuctionInputDTO{
+			ProductName: product,
+			CategoryId:  categoryId,
+			Description: "An auction created by the tests",
+			Condition:   auction_usecase.ProductCondition(auction_entity.New),
+		})
+		if err != nil {
+			t.Fatalf("CreateAuction: %v", err)
+		}
+		ids = append(ids, auction.Id)
+	}
+
+	if err := cancelAuctions(context.Background(), app, []string{ids[0]}); err != nil {
+		t.Fatalf("cancelAuctions: %v", err)
+	}
+	if err := closeAuctions(context.Background(), app, []string{ids[1]}); err != nil {
+		t.Fatalf("closeAuctions: %v", err)
+	}
+
+	for id, want := range map[string]auction_entity.AuctionStatus{
+		ids[0]: auction_entity.Cancelled,
+		ids[1]: auction_entity.Completed,
+	} {
+		stored, err := app.repositories.Auctions.FindAuctionById(context.Background(), id)
+		if err != nil {
+			t.Fatalf("FindAuctionById: %v", err)
+		}
+		if stored.Status != want {
+			t.Errorf("expected auction %s to be %s, got %s", id, want, stored.Status)
+		}
+	}
+
+	// The auction is already closed, so the command fails without output.
+	out.Reset()
+	if err := closeAuctions(context.Background(), app, []string{ids[1]}); err == nil {
+		t.Error("expected closing a completed auction to fail")
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no output, got %q", out.String())
+	}
+}
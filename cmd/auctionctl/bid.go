@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/usecase/bid_usecase"
+)
+
+func listBids(ctx context.Context, app *app, args []string) error {
+	flags, format := newFlagSet("bid list")
+	if err := parseFlags(flags, format, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("bid list expects one auction id")
+	}
+
+	bids, err := app.repositories.Bids.FindBidByAuctionId(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	output := newBidOutputs(bids)
+	result := table{header: []string{"ID", "USER", "AMOUNT", "TIMESTAMP"}}
+	for _, bid := range output {
+		result.rows = append(result.rows, []string{
+			bid.Id, bid.UserId, formatAmount(bid.Amount), formatTime(bid.Timestamp)})
+	}
+	result.footer = fmt.Sprintf("%d bids", len(output))
+
+	return write(app.out, *format, output, result)
+}
+
+func newBidOutputs(bids []bid_entity.Bid) []bid_usecase.BidOutputDTO {
+	output := make([]bid_usecase.BidOutputDTO, 0, len(bids))
+	for i := range bids {
+		output = append(output, bid_usecase.NewBidOutputDTO(&bids[i]))
+	}
+	return output
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"fullcycle-auction_go/internal/infra/export"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"io"
+	"os"
+	"time"
+)
+
+func exportAuctions(ctx context.Context, app *app, args []string) error {
+	return runExport(app, "export auctions", args,
+		func(input auction_usecase.AuctionExportInputDTO,
+			fn func(row auction_usecase.AuctionExportDTO) error) *internal_error.InternalError {
+			return app.auctionUseCase.ExportAuctions(ctx, input, fn)
+		})
+}
+
+func exportBids(ctx context.Context, app *app, args []string) error {
+	return runExport(app, "export bids", args,
+		func(input auction_usecase.AuctionExportInputDTO,
+			fn func(row auction_usecase.BidExportDTO) error) *internal_error.InternalError {
+			return app.auctionUseCase.ExportBids(ctx, input, fn)
+		})
+}
+
+// runExport parses the filters shared by the export commands and writes the
+// rows produced by run to the output file, or stdout.
+func runExport[T any](
+	app *app,
+	name string,
+	args []string,
+	run func(input auction_usecase.AuctionExportInputDTO, fn func(row T) error) *internal_error.InternalError) error {
+	flags := flag.NewFlagSet("auctionctl "+name, flag.ContinueOnError)
+	format := flags.String("o", string(export.CSV), "file format, csv, ndjson or parquet")
+	file := flags.String("file", "", "file to write; stdout when empty")
+	status := flags.String("status", "", "only auctions with this status: active, completed, cancelled or scheduled")
+	category := flags.String("category", "", "only auctions in this category or its subcategories")
+
+	var input auction_usecase.AuctionExportInputDTO
+	timeFlag(flags, "created-from", "only auctions created at or after this RFC 3339 time", &input.CreatedFrom)
+	timeFlag(flags, "created-to", "only auctions created at or before this RFC 3339 time", &input.CreatedTo)
+	timeFlag(flags, "ended-from", "only auctions ending at or after this RFC 3339 time", &input.EndedFrom)
+	timeFlag(flags, "ended-to", "only auctions ending at or before this RFC 3339 time", &input.EndedTo)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	exportFormat, err := export.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	input.Category = *category
+	if *status != "" {
+		auctionStatus, err := parseStatus(*status)
+		if err != nil {
+			return err
+		}
+		input.Status = &auctionStatus
+	}
+
+	if *file == "" {
+		_, err := writeExport(app.out, exportFormat, input, run)
+		return err
+	}
+
+	created, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+
+	rows, err := writeExport(created, exportFormat, input, run)
+	if closeErr := created.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*file)
+		return err
+	}
+
+	fmt.Fprintf(app.out, "%d rows written to %s\n", rows, *file)
+	return nil
+}
+
+func writeExport[T any](
+	out io.Writer,
+	format export.Format,
+	input auction_usecase.AuctionExportInputDTO,
+	run func(input auction_usecase.AuctionExportInputDTO, fn func(row T) error) *internal_error.InternalError) (int, error) {
+	writer, err := export.NewWriter[T](format, out)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	exportErr := run(input, func(row T) error {
+		rows++
+		return writer.Write(row)
+	})
+	if exportErr != nil {
+		return rows, exportErr
+	}
+
+	return rows, writer.Close()
+}
+
+func timeFlag(flags *flag.FlagSet, name, usage string, value **time.Time) {
+	flags.Func(name, usage, func(text string) error {
+		parsed, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return err
+		}
+		*value = &parsed
+		return nil
+	})
+}
@@ -0,0 +1,171 @@
+// Command auctionctl administers the auction store through the same use cases
+// and repositories as the server.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/blob_entity"
+	"fullcycle-auction_go/internal/infra/database/backend"
+	"fullcycle-auction_go/internal/infra/storage"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"fullcycle-auction_go/internal/usecase/category_usecase"
+	"fullcycle-auction_go/internal/usecase/job_usecase"
+	"fullcycle-auction_go/internal/usecase/user_usecase"
+	"io"
+	"os"
+	"strings"
+)
+
+const usage = `Usage: auctionctl [configuration flags] <command> [flags] [arguments]
+
+Commands:
+  auction create     create an auction
+  auction list       list auctions
+  auction cancel     cancel active or scheduled auctions
+  auction close      close active auctions right away
+  auction recompute  recompute the highest bid, bid count and winner of auctions
+  bid list           list the bids of an auction
+  export auctions    export auctions as CSV, NDJSON or Parquet
+  export bids        export the bids of auctions as CSV, NDJSON or Parquet
+  seed               create test users, categories, auctions and bids
+  migrate status     list the MongoDB migrations and whether they were applied
+  migrate up         apply the pending migrations
+  migrate down       revert the latest MongoDB migrations
+
+Every command accepts -o table|json, except export, whose -o is the file
+format: csv, ndjson or parquet. The configuration flags and sources are
+the same as the server's; run "auctionctl -h" to list them.
+`
+
+type command struct {
+	name string
+	run  func(ctx context.Context, app *app, args []string) error
+}
+
+var commands = []command{
+	{"auction create", createAuction},
+	{"auction list", listAuctions},
+	{"auction cancel", cancelAuctions},
+	{"auction close", closeAuctions},
+	{"auction recompute", recomputeAuctions},
+	{"bid list", listBids},
+	{"export auctions", exportAuctions},
+	{"export bids", exportBids},
+	{"seed", seed},
+	{"migrate status", migrateStatus},
+	{"migrate up", migrateUp},
+	{"migrate down", migrateDown},
+}
+
+// app holds the repositories and use cases the commands run against.
+type app struct {
+	out             io.Writer
+	repositories    *backend.Repositories
+	clock           clock.Clock
+	auctionUseCase  auction_usecase.AuctionUseCaseInterface
+	categoryUseCase category_usecase.CategoryUseCaseInterface
+	userUseCase     user_usecase.UserUseCaseInterface
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "auctionctl: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	cfg, args, err := config.Parse("auctionctl", args)
+	if err != nil {
+		return err
+	}
+
+	command, args, ok := findCommand(args)
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		return flag.ErrHelp
+	}
+
+	if cfg.StorageBackend == config.MemoryBackend {
+		return errors.New("the memory backend keeps no data between runs; select another STORAGE_BACKEND")
+	}
+	config.Set(cfg)
+
+	repositories, closeDatabase, err := backend.Open(ctx, cfg, clock.Real)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(ctx)
+
+	blobStore, err := storage.NewBlobStore(cfg, repositories.Mongo)
+	if err != nil {
+		return err
+	}
+
+	return command.run(ctx, newApp(out, repositories, blobStore, clock.Real), args)
+}
+
+// newApp builds the use cases of the commands over repositories. Neither the
+// job runner nor the closure scheduler run here: the jobs and end times
+// stored by the commands are picked up by the server.
+func newApp(
+	out io.Writer,
+	repositories *backend.Repositories,
+	blobStore blob_entity.BlobStore,
+	clock clock.Clock) *app {
+	jobUseCase := job_usecase.NewJobUseCase(repositories.Jobs, clock, "auctionctl")
+	closureScheduler := auction_usecase.NewAuctionClosureScheduler(repositories.Auctions, clock)
+
+	return &app{
+		out:          out,
+		repositories: repositories,
+		clock:        clock,
+		auctionUseCase: auction_usecase.NewAuctionUseCase(
+			repositories.Auctions, repositories.Bids, repositories.Categories,
+			blobStore, closureScheduler, jobUseCase, clock),
+		categoryUseCase: category_usecase.NewCategoryUseCase(
+			repositories.Categories, repositories.Auctions, clock),
+		userUseCase: user_usecase.NewUserUseCase(
+			repositories.Users, repositories.Auctions, repositories.Bids),
+	}
+}
+
+// findCommand matches the command named by the first arguments, returning
+// the arguments left after its name.
+func findCommand(args []string) (command, []string, bool) {
+	for _, command := range commands {
+		words := strings.Fields(command.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == command.name {
+			return command, args[len(words):], true
+		}
+	}
+
+	return command{}, nil, false
+}
+
+// newFlagSet returns the flags of a command, with the -o output format every
+// command accepts.
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet("auctionctl "+name, flag.ContinueOnError)
+	format := flags.String("o", tableFormat, "output format, table or json")
+	return flags, format
+}
+
+// parseFlags parses the flags of a command and checks its output format.
+func parseFlags(flags *flag.FlagSet, format *string, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != tableFormat && *format != jsonFormat {
+		return fmt.Errorf("unknown output format %q, expected table or json", *format)
+	}
+	return nil
+}
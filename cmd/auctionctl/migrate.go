@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/infra/database/migration"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type migrationOutput struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+}
+
+type sqlMigrateOutput struct {
+	StorageBackend string `json:"storage_backend"`
+	Status         string `json:"status"`
+}
+
+func migrateStatus(ctx context.Context, app *app, args []string) error {
+	flags, format := newFlagSet("migrate status")
+	if err := parseFlags(flags, format, args); err != nil {
+		return err
+	}
+
+	runner := app.migrationRunner()
+	if runner == nil {
+		return writeSQLMigrations(app, *format)
+	}
+
+	statuses, err := runner.Status(ctx)
+	if err != nil {
+		return err
+	}
+	return write(app.out, *format, statuses, migrationTable(statuses))
+}
+
+func migrateUp(ctx context.Context, app *app, args []string) error {
+	flags, format := newFlagSet("migrate up")
+	if err := parseFlags(flags, format, args); err != nil {
+		return err
+	}
+
+	runner := app.migrationRunner()
+	if runner == nil {
+		return writeSQLMigrations(app, *format)
+	}
+
+	applied, err := runner.Up(ctx)
+	if writeErr := writeMigrations(app, *format, applied); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+func migrateDown(ctx context.Context, app *app, args []string) error {
+	flags, format := newFlagSet("migrate down")
+	steps := flags.Int("steps", 1, "migrations to revert, latest first")
+	if err := parseFlags(flags, format, args); err != nil {
+		return err
+	}
+	if *steps < 1 {
+		return errors.New("-steps must be at least 1")
+	}
+
+	runner := app.migrationRunner()
+	if runner == nil {
+		return errors.New("reverting migrations is only supported on the mongodb backend")
+	}
+
+	reverted, err := runner.Down(ctx, *steps)
+	if writeErr := writeMigrations(app, *format, reverted); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+// migrationRunner returns the runner of the MongoDB migrations, or nil on the
+// SQL backends, whose schema migrations are applied when the database is
+// opened.
+func (app *app) migrationRunner() *migration.Runner {
+	if app.repositories.Mongo == nil {
+		return nil
+	}
+	return migration.NewRunner(app.repositories.Mongo, clock.Real, "auctionctl-"+uuid.New().String()[:8])
+}
+
+func writeSQLMigrations(app *app, format string) error {
+	output := sqlMigrateOutput{StorageBackend: config.Current().StorageBackend, Status: "up to date"}
+	return write(app.out, format, output, table{
+		header: []string{"STORAGE BACKEND", "STATUS"},
+		rows:   [][]string{{output.StorageBackend, output.Status}},
+	})
+}
+
+// writeMigrations prints the migrations a command applied or reverted.
+func writeMigrations(app *app, format string, migrations []migration.Migration) error {
+	output := make([]migrationOutput, 0, len(migrations))
+	result := table{header: []string{"VERSION", "DESCRIPTION"}}
+	for _, step := range migrations {
+		output = append(output, migrationOutput{Version: step.Version, Description: step.Description})
+		result.rows = append(result.rows, []string{strconv.Itoa(step.Version), step.Description})
+	}
+	result.footer = strconv.Itoa(len(migrations)) + " migrations"
+
+	return write(app.out, format, output, result)
+}
+
+func migrationTable(statuses []migration.Status) table {
+	result := table{header: []string{"VERSION", "DESCRIPTION", "APPLIED AT"}}
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = formatTime(*status.AppliedAt)
+		}
+		result.rows = append(result.rows, []string{
+			strconv.Itoa(status.Version), status.Description, appliedAt})
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	tableFormat = "table"
+	jsonFormat  = "json"
+)
+
+// table is the tabular form of a command result. footer, when set, is
+// printed below the rows.
+type table struct {
+	header []string
+	rows   [][]string
+	footer string
+}
+
+// write prints value as indented JSON, or its table form.
+func write(out io.Writer, format string, value interface{}, result table) error {
+	if format == jsonFormat {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	writer := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(result.header, "\t"))
+	for _, row := range result.rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	if result.footer != "" {
+		fmt.Fprintln(out, result.footer)
+	}
+	return nil
+}
+
+func parseStatus(name string) (auction_usecase.AuctionStatus, error) {
+	for _, status := range []auction_entity.AuctionStatus{
+		auction_entity.Active, auction_entity.Completed, auction_entity.Cancelled, auction_entity.Scheduled} {
+		if status.String() == name {
+			return auction_usecase.AuctionStatus(status), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown status %q, expected active, completed, cancelled or scheduled", name)
+}
+
+func parseCondition(name string) (auction_usecase.ProductCondition, error) {
+	for _, condition := range []auction_entity.ProductCondition{
+		auction_entity.New, auction_entity.Used, auction_entity.Refurbished} {
+		if condition.String() == name {
+			return auction_usecase.ProductCondition(condition), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown condition %q, expected new, used or refurbished", name)
+}
+
+func auctionTable(auctions []auction_usecase.AuctionOutputDTO) table {
+	result := table{header: []string{
+		"ID", "PRODUCT", "CATEGORY", "CONDITION", "STATUS", "STARTS AT", "ENDS AT", "HIGHEST BID", "BIDS"}}
+	for _, auction := range auctions {
+		result.rows = append(result.rows, []string{
+			auction.Id,
+			auction.ProductName,
+			auction.Category,
+			auction_entity.ProductCondition(auction.Condition).String(),
+			auction_entity.AuctionStatus(auction.Status).String(),
+			formatTime(auction.StartsAt),
+			formatTime(auction.EndTime),
+			formatAmount(auction.HighestBid),
+			strconv.FormatInt(auction.BidCount, 10),
+		})
+	}
+	return result
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format(time.RFC3339)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"fullcycle-auction_go/internal/usecase/category_usecase"
+	"fullcycle-auction_go/internal/usecase/user_usecase"
+	"math/rand"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+var seedProducts = []string{
+	"Vintage Watch", "Mountain Bike", "Acoustic Guitar", "Film Camera", "Leather Jacket",
+	"Espresso Machine", "Mechanical Keyboard", "Oil Painting", "Record Player", "Chess Set",
+}
+
+type seedOutput struct {
+	Users      []string `json:"users"`
+	Categories []string `json:"categories"`
+	Auctions   []string `json:"auctions"`
+	Bids       int      `json:"bids"`
+}
+
+func seed(ctx context.Context, app *app, args []string) error {
+	flags, format := newFlagSet("seed")
+	users := flags.Int("users", 5, "users to create")
+	categories := flags.Int("categories", 2, "categories to create")
+	auctions := flags.Int("auctions", 10, "auctions to create, spread over the categories")
+	bids := flags.Int("bids", 5, "bids to place on each auction, from random users")
+	if err := parseFlags(flags, format, args); err != nil {
+		return err
+	}
+
+	if *users < 0 || *categories < 0 || *auctions < 0 || *bids < 0 {
+		return errors.New("seed counts must not be negative")
+	}
+	if *auctions > 0 && *categories == 0 {
+		return errors.New("auctions need at least one category")
+	}
+	if *bids > 0 && *auctions > 0 && *users == 0 {
+		return errors.New("bids need at least one user")
+	}
+
+	// Names and emails carry a run id so seeding again never conflicts.
+	run := uuid.New().String()[:8]
+	output := seedOutput{Users: []string{}, Categories: []string{}, Auctions: []string{}}
+
+	for i := 1; i <= *users; i++ {
+		user, err := app.userUseCase.CreateUser(ctx, user_usecase.UserInputDTO{
+			Name:  fmt.Sprintf("Seed User %s-%d", run, i),
+			Email: fmt.Sprintf("seed-%s-%d@example.com", run, i),
+		})
+		if err != nil {
+			return fmt.Errorf("creating user: %w", err)
+		}
+		output.Users = append(output.Users, user.Id)
+	}
+
+	for i := 1; i <= *categories; i++ {
+		category, err := app.categoryUseCase.CreateCategory(ctx, category_usecase.CategoryInputDTO{
+			Name: fmt.Sprintf("Seed %s-%d", run, i),
+		})
+		if err != nil {
+			return fmt.Errorf("creating category: %w", err)
+		}
+		output.Categories = append(output.Categories, category.Id)
+	}
+
+	for i := 0; i < *auctions; i++ {
+		product := seedProducts[i%len(seedProducts)]
+		auction, err := app.auctionUseCase.CreateAuction(ctx, auction_usecase.AuctionInputDTO{
+			ProductName: product,
+			CategoryId:  output.Categories[i%len(output.Categories)],
+			Description: fmt.Sprintf("%s seeded for testing (%s)", product, run),
+			// New and used, the conditions POST /auction accepts.
+			Condition: auction_usecase.ProductCondition(i%2 + 1),
+		})
+		if err != nil {
+			return fmt.Errorf("creating auction: %w", err)
+		}
+		output.Auctions = append(output.Auctions, auction.Id)
+
+		if *bids == 0 || len(output.Users) == 0 {
+			continue
+		}
+
+		stored, bidErr := app.placeSeedBids(ctx, auction.Id, output.Users, *bids)
+		if bidErr != nil {
+			return bidErr
+		}
+		output.Bids += stored
+	}
+
+	return write(app.out, *format, output, table{
+		header: []string{"KIND", "CREATED"},
+		rows: [][]string{
+			{"users", strconv.Itoa(len(output.Users))},
+			{"categories", strconv.Itoa(len(output.Categories))},
+			{"auctions", strconv.Itoa(len(output.Auctions))},
+			{"bids", strconv.Itoa(output.Bids)},
+		},
+	})
+}
+
+// placeSeedBids stores count increasing bids on an auction through the bid
+// repository, bypassing the server's bid queue.
+func (app *app) placeSeedBids(ctx context.Context, auctionId string, userIds []string, count int) (int, error) {
+	amount := float64(10 + rand.Intn(90))
+	bids := make([]bid_entity.Bid, 0, count)
+	for i := 0; i < count; i++ {
+		amount += float64(1 + rand.Intn(20))
+		bids = append(bids, bid_entity.Bid{
+			Id:        uuid.New().String(),
+			UserId:    userIds[rand.Intn(len(userIds))],
+			AuctionId: auctionId,
+			Amount:    amount,
+			Timestamp: app.clock.Now(),
+		})
+	}
+
+	results, err := app.repositories.Bids.CreateBid(ctx, bids)
+	if err != nil {
+		return 0, fmt.Errorf("placing bids on auction %s: %w", auctionId, err)
+	}
+
+	stored := 0
+	for _, result := range results {
+		if result.Status == bid_entity.BidStored {
+			stored++
+		}
+	}
+	return stored, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"testing"
+)
+
+func TestSeedCreatesAuctionsTheAPIAccepts(t *testing.T) {
+	app, out := newTestApp(t)
+
+	args := []string{"-o", "json", "-users", "2", "-categories", "2", "-auctions", "4", "-bids", "3"}
+	if err := seed(context.Background(), app, args); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	var output seedOutput
+	if err := json.Unmarshal(out.Bytes(), &output); err != nil {
+		t.Fatalf("decoding the output: %v", err)
+	}
+	if len(output.Users) != 2 || len(output.Categories) != 2 || len(output.Auctions) != 4 || output.Bids != 12 {
+		t.Fatalf("unexpected seed output %+v", output)
+	}
+
+	for _, auctionId := range output.Auctions {
+		auction, err := app.repositories.Auctions.FindAuctionById(context.Background(), auctionId)
+		if err != nil {
+			t.Fatalf("FindAuctionById: %v", err)
+		}
+		if auction.BidCount != 3 {
+			t.Errorf("expected 3 bids on auction %s, got %d", auctionId, auction.BidCount)
+		}
+
+		bids, err := app.repositories.Bids.FindBidByAuctionId(context.Background(), auctionId)
+		if err != nil {
+			t.Fatalf("FindBidByAuctionId: %v", err)
+		}
+		for _, bid := range bids {
+			if !bid.Timestamp.Equal(app.clock.Now()) {
+				t.Errorf("expected bid %s to be placed at %v, got %v", bid.Id, app.clock.Now(), bid.Timestamp)
+			}
+		}
+
+		// Seeded auctions must pass the binding rules of POST /auction.
+		input := auction_usecase.AuctionInputDTO{
+			ProductName: auction.ProductName,
+			CategoryId:  auction.CategoryId,
+			Description: auction.Description,
+			Condition:   auction_usecase.ProductCondition(auction.Condition),
+		}
+		if err := validateInput(&input); err != nil {
+			t.Errorf("auction %s would be refused by the API: %v", auctionId, err)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+// Package config holds the application settings. They are loaded from, in
+// increasing order of precedence, the defaults below, an optional .env file,
+// an optional YAML or TOML file, the environment and command line flags.
+//
+// Fields tagged reload are tunables: Reload applies new values for them to the
+// running process, while changes to the other fields need a restart.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	MongoBackend    = "mongodb"
+	MemoryBackend   = "memory"
+	SQLiteBackend   = "sqlite"
+	PostgresBackend = "postgres"
+)
+
+type Config struct {
+	StorageBackend  string        `key:"STORAGE_BACKEND" default:"mongodb" oneof:"mongodb memory sqlite postgres"`
+	SQLDSN          string        `key:"SQL_DSN" secret:"true"`
+	MongoDBURL      string        `key:"MONGODB_URL" secret:"true"`
+	MongoDBDB       string        `key:"MONGODB_DB"`
+	AdminToken      string        `key:"ADMIN_TOKEN" secret:"true" reload:"true"`
+	InstanceId      string        `key:"INSTANCE_ID"`
+	ShutdownTimeout time.Duration `key:"SHUTDOWN_TIMEOUT" default:"30s" min:"1s"`
+
+	MigrateOnStartup bool `key:"MIGRATE_ON_STARTUP" default:"true"`
+
+	BlobStore        string `key:"BLOB_STORE" default:"filesystem" oneof:"filesystem gridfs"`
+	BlobStorePath    string `key:"BLOB_STORE_PATH" default:"data/blobs"`
+	MaxImageSize     int64  `key:"MAX_IMAGE_SIZE" default:"5242880" min:"1024" reload:"true"`
+	MaxAuctionImages int    `key:"MAX_AUCTION_IMAGES" default:"10" min:"1" max:"100" reload:"true"`
+
+	MaxImportSize   int64 `key:"MAX_IMPORT_SIZE" default:"10485760" min:"1024" reload:"true"`
+	MaxImportRows   int   `key:"MAX_IMPORT_ROWS" default:"5000" min:"1" max:"100000" reload:"true"`
+	ImportBatchSize int   `key:"IMPORT_BATCH_SIZE" default:"100" min:"1" max:"1000" reload:"true"`
+
+	AuctionDuration        time.Duration `key:"AUCTION_DURATION" default:"10m" min:"1s" reload:"true"`
+	AuctionSchedulerResync time.Duration `key:"AUCTION_SCHEDULER_RESYNC" default:"30s" min:"1s" reload:"true"`
+	AuctionStatusCacheSize int           `key:"AUCTION_STATUS_CACHE_SIZE" default:"10000" min:"1"`
+	AuctionStatusCacheTTL  time.Duration `key:"AUCTION_STATUS_CACHE_TTL" default:"1m" min:"1ms"`
+
+	BidWALDir             string        `key:"BID_WAL_DIR" default:"data/wal"`
+	BidWALSegmentSize     int64         `key:"BID_WAL_SEGMENT_SIZE" default:"16777216" min:"4096"`
+	BidWorkers            int           `key:"BID_WORKERS" default:"4" min:"1" max:"256"`
+	BidQueueSize          int           `key:"BID_QUEUE_SIZE" default:"1000" min:"1"`
+	MaxBatchSize          int           `key:"MAX_BATCH_SIZE" default:"4" min:"1" max:"10000" reload:"true"`
+	BatchInsertInterval   time.Duration `key:"BATCH_INSERT_INTERVAL" default:"20s" min:"1ms" reload:"true"`
+	BidInsertMaxRetries   int           `key:"BID_INSERT_MAX_RETRIES" default:"3" min:"0" max:"10" reload:"true"`
+	BidInsertRetryBackoff time.Duration `key:"BID_INSERT_RETRY_BACKOFF" default:"100ms" min:"1ms" reload:"true"`
+	BidMaxAttempts        int           `key:"BID_MAX_ATTEMPTS" default:"5" min:"1" max:"100" reload:"true"`
+
+	LeaderLeaseTTL  time.Duration `key:"LEADER_LEASE_TTL" default:"15s" min:"1s"`
+	JobWorkers      int           `key:"JOB_WORKERS" default:"2" min:"1" max:"64"`
+	JobLeaseTTL     time.Duration `key:"JOB_LEASE_TTL" default:"5m" min:"1s"`
+	JobPollInterval time.Duration `key:"JOB_POLL_INTERVAL" default:"1s" min:"10ms" reload:"true"`
+	JobRetryBackoff time.Duration `key:"JOB_RETRY_BACKOFF" default:"5s" min:"1ms" reload:"true"`
+}
+
+var current atomic.Pointer[Config]
+
+// Current returns the configuration installed with Set. Until one is
+// installed, as in tests, it is built from the defaults and the environment.
+func Current() *Config {
+	if cfg := current.Load(); cfg != nil {
+		return cfg
+	}
+
+	cfg, _ := FromEnv()
+	return cfg
+}
+
+func Set(cfg *Config) {
+	current.Store(cfg)
+}
+
+// FromEnv builds a configuration from the defaults and the environment only.
+// Settings that fail to parse keep their default.
+func FromEnv() (*Config, error) {
+	cfg := defaults()
+	return cfg, cfg.apply(environment())
+}
+
+// Load builds the configuration from every source. args are the command line
+// arguments, without the program name; besides a flag per setting, such as
+// -max-batch-size, they accept -config, the YAML or TOML file to read
+// (CONFIG_FILE by default), and -env-file, a .env file read when present.
+func Load(args []string) (*Config, error) {
+	cfg, _, err := Parse("auction", args)
+	return cfg, err
+}
+
+// Parse builds the configuration like Load from the flags at the start of
+// args, returning the arguments left after them. name is the program name
+// shown in the usage message.
+func Parse(name string, args []string) (*Config, []string, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML configuration file")
+	envFile := flags.String("env-file", "cmd/auction/.env", "optional .env file")
+
+	keys := map[string]string{}
+	for _, setting := range settings() {
+		flags.String(setting.flagName(), "", setting.key)
+		keys[setting.flagName()] = setting.key
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	cfg := defaults()
+	var errs []error
+
+	dotEnv, err := godotenv.Read(*envFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, fmt.Errorf("reading %s: %w", *envFile, err))
+	}
+	errs = append(errs, cfg.apply(dotEnv))
+
+	if *configFile != "" {
+		fileValues, err := readFile(*configFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		errs = append(errs, cfg.apply(fileValues))
+	}
+
+	errs = append(errs, cfg.apply(environment()))
+
+	flagValues := map[string]string{}
+	flags.Visit(func(f *flag.Flag) {
+		if key, ok := keys[f.Name]; ok {
+			flagValues[key] = f.Value.String()
+		}
+	})
+	errs = append(errs, cfg.apply(flagValues))
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, flags.Args(), nil
+}
+
+// Validate checks every setting against its range and the settings that
+// depend on each other.
+func (c *Config) Validate() error {
+	var errs []error
+	value := reflect.ValueOf(c).Elem()
+	for _, setting := range settings() {
+		if err := setting.validate(value.Field(setting.index)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch c.StorageBackend {
+	case MongoBackend:
+		if c.MongoDBURL == "" || c.MongoDBDB == "" {
+			errs = append(errs, errors.New("MONGODB_URL and MONGODB_DB are required when STORAGE_BACKEND=mongodb"))
+		}
+	case PostgresBackend:
+		if c.SQLDSN == "" {
+			errs = append(errs, errors.New("SQL_DSN is required when STORAGE_BACKEND=postgres"))
+		}
+	}
+
+	if c.BlobStore == "gridfs" && c.StorageBackend != MongoBackend {
+		errs = append(errs, errors.New("BLOB_STORE=gridfs requires STORAGE_BACKEND=mongodb"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Dump lists every setting as KEY=value, in declaration order, with secrets
+// redacted.
+func (c *Config) Dump() []string {
+	value := reflect.ValueOf(c).Elem()
+
+	lines := make([]string, 0, value.NumField())
+	for _, setting := range settings() {
+		formatted := format(value.Field(setting.index))
+		if setting.secret && formatted != "" {
+			formatted = "[redacted]"
+		}
+		lines = append(lines, setting.key+"="+formatted)
+	}
+
+	return lines
+}
+
+// Reload installs next in place of the current configuration, keeping the
+// settings that need a restart. It returns the keys of the tunables that were
+// applied and of the changed settings that were not.
+func Reload(next *Config) (applied, ignored []string) {
+	previous := Current()
+	merged := *previous
+
+	mergedValue := reflect.ValueOf(&merged).Elem()
+	previousValue := reflect.ValueOf(previous).Elem()
+	nextValue := reflect.ValueOf(next).Elem()
+	for _, setting := range settings() {
+		field := nextValue.Field(setting.index)
+		if field.Interface() == previousValue.Field(setting.index).Interface() {
+			continue
+		}
+
+		if !setting.reload {
+			ignored = append(ignored, setting.key)
+			continue
+		}
+
+		mergedValue.Field(setting.index).Set(field)
+		applied = append(applied, setting.key)
+	}
+
+	Set(&merged)
+	return applied, ignored
+}
+
+func defaults() *Config {
+	cfg := &Config{}
+	value := reflect.ValueOf(cfg).Elem()
+	for _, setting := range settings() {
+		if setting.defaultValue != "" {
+			// Defaults are checked by the package tests.
+			setting.parse(value.Field(setting.index), setting.defaultValue)
+		}
+	}
+
+	return cfg
+}
+
+// apply sets the settings found in values, keyed by setting key.
+func (c *Config) apply(values map[string]string) error {
+	var errs []error
+	value := reflect.ValueOf(c).Elem()
+	for _, setting := range settings() {
+		raw, ok := values[setting.key]
+		if !ok {
+			continue
+		}
+
+		if err := setting.parse(value.Field(setting.index), raw); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func environment() map[string]string {
+	values := map[string]string{}
+	for _, setting := range settings() {
+		if raw, ok := os.LookupEnv(setting.key); ok {
+			values[setting.key] = raw
+		}
+	}
+
+	return values
+}
+
+// readFile reads a flat YAML or TOML file, chosen by its extension. Keys match
+// the setting keys in any case, so max_batch_size sets MAX_BATCH_SIZE.
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	document := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(content, &document)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &document)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	for key, raw := range document {
+		values[strings.ToUpper(key)] = fmt.Sprint(raw)
+	}
+
+	return values, nil
+}
+
+type setting struct {
+	index        int
+	key          string
+	defaultValue string
+	min, max     string
+	oneOf        []string
+	secret       bool
+	reload       bool
+}
+
+func settings() []setting {
+	configType := reflect.TypeOf(Config{})
+
+	list := make([]setting, 0, configType.NumField())
+	for i := 0; i < configType.NumField(); i++ {
+		tag := configType.Field(i).Tag
+		list = append(list, setting{
+			index:        i,
+			key:          tag.Get("key"),
+			defaultValue: tag.Get("default"),
+			min:          tag.Get("min"),
+			max:          tag.Get("max"),
+			oneOf:        strings.Fields(tag.Get("oneof")),
+			secret:       tag.Get("secret") == "true",
+			reload:       tag.Get("reload") == "true",
+		})
+	}
+
+	return list
+}
+
+func (s setting) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(s.key), "_", "-")
+}
+
+func (s setting) parse(field reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	switch {
+	case field.Type() == reflect.TypeOf(time.Duration(0)):
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a duration", s.key, raw)
+		}
+		field.SetInt(int64(duration))
+	case field.Kind() == reflect.Int || field.Kind() == reflect.Int64:
+		number, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not an integer", s.key, raw)
+		}
+		field.SetInt(number)
+	case field.Kind() == reflect.Bool:
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a boolean", s.key, raw)
+		}
+		field.SetBool(enabled)
+	default:
+		field.SetString(raw)
+	}
+
+	return nil
+}
+
+func (s setting) validate(field reflect.Value) error {
+	if field.Kind() == reflect.String {
+		if len(s.oneOf) > 0 && !contains(s.oneOf, field.String()) {
+			return fmt.Errorf("%s must be one of %s, got %q",
+				s.key, strings.Join(s.oneOf, ", "), field.String())
+		}
+		return nil
+	}
+
+	bound := &Config{}
+	boundField := reflect.ValueOf(bound).Elem().Field(s.index)
+	if s.min != "" {
+		s.parse(boundField, s.min)
+		if field.Int() < boundField.Int() {
+			return fmt.Errorf("%s must be at least %s, got %s", s.key, s.min, format(field))
+		}
+	}
+	if s.max != "" {
+		s.parse(boundField, s.max)
+		if field.Int() > boundField.Int() {
+			return fmt.Errorf("%s must be at most %s, got %s", s.key, s.max, format(field))
+		}
+	}
+
+	return nil
+}
+
+func format(field reflect.Value) string {
+	return fmt.Sprint(field.Interface())
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
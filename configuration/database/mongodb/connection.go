@@ -2,20 +2,15 @@ package mongodb
 
 import (
 	"context"
+	"fullcycle-auction_go/configuration/config"
 	"fullcycle-auction_go/configuration/logger"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"os"
-)
-
-const (
-	MONGODB_URL = "MONGODB_URL"
-	MONGODB_DB  = "MONGODB_DB"
 )
 
 func NewMongoDBConnection(ctx context.Context) (*mongo.Database, error) {
-	mongoURL := os.Getenv(MONGODB_URL)
-	mongoDatabase := os.Getenv(MONGODB_DB)
+	mongoURL := config.Current().MongoDBURL
+	mongoDatabase := config.Current().MongoDBDB
 
 	client, err := mongo.Connect(
 		ctx, options.Client().ApplyURI(mongoURL))
@@ -29,6 +29,12 @@ func ConvertError(internalError *internal_error.InternalError) *RestErr {
 		return NewNotFoundError(internalError.Error())
 	case "conflict":
 		return NewConflictError(internalError.Error())
+	case "forbidden":
+		return NewForbiddenError(internalError.Error())
+	case "too_many_requests":
+		return NewTooManyRequestsError(internalError.Error())
+	case "service_unavailable":
+		return NewServiceUnavailableError(internalError.Error())
 	default:
 		return NewInternalServerError(internalError.Error())
 	}
@@ -69,3 +75,30 @@ func NewConflictError(message string) *RestErr {
 		Causes:  nil,
 	}
 }
+
+func NewForbiddenError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "forbidden",
+		Code:    http.StatusForbidden,
+		Causes:  nil,
+	}
+}
+
+func NewTooManyRequestsError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "too_many_requests",
+		Code:    http.StatusTooManyRequests,
+		Causes:  nil,
+	}
+}
+
+func NewServiceUnavailableError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "service_unavailable",
+		Code:    http.StatusServiceUnavailable,
+		Causes:  nil,
+	}
+}
@@ -0,0 +1,158 @@
+// Package clock abstracts time so that timing behaviour can be tested without
+// sleeping.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer mirrors time.Timer behind an interface.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Real is the wall clock.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{timer: time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t *realTimer) C() <-chan time.Time        { return t.timer.C }
+func (t *realTimer) Stop() bool                 { return t.timer.Stop() }
+func (t *realTimer) Reset(d time.Duration) bool { return t.timer.Reset(d) }
+
+// Fake is a clock that only moves when Advance or Set is called, firing the
+// timers that become due.
+type Fake struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.now
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	timer := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	f.schedule(timer, d)
+	return timer
+}
+
+// Advance moves the clock forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the clock to now and fires every timer due by then.
+func (f *Fake) Set(now time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.now = now
+	pending := f.timers[:0]
+	for _, timer := range f.timers {
+		if timer.deadline.After(now) {
+			pending = append(pending, timer)
+			continue
+		}
+
+		timer.active = false
+		select {
+		case timer.c <- now:
+		default:
+		}
+	}
+	f.timers = pending
+}
+
+// Timers returns how many timers are waiting to fire, letting tests wait
+// for a goroutine to block on the clock before advancing it.
+func (f *Fake) Timers() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return len(f.timers)
+}
+
+func (f *Fake) schedule(timer *fakeTimer, d time.Duration) {
+	timer.deadline = f.now.Add(d)
+	if d <= 0 {
+		timer.active = false
+		select {
+		case timer.c <- f.now:
+		default:
+		}
+		return
+	}
+
+	timer.active = true
+	f.timers = append(f.timers, timer)
+}
+
+func (f *Fake) unschedule(timer *fakeTimer) bool {
+	if !timer.active {
+		return false
+	}
+
+	timer.active = false
+	for i, scheduled := range f.timers {
+		if scheduled == timer {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+	active   bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	return t.clock.unschedule(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	active := t.clock.unschedule(t)
+	t.clock.schedule(t, d)
+	return active
+}
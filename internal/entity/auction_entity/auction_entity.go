@@ -2,23 +2,26 @@ package auction_entity
 
 import (
 	"context"
+	"fullcycle-auction_go/internal/entity/lease_entity"
 	"fullcycle-auction_go/internal/internal_error"
 	"github.com/google/uuid"
 	"time"
 )
 
 func CreateAuction(
-	productName, category, description string,
-	condition ProductCondition) (*Auction, *internal_error.InternalError) {
+	productName, categoryId, description string,
+	condition ProductCondition,
+	now time.Time) (*Auction, *internal_error.InternalError) {
 	auction := &Auction{
 		Id:          uuid.New().String(),
 		ProductName: productName,
-		Category:    category,
+		CategoryId:  categoryId,
 		Description: description,
 		Condition:   condition,
 		Status:      Active,
-		Timestamp:   time.Now(),
+		Timestamp:   now,
 	}
+	auction.StartsAt = auction.Timestamp
 
 	if err := auction.Validate(); err != nil {
 		return nil, err
@@ -29,7 +32,7 @@ func CreateAuction(
 
 func (au *Auction) Validate() *internal_error.InternalError {
 	if len(au.ProductName) <= 1 ||
-		len(au.Category) <= 2 ||
+		uuid.Validate(au.CategoryId) != nil ||
 		len(au.Description) <= 10 && (au.Condition != New &&
 			au.Condition != Refurbished &&
 			au.Condition != Used) {
@@ -42,11 +45,27 @@ func (au *Auction) Validate() *internal_error.InternalError {
 type Auction struct {
 	Id          string
 	ProductName string
+	CategoryId  string
 	Category    string
 	Description string
 	Condition   ProductCondition
 	Status      AuctionStatus
 	Timestamp   time.Time
+	StartsAt    time.Time
+	EndTime     time.Time
+	HighestBid  float64
+	BidCount    int64
+	Images      []AuctionImage
+	Attributes  map[string]interface{}
+}
+
+type AuctionImage struct {
+	Id           string
+	Key          string
+	ThumbnailKey string
+	ContentType  string
+	Size         int64
+	Timestamp    time.Time
 }
 
 type ProductCondition int
@@ -55,8 +74,60 @@ type AuctionStatus int
 const (
 	Active AuctionStatus = iota
 	Completed
+	Cancelled
+	// Scheduled auctions are announced but only accept bids from StartsAt.
+	Scheduled
+)
+
+var (
+	statusNames = map[AuctionStatus]string{
+		Active:    "active",
+		Completed: "completed",
+		Cancelled: "cancelled",
+		Scheduled: "scheduled",
+	}
+	conditionNames = map[ProductCondition]string{
+		New:         "new",
+		Used:        "used",
+		Refurbished: "refurbished",
+	}
 )
 
+func (s AuctionStatus) String() string {
+	return statusNames[s]
+}
+
+func (c ProductCondition) String() string {
+	return conditionNames[c]
+}
+
+// AuctionStatusChanged is published in-process whenever the status of an
+// auction changes after it was created, so cached copies of it can be dropped.
+type AuctionStatusChanged struct {
+	AuctionId string
+	Status    AuctionStatus
+}
+
+// AuctionState is what bid placement needs to know about an auction.
+type AuctionState struct {
+	Status   AuctionStatus
+	StartsAt time.Time
+	EndTime  time.Time
+}
+
+// AuctionEndTime is when an active auction stops accepting bids.
+type AuctionEndTime struct {
+	AuctionId string
+	EndTime   time.Time
+}
+
+// AuctionClosed is published when an auction is closed at its end time.
+type AuctionClosed struct {
+	AuctionId string
+	EndTime   time.Time
+	ClosedAt  time.Time
+}
+
 const (
 	New ProductCondition = iota + 1
 	Used
@@ -68,11 +139,87 @@ type AuctionRepositoryInterface interface {
 		ctx context.Context,
 		auctionEntity *Auction) *internal_error.InternalError
 
+	// CreateAuctions stores a batch of new auctions, either all of them or,
+	// on error, none.
+	CreateAuctions(
+		ctx context.Context,
+		auctionEntities []*Auction) *internal_error.InternalError
+
 	FindAuctions(
 		ctx context.Context,
-		status AuctionStatus,
-		category, productName string) ([]Auction, *internal_error.InternalError)
+		filter AuctionFilter) (*AuctionPage, *internal_error.InternalError)
 
 	FindAuctionById(
 		ctx context.Context, id string) (*Auction, *internal_error.InternalError)
+
+	FindAuctionState(
+		ctx context.Context, id string) (*AuctionState, *internal_error.InternalError)
+
+	SuggestProductNames(
+		ctx context.Context,
+		prefix string,
+		limit int64) ([]string, *internal_error.InternalError)
+
+	AddAuctionImage(
+		ctx context.Context,
+		auctionId string,
+		image AuctionImage,
+		maxImages int) *internal_error.InternalError
+
+	RemoveAuctionImage(
+		ctx context.Context,
+		auctionId, imageId string) *internal_error.InternalError
+
+	// CancelAuction cancels an active or scheduled auction.
+	CancelAuction(
+		ctx context.Context, id string) (*Auction, *internal_error.InternalError)
+
+	// StartAuction opens a scheduled auction for bids and reports whether it
+	// did.
+	StartAuction(ctx context.Context, id string) (bool, *internal_error.InternalError)
+
+	// ExtendAuction moves the end time of an active auction to the later
+	// endTime.
+	ExtendAuction(
+		ctx context.Context,
+		id string,
+		endTime time.Time) (*Auction, *internal_error.InternalError)
+
+	// CloseAuction completes an active auction whose end time has passed and
+	// reports whether it did. With a fence, it also does nothing unless the
+	// fence's lease still has its token.
+	CloseAuction(
+		ctx context.Context, id string, fence lease_entity.Fence) (bool, *internal_error.InternalError)
+
+	// ForceCloseAuction completes an active auction right away, moving its
+	// end time to now.
+	ForceCloseAuction(
+		ctx context.Context, id string) (*Auction, *internal_error.InternalError)
+
+	// UpdateBidStats overwrites the highest bid and bid count of an auction.
+	UpdateBidStats(
+		ctx context.Context,
+		id string,
+		highestBid float64,
+		bidCount int64) *internal_error.InternalError
+
+	// RenameCategory updates the category name copied into the auctions of
+	// the category.
+	RenameCategory(
+		ctx context.Context, categoryId, name string) *internal_error.InternalError
+
+	FindAuctionEndTimes(ctx context.Context) ([]AuctionEndTime, *internal_error.InternalError)
+
+	// FindOverdueScheduledAuctions lists the ids of the scheduled auctions
+	// whose start time is not after cutoff.
+	FindOverdueScheduledAuctions(
+		ctx context.Context, cutoff time.Time) ([]string, *internal_error.InternalError)
+
+	// ExportAuctions calls fn for every auction matching filter, oldest first,
+	// reading them from the store as it goes and stopping at the first error.
+	// Images are not loaded, and fn must not call back into the repositories.
+	ExportAuctions(
+		ctx context.Context,
+		filter AuctionExportFilter,
+		fn func(auction *Auction) *internal_error.InternalError) *internal_error.InternalError
 }
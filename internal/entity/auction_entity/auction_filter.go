@@ -0,0 +1,126 @@
+package auction_entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type AuctionSort string
+
+const (
+	SortNewest          AuctionSort = "newest"
+	SortEndingSoonest   AuctionSort = "ending_soonest"
+	SortStartingSoonest AuctionSort = "starting_soonest"
+	SortHighestBid      AuctionSort = "highest_bid"
+	SortMostBids        AuctionSort = "most_bids"
+	SortRelevance       AuctionSort = "relevance"
+)
+
+type AttributeOperator string
+
+const (
+	AttributeEq  AttributeOperator = "eq"
+	AttributeGt  AttributeOperator = "gt"
+	AttributeGte AttributeOperator = "gte"
+	AttributeLt  AttributeOperator = "lt"
+	AttributeLte AttributeOperator = "lte"
+)
+
+type AuctionFilter struct {
+	Status      *AuctionStatus
+	Query       string
+	CategoryIds []string
+	ProductName string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Attributes  []AttributeFilter
+	Sort        AuctionSort
+	Limit       int64
+	After       string
+}
+
+// AuctionExportFilter selects the auctions an export goes through, and whose
+// bids it goes through when exporting bids.
+type AuctionExportFilter struct {
+	Status      *AuctionStatus
+	CategoryIds []string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	EndedFrom   *time.Time
+	EndedTo     *time.Time
+}
+
+// AttributeFilter matches auctions whose attribute Key compares to Value
+// with Operator. Equality filters match any of Values.
+type AttributeFilter struct {
+	Key      string
+	Operator AttributeOperator
+	Values   []interface{}
+}
+
+type AuctionPage struct {
+	Auctions   []Auction
+	NextCursor string
+	Total      int64
+}
+
+type AuctionCursor struct {
+	Value float64 `json:"v"`
+	Id    string  `json:"id"`
+}
+
+func (s AuctionSort) IsValid() bool {
+	switch s {
+	case SortNewest, SortEndingSoonest, SortStartingSoonest, SortHighestBid, SortMostBids, SortRelevance:
+		return true
+	}
+
+	return false
+}
+
+func (s AuctionSort) Descending() bool {
+	return s != SortEndingSoonest && s != SortStartingSoonest
+}
+
+func (au *Auction) SortValue(sort AuctionSort) float64 {
+	switch sort {
+	case SortHighestBid:
+		return au.HighestBid
+	case SortMostBids:
+		return float64(au.BidCount)
+	case SortEndingSoonest:
+		return float64(au.EndTime.UnixMilli())
+	case SortStartingSoonest:
+		return float64(au.StartsAt.UnixMilli())
+	default:
+		return float64(au.Timestamp.Unix())
+	}
+}
+
+func NewAuctionCursor(auction *Auction, sort AuctionSort) AuctionCursor {
+	return AuctionCursor{
+		Value: auction.SortValue(sort),
+		Id:    auction.Id,
+	}
+}
+
+func (c AuctionCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeAuctionCursor(cursor string) (*AuctionCursor, *internal_error.InternalError) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, internal_error.NewBadRequestError("Invalid pagination cursor")
+	}
+
+	var auctionCursor AuctionCursor
+	if err := json.Unmarshal(data, &auctionCursor); err != nil || auctionCursor.Id == "" {
+		return nil, internal_error.NewBadRequestError("Invalid pagination cursor")
+	}
+
+	return &auctionCursor, nil
+}
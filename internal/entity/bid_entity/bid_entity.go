@@ -2,6 +2,7 @@ package bid_entity
 
 import (
 	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/internal_error"
 	"github.com/google/uuid"
 	"time"
@@ -15,13 +16,14 @@ type Bid struct {
 	Timestamp time.Time
 }
 
-func CreateBid(userId, auctionId string, amount float64) (*Bid, *internal_error.InternalError) {
+func CreateBid(
+	userId, auctionId string, amount float64, now time.Time) (*Bid, *internal_error.InternalError) {
 	bid := &Bid{
 		Id:        uuid.New().String(),
 		UserId:    userId,
 		AuctionId: auctionId,
 		Amount:    amount,
-		Timestamp: time.Now(),
+		Timestamp: now,
 	}
 
 	if err := bid.Validate(); err != nil {
@@ -43,14 +45,70 @@ func (b *Bid) Validate() *internal_error.InternalError {
 	return nil
 }
 
+type BidResultStatus int
+
+const (
+	BidStored BidResultStatus = iota
+	// BidDuplicate means a bid with the same id was already stored.
+	BidDuplicate
+	// BidRejected means the auction is missing or no longer accepts bids.
+	BidRejected
+	// BidFailed means the bid could not be stored and may be retried.
+	BidFailed
+)
+
+type BidResult struct {
+	BidId  string
+	Status BidResultStatus
+	Err    *internal_error.InternalError
+}
+
 type BidEntityRepository interface {
+	// CreateBid stores a batch of bids and returns one result per bid, in
+	// the order given. The error is only set when the batch could not be
+	// processed at all.
 	CreateBid(
 		ctx context.Context,
-		bidEntities []Bid) *internal_error.InternalError
+		bidEntities []Bid) ([]BidResult, *internal_error.InternalError)
 
 	FindBidByAuctionId(
 		ctx context.Context, auctionId string) ([]Bid, *internal_error.InternalError)
 
 	FindWinningBidByAuctionId(
 		ctx context.Context, auctionId string) (*Bid, *internal_error.InternalError)
+
+	FindBidsByUserId(
+		ctx context.Context,
+		userId string,
+		auctionStatus *auction_entity.AuctionStatus,
+		page, limit int64) ([]Bid, int64, *internal_error.InternalError)
+
+	FindWinningBidsByUserId(
+		ctx context.Context,
+		userId string,
+		page, limit int64) ([]Bid, int64, *internal_error.InternalError)
+
+	// ExportBids calls fn for every bid on the auctions matching filter,
+	// grouped by auction in the order of ExportAuctions and oldest first
+	// within each, with the same constraints on fn.
+	ExportBids(
+		ctx context.Context,
+		filter auction_entity.AuctionExportFilter,
+		fn func(bid *Bid) *internal_error.InternalError) *internal_error.InternalError
+}
+
+// BidJournal durably records bids before they are batched into the
+// repository, so bids acknowledged to clients survive a restart.
+type BidJournal interface {
+	Append(bid Bid) (uint64, *internal_error.InternalError)
+
+	// Commit marks every bid up to lsn as stored in the repository.
+	Commit(lsn uint64) *internal_error.InternalError
+
+	// Replay calls fn, in order, for every bid appended but not committed.
+	Replay(fn func(lsn uint64, bid Bid) *internal_error.InternalError) *internal_error.InternalError
+
+	// DeadLetter durably sets aside a bid that could not be stored, so the
+	// journal can be committed past it.
+	DeadLetter(bid Bid, reason string) *internal_error.InternalError
 }
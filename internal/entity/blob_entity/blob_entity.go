@@ -0,0 +1,18 @@
+package blob_entity
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+)
+
+type BlobStore interface {
+	Put(
+		ctx context.Context, key string, data io.Reader) *internal_error.InternalError
+
+	Open(
+		ctx context.Context, key string) (io.ReadCloser, *internal_error.InternalError)
+
+	Delete(
+		ctx context.Context, key string) *internal_error.InternalError
+}
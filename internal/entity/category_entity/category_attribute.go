@@ -0,0 +1,140 @@
+package category_entity
+
+import (
+	"fmt"
+	"fullcycle-auction_go/internal/internal_error"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type AttributeType string
+
+const (
+	AttributeString  AttributeType = "string"
+	AttributeNumber  AttributeType = "number"
+	AttributeBoolean AttributeType = "boolean"
+)
+
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+type AttributeDefinition struct {
+	Key      string
+	Type     AttributeType
+	Required bool
+	Options  []string
+}
+
+func IsValidAttributeKey(key string) bool {
+	return attributeKeyPattern.MatchString(key)
+}
+
+func (c *Category) validateAttributeDefinitions() *internal_error.InternalError {
+	keys := map[string]bool{}
+	for _, definition := range c.Attributes {
+		if !IsValidAttributeKey(definition.Key) {
+			return internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute key %q must be lowercase letters, digits or underscores", definition.Key))
+		} else if keys[definition.Key] {
+			return internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute key %q is declared more than once", definition.Key))
+		} else if definition.Type != AttributeString &&
+			definition.Type != AttributeNumber &&
+			definition.Type != AttributeBoolean {
+			return internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute %q has an invalid type", definition.Key))
+		} else if len(definition.Options) > 0 && definition.Type != AttributeString {
+			return internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute %q can only declare options when its type is string", definition.Key))
+		}
+
+		keys[definition.Key] = true
+	}
+
+	return nil
+}
+
+func (c *Category) FindAttribute(key string) *AttributeDefinition {
+	for i := range c.Attributes {
+		if c.Attributes[i].Key == key {
+			return &c.Attributes[i]
+		}
+	}
+
+	return nil
+}
+
+func (c *Category) ValidateAttributes(
+	values map[string]interface{}) (map[string]interface{}, *internal_error.InternalError) {
+	attributes := map[string]interface{}{}
+
+	for key, value := range values {
+		definition := c.FindAttribute(key)
+		if definition == nil {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute %q is not defined for category %s", key, c.Name))
+		}
+
+		normalized, err := definition.Normalize(value)
+		if err != nil {
+			return nil, err
+		}
+
+		attributes[key] = normalized
+	}
+
+	for _, definition := range c.Attributes {
+		if _, ok := attributes[definition.Key]; definition.Required && !ok {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute %q is required for category %s", definition.Key, c.Name))
+		}
+	}
+
+	return attributes, nil
+}
+
+func (d *AttributeDefinition) Normalize(value interface{}) (interface{}, *internal_error.InternalError) {
+	switch d.Type {
+	case AttributeNumber:
+		switch typed := value.(type) {
+		case float64:
+			return typed, nil
+		case int:
+			return float64(typed), nil
+		case int64:
+			return float64(typed), nil
+		case string:
+			if number, err := strconv.ParseFloat(strings.TrimSpace(typed), 64); err == nil {
+				return number, nil
+			}
+		}
+	case AttributeBoolean:
+		switch typed := value.(type) {
+		case bool:
+			return typed, nil
+		case string:
+			if boolean, err := strconv.ParseBool(strings.TrimSpace(typed)); err == nil {
+				return boolean, nil
+			}
+		}
+	case AttributeString:
+		if text, ok := value.(string); ok && strings.TrimSpace(text) != "" {
+			text = strings.TrimSpace(text)
+			if len(d.Options) == 0 {
+				return text, nil
+			}
+
+			for _, option := range d.Options {
+				if option == text {
+					return text, nil
+				}
+			}
+
+			return nil, internal_error.NewBadRequestError(fmt.Sprintf(
+				"Attribute %q must be one of: %s", d.Key, strings.Join(d.Options, ", ")))
+		}
+	}
+
+	return nil, internal_error.NewBadRequestError(
+		fmt.Sprintf("Attribute %q must be a %s", d.Key, d.Type))
+}
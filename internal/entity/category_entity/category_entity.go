@@ -0,0 +1,122 @@
+package category_entity
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+type Category struct {
+	Id         string
+	Name       string
+	Slug       string
+	ParentId   string
+	Attributes []AttributeDefinition
+	Timestamp  time.Time
+}
+
+func CreateCategory(
+	name, parentId string,
+	attributes []AttributeDefinition,
+	now time.Time) (*Category, *internal_error.InternalError) {
+	category := &Category{
+		Id:         uuid.New().String(),
+		Name:       strings.TrimSpace(name),
+		Slug:       Slugify(name),
+		ParentId:   parentId,
+		Attributes: attributes,
+		Timestamp:  now,
+	}
+
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (c *Category) Validate() *internal_error.InternalError {
+	if len(c.Name) <= 1 || len(c.Slug) <= 1 {
+		return internal_error.NewBadRequestError("Category name is not a valid value")
+	} else if c.ParentId != "" && uuid.Validate(c.ParentId) != nil {
+		return internal_error.NewBadRequestError("Category parentId is not a valid id")
+	} else if c.ParentId == c.Id {
+		return internal_error.NewBadRequestError("Category cannot be its own parent")
+	}
+
+	return c.validateAttributeDefinitions()
+}
+
+func (c *Category) Rename(name string) {
+	c.Name = strings.TrimSpace(name)
+	c.Slug = Slugify(name)
+}
+
+func Slugify(name string) string {
+	unaccented, _, err := transform.String(
+		transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), name)
+	if err != nil {
+		unaccented = name
+	}
+
+	var slug strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(unaccented) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			slug.WriteRune(r)
+			lastDash = false
+		} else if !lastDash {
+			slug.WriteRune('-')
+			lastDash = true
+		}
+	}
+
+	return strings.TrimSuffix(slug.String(), "-")
+}
+
+func DescendantIds(categories []Category, rootId string) []string {
+	children := map[string][]string{}
+	for _, category := range categories {
+		children[category.ParentId] = append(children[category.ParentId], category.Id)
+	}
+
+	ids := []string{rootId}
+	visited := map[string]bool{rootId: true}
+	for i := 0; i < len(ids); i++ {
+		for _, childId := range children[ids[i]] {
+			if !visited[childId] {
+				visited[childId] = true
+				ids = append(ids, childId)
+			}
+		}
+	}
+
+	return ids
+}
+
+type CategoryRepositoryInterface interface {
+	CreateCategory(
+		ctx context.Context, category *Category) *internal_error.InternalError
+
+	UpdateCategory(
+		ctx context.Context, category *Category) *internal_error.InternalError
+
+	DeleteCategory(
+		ctx context.Context, id string) *internal_error.InternalError
+
+	FindCategoryById(
+		ctx context.Context, id string) (*Category, *internal_error.InternalError)
+
+	FindCategoryBySlug(
+		ctx context.Context, slug string) (*Category, *internal_error.InternalError)
+
+	FindCategories(
+		ctx context.Context) ([]Category, *internal_error.InternalError)
+}
@@ -0,0 +1,168 @@
+package job_entity
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/cron"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job is a unit of work run at RunAt by whichever worker claims it first.
+// Jobs with a Cron expression are rescheduled after each run instead of
+// finishing.
+type Job struct {
+	Id             string
+	Type           string
+	Payload        []byte
+	Status         JobStatus
+	RunAt          time.Time
+	Cron           string
+	IdempotencyKey string
+	Attempts       int
+	MaxAttempts    int
+	LastError      string
+
+	// ClaimedBy holds the claim until LeaseExpiresAt, after which another
+	// worker may take the job over. Token grows with every claim, so a
+	// worker whose claim was taken over cannot overwrite the new one.
+	ClaimedBy      string
+	Token          int64
+	LeaseExpiresAt time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type JobStatus int
+
+const (
+	Pending JobStatus = iota
+	Running
+	Succeeded
+	Failed
+	Cancelled
+)
+
+const defaultMaxAttempts = 5
+
+// JobSpec describes a job to create. RunAt defaults to now, or to the next
+// time matching Cron when it is set.
+type JobSpec struct {
+	Type           string
+	Payload        []byte
+	RunAt          time.Time
+	Cron           string
+	IdempotencyKey string
+	MaxAttempts    int
+}
+
+type JobFilter struct {
+	Status *JobStatus
+	Type   string
+	Page   int64
+	Limit  int64
+}
+
+func CreateJob(spec JobSpec, now time.Time) (*Job, *internal_error.InternalError) {
+	job := &Job{
+		Id:             uuid.New().String(),
+		Type:           strings.TrimSpace(spec.Type),
+		Payload:        spec.Payload,
+		Status:         Pending,
+		RunAt:          spec.RunAt,
+		Cron:           strings.TrimSpace(spec.Cron),
+		IdempotencyKey: strings.TrimSpace(spec.IdempotencyKey),
+		MaxAttempts:    spec.MaxAttempts,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = defaultMaxAttempts
+	}
+
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+		if job.Cron != "" {
+			job.RunAt = job.NextRun(now)
+		}
+	}
+
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (j *Job) Validate() *internal_error.InternalError {
+	if j.Type == "" {
+		return internal_error.NewBadRequestError("Job type is required")
+	} else if j.MaxAttempts < 1 {
+		return internal_error.NewBadRequestError("Job max attempts must be at least 1")
+	}
+
+	if j.Cron != "" {
+		if _, err := cron.Parse(j.Cron); err != nil {
+			return internal_error.NewBadRequestError(fmt.Sprintf("Job cron expression is invalid: %v", err))
+		}
+	}
+
+	if j.RunAt.IsZero() {
+		return internal_error.NewBadRequestError("Job cron expression never matches")
+	}
+
+	return nil
+}
+
+// NextRun returns the next time a recurring job runs after t, or the zero
+// time for one-off jobs.
+func (j *Job) NextRun(t time.Time) time.Time {
+	schedule, err := cron.Parse(j.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	return schedule.Next(t)
+}
+
+type JobRepositoryInterface interface {
+	// CreateJob stores job, unless a job with the same idempotency key
+	// exists, in which case that job is returned instead. It reports whether
+	// job was created.
+	CreateJob(
+		ctx context.Context, job *Job) (*Job, bool, *internal_error.InternalError)
+
+	// ClaimJob marks the pending job due the earliest, or a running one whose
+	// lease expired, as running for workerId until now plus leaseTTL,
+	// counting one more attempt. It returns nil when no job is due.
+	ClaimJob(
+		ctx context.Context,
+		workerId string,
+		now time.Time,
+		leaseTTL time.Duration) (*Job, *internal_error.InternalError)
+
+	// FinishJob stores the status, run time, attempts and last error of a
+	// claimed job and releases the claim. It reports false, without storing
+	// anything, when the claim was taken over by another worker.
+	FinishJob(
+		ctx context.Context, job *Job) (bool, *internal_error.InternalError)
+
+	FindJobById(
+		ctx context.Context, id string) (*Job, *internal_error.InternalError)
+
+	FindJobs(
+		ctx context.Context, filter JobFilter) ([]Job, int64, *internal_error.InternalError)
+
+	// RetryJob runs a failed or cancelled job again at runAt with its
+	// attempts reset.
+	RetryJob(
+		ctx context.Context, id string, runAt time.Time) (*Job, *internal_error.InternalError)
+
+	// CancelJob cancels a pending or failed job.
+	CancelJob(
+		ctx context.Context, id string) (*Job, *internal_error.InternalError)
+}
@@ -0,0 +1,46 @@
+package lease_entity
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+// Lease grants its holder exclusive right to run a job until ExpiresAt. Token
+// grows every time the lease changes hands, so work tagged with an older
+// token can be told apart from the current holder's.
+type Lease struct {
+	Name      string
+	HolderId  string
+	Token     int64
+	ExpiresAt time.Time
+}
+
+type LeaseRepositoryInterface interface {
+	// AcquireLease renews the lease when holderId holds it and it has not
+	// expired, or takes it over, with a new token, when it is free or
+	// expired. It returns the current lease and whether holderId holds it.
+	AcquireLease(
+		ctx context.Context,
+		name, holderId string,
+		ttl time.Duration) (*Lease, bool, *internal_error.InternalError)
+
+	// ReleaseLease expires the lease right away if it is still held with
+	// token, so another instance can take over without waiting.
+	ReleaseLease(
+		ctx context.Context, name, holderId string, token int64) *internal_error.InternalError
+
+	FindLease(ctx context.Context, name string) (*Lease, *internal_error.InternalError)
+}
+
+// Fence is the lease term a write is made under. A fenced write applies only
+// while the lease named Lease still has Token, so a deposed holder's writes
+// are refused once another instance took over. The zero Fence is unfenced.
+type Fence struct {
+	Lease string
+	Token int64
+}
+
+func (f Fence) IsZero() bool {
+	return f.Token == 0
+}
@@ -3,11 +3,83 @@ package user_entity
 import (
 	"context"
 	"fullcycle-auction_go/internal/internal_error"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
 )
 
 type User struct {
-	Id   string
-	Name string
+	Id     string
+	Name   string
+	Email  string
+	Status UserStatus
+}
+
+type UserStatus int
+
+const (
+	Active UserStatus = iota
+	Inactive
+)
+
+// UserProfileUpdate holds the profile fields to change; nil fields are kept.
+type UserProfileUpdate struct {
+	Name  *string
+	Email *string
+}
+
+type UserFilter struct {
+	Name   string
+	Email  string
+	Status *UserStatus
+	Page   int64
+	Limit  int64
+}
+
+func CreateUser(name, email string) (*User, *internal_error.InternalError) {
+	user := &User{
+		Id:     uuid.New().String(),
+		Name:   strings.TrimSpace(name),
+		Email:  NormalizeEmail(email),
+		Status: Active,
+	}
+
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (u *User) Validate() *internal_error.InternalError {
+	if err := uuid.Validate(u.Id); err != nil {
+		return internal_error.NewBadRequestError("User id is not a valid id")
+	} else if err := ValidateName(u.Name); err != nil {
+		return err
+	}
+
+	return ValidateEmail(u.Email)
+}
+
+func ValidateName(name string) *internal_error.InternalError {
+	if len(strings.TrimSpace(name)) <= 1 {
+		return internal_error.NewBadRequestError("User name is not a valid value")
+	}
+
+	return nil
+}
+
+func ValidateEmail(email string) *internal_error.InternalError {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return internal_error.NewBadRequestError("User email is not a valid value")
+	}
+
+	return nil
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
 
 type UserRepositoryInterface interface {
@@ -15,4 +87,12 @@ type UserRepositoryInterface interface {
 		ctx context.Context, userId string) (*User, *internal_error.InternalError)
 	CreateUser(
 		ctx context.Context, user *User) *internal_error.InternalError
+	// UpdateUserProfile changes only the given fields of an active user,
+	// answering not_found when no active user has the id.
+	UpdateUserProfile(
+		ctx context.Context, userId string, update UserProfileUpdate) *internal_error.InternalError
+	DeactivateUser(
+		ctx context.Context, userId string) *internal_error.InternalError
+	FindUsers(
+		ctx context.Context, filter UserFilter) ([]User, int64, *internal_error.InternalError)
 }
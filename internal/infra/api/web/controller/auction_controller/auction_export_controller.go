@@ -0,0 +1,207 @@
+package auction_controller
+
+import (
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/infra/export"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportAuctions exports the auctions, or with dataset=bids their bids, as a
+// CSV or NDJSON stream or as a Parquet file.
+func (u *AuctionController) ExportAuctions(c *gin.Context) {
+	var exportInputDTO auction_usecase.AuctionExportInputDTO
+
+	if err := c.ShouldBindQuery(&exportInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	format, errFormat := export.ParseFormat(c.DefaultQuery("format", string(export.CSV)))
+	if errFormat != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "format",
+			Message: "format must be csv, ndjson or parquet",
+		})
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	for _, period := range []struct {
+		field    string
+		from, to *time.Time
+	}{
+		{"created_from", exportInputDTO.CreatedFrom, exportInputDTO.CreatedTo},
+		{"ended_from", exportInputDTO.EndedFrom, exportInputDTO.EndedTo},
+	} {
+		if period.from != nil && period.to != nil && period.from.After(*period.to) {
+			errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+				Field:   period.field,
+				Message: fmt.Sprintf("%s must be before the end of the period", period.field),
+			})
+			c.JSON(errRest.Code, errRest)
+			return
+		}
+	}
+
+	// The request context stops reading from the database once the client
+	// goes away.
+	ctx := c.Request.Context()
+
+	switch dataset := c.DefaultQuery("dataset", "auctions"); dataset {
+	case "auctions":
+		sendExport(c, format, dataset,
+			func(fn func(row auction_usecase.AuctionExportDTO) error) *internal_error.InternalError {
+				return u.auctionUseCase.ExportAuctions(ctx, exportInputDTO, fn)
+			})
+	case "bids":
+		sendExport(c, format, dataset,
+			func(fn func(row auction_usecase.BidExportDTO) error) *internal_error.InternalError {
+				return u.auctionUseCase.ExportBids(ctx, exportInputDTO, fn)
+			})
+	default:
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "dataset",
+			Message: "dataset must be auctions or bids",
+		})
+		c.JSON(errRest.Code, errRest)
+	}
+}
+
+// sendExport writes the rows produced by run to the response. CSV and NDJSON
+// are streamed as they are read, while Parquet, unreadable without the footer
+// written last, is spooled to a temporary file and sent once complete.
+func sendExport[T any](
+	c *gin.Context,
+	format export.Format,
+	dataset string,
+	run func(fn func(row T) error) *internal_error.InternalError) {
+	if format != export.Parquet {
+		streamExport(c, format, dataset, run)
+		return
+	}
+
+	file, errFile := os.CreateTemp("", "export-*."+string(format))
+	if errFile != nil {
+		exportFailed(c, "Error creating the export file", errFile)
+		return
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer, errWriter := export.NewWriter[T](format, file)
+	if errWriter != nil {
+		exportFailed(c, "Error creating the export writer", errWriter)
+		return
+	}
+
+	if err := run(writer.Write); err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	if err := writer.Close(); err != nil {
+		exportFailed(c, "Error finishing the export", err)
+		return
+	}
+
+	info, errStat := file.Stat()
+	if errStat == nil {
+		_, errStat = file.Seek(0, io.SeekStart)
+	}
+	if errStat != nil {
+		exportFailed(c, "Error reading the export file", errStat)
+		return
+	}
+
+	c.DataFromReader(http.StatusOK, info.Size(), format.ContentType(), file, exportHeaders(format, dataset))
+}
+
+// streamExport writes the rows produced by run straight to the response. The
+// response starts when the writer first flushes its buffer, so an error
+// before then is still answered in JSON with its status. After it, the
+// connection is closed without ending the response, so the client sees the
+// export cut short instead of taking it as complete.
+func streamExport[T any](
+	c *gin.Context,
+	format export.Format,
+	dataset string,
+	run func(fn func(row T) error) *internal_error.InternalError) {
+	response := &exportResponse{c: c, format: format, dataset: dataset}
+
+	writer, errWriter := export.NewWriter[T](format, response)
+	if errWriter != nil {
+		exportFailed(c, "Error creating the export writer", errWriter)
+		return
+	}
+
+	err := run(writer.Write)
+	if err == nil {
+		if errClose := writer.Close(); errClose != nil {
+			logger.Error("Error finishing the export", errClose)
+			err = internal_error.NewInternalServerError("Error trying to export")
+		}
+	}
+	if err == nil {
+		// An empty NDJSON export writes no bytes, but still gets its headers.
+		response.Write(nil)
+		return
+	}
+
+	if !response.started {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	logger.Error(fmt.Sprintf("Export of %s failed after the response started", dataset), err)
+	if conn, _, errHijack := c.Writer.Hijack(); errHijack == nil {
+		conn.Close()
+	}
+}
+
+// exportResponse sends the export status and headers with the first bytes
+// written to it.
+type exportResponse struct {
+	c       *gin.Context
+	format  export.Format
+	dataset string
+	started bool
+}
+
+func (r *exportResponse) Write(p []byte) (int, error) {
+	if !r.started {
+		r.started = true
+		for key, value := range exportHeaders(r.format, r.dataset) {
+			r.c.Header(key, value)
+		}
+		r.c.Header("Content-Type", r.format.ContentType())
+		r.c.Status(http.StatusOK)
+	}
+
+	return r.c.Writer.Write(p)
+}
+
+func exportHeaders(format export.Format, dataset string) map[string]string {
+	return map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s.%s"`, dataset, format),
+	}
+}
+
+func exportFailed(c *gin.Context, message string, err error) {
+	logger.Error(message, err)
+	errRest := rest_err.NewInternalServerError("Error trying to export")
+	c.JSON(errRest.Code, errRest)
+}
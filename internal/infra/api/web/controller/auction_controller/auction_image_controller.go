@@ -0,0 +1,127 @@
+package auction_controller
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (u *AuctionController) AddAuctionImage(c *gin.Context) {
+	auctionId, ok := validateAuctionId(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, errForm := c.FormFile("image")
+	if errForm != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "image",
+			Message: "A multipart file named image is required",
+		})
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	file, errOpen := fileHeader.Open()
+	if errOpen != nil {
+		errRest := rest_err.NewBadRequestError("Error trying to read image")
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+	defer file.Close()
+
+	imageData, err := u.auctionUseCase.AddAuctionImage(context.Background(), auctionId, file)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, imageData)
+}
+
+func (u *AuctionController) FindAuctionImage(c *gin.Context) {
+	u.serveAuctionImage(c, false)
+}
+
+func (u *AuctionController) FindAuctionImageThumbnail(c *gin.Context) {
+	u.serveAuctionImage(c, true)
+}
+
+func (u *AuctionController) RemoveAuctionImage(c *gin.Context) {
+	auctionId, ok := validateAuctionId(c)
+	if !ok {
+		return
+	}
+
+	if err := u.auctionUseCase.RemoveAuctionImage(
+		context.Background(), auctionId, c.Param("imageId")); err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (u *AuctionController) CancelAuction(c *gin.Context) {
+	auctionId, ok := validateAuctionId(c)
+	if !ok {
+		return
+	}
+
+	if err := u.auctionUseCase.CancelAuction(context.Background(), auctionId); err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (u *AuctionController) serveAuctionImage(c *gin.Context, thumbnail bool) {
+	auctionId, ok := validateAuctionId(c)
+	if !ok {
+		return
+	}
+
+	reader, contentType, err := u.auctionUseCase.OpenAuctionImage(
+		context.Background(), auctionId, c.Param("imageId"), thumbnail)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+	defer reader.Close()
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+
+	// The status is already sent, so a failed copy can only be logged.
+	if _, err := io.Copy(c.Writer, reader); err != nil {
+		logger.Error(fmt.Sprintf("Error sending image %s of auction %s", c.Param("imageId"), auctionId), err)
+	}
+}
+
+func validateAuctionId(c *gin.Context) (string, bool) {
+	auctionId := c.Param("auctionId")
+
+	if err := uuid.Validate(auctionId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "auctionId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return auctionId, true
+}
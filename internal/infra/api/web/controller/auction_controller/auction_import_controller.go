@@ -0,0 +1,265 @@
+package auction_controller
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// importColumns are the CSV columns besides the attr.<key> ones.
+var (
+	requiredImportColumns = []string{"product_name", "category_id", "description"}
+	importColumns         = map[string]bool{
+		"product_name": true,
+		"category_id":  true,
+		"description":  true,
+		"condition":    true,
+		"starts_at":    true,
+	}
+)
+
+// ImportAuctions creates the auctions of a CSV or NDJSON body, answering with
+// the created auctions and the errors of each rejected row.
+func (u *AuctionController) ImportAuctions(c *gin.Context) {
+	dryRun, errDryRun := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if errDryRun != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "dry_run",
+			Message: "dry_run must be true or false",
+		})
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	cfg := config.Current()
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxImportSize)
+
+	var rows []auction_usecase.AuctionImportRowDTO
+	var errRead error
+	switch format := c.DefaultQuery("format", "csv"); format {
+	case "csv":
+		rows, errRead = readCSVImport(body, cfg.MaxImportRows)
+	case "ndjson":
+		rows, errRead = readNDJSONImport(body, cfg.MaxImportRows)
+	default:
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "format",
+			Message: "format must be csv or ndjson",
+		})
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	if errRead != nil {
+		var errSize *http.MaxBytesError
+		if errors.As(errRead, &errSize) {
+			errRead = fmt.Errorf("the file exceeds %d bytes", cfg.MaxImportSize)
+		}
+
+		errRest := rest_err.NewBadRequestError("Error trying to read the import file", rest_err.Causes{
+			Field:   "file",
+			Message: errRead.Error(),
+		})
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	output, err := u.auctionUseCase.ImportAuctions(context.Background(), auction_usecase.AuctionImportInputDTO{
+		Rows:   rows,
+		DryRun: dryRun,
+	})
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// readCSVImport reads a CSV file with a header row naming its columns. Rows
+// are numbered by the line they start on.
+func readCSVImport(in io.Reader, maxRows int) ([]auction_usecase.AuctionImportRowDTO, error) {
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = 0
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("the file is empty")
+		}
+		return nil, err
+	}
+
+	columns := make([]string, len(header))
+	present := map[string]bool{}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !importColumns[name] && !strings.HasPrefix(name, "attr.") {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[i] = name
+		present[name] = true
+	}
+	for _, name := range requiredImportColumns {
+		if !present[name] {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var rows []auction_usecase.AuctionImportRowDTO
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, err
+		}
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("the file has more than %d rows", maxRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			rows = append(rows, importRowError(line, "", fmt.Sprintf(
+				"expected %d fields, got %d", len(columns), len(record))))
+			continue
+		}
+
+		rows = append(rows, csvImportRow(line, columns, record))
+	}
+}
+
+func csvImportRow(line int, columns, record []string) auction_usecase.AuctionImportRowDTO {
+	row := auction_usecase.AuctionImportRowDTO{Line: line}
+	input := &row.Auction
+
+	for i, value := range record {
+		switch name := columns[i]; name {
+		case "product_name":
+			input.ProductName = value
+		case "category_id":
+			input.CategoryId = value
+		case "description":
+			input.Description = value
+		case "condition":
+			condition, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				row.Errors = append(row.Errors, importError(line, name, "condition must be a number"))
+				continue
+			}
+			input.Condition = auction_usecase.ProductCondition(condition)
+		case "starts_at":
+			if value == "" {
+				continue
+			}
+			startsAt, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+			if err != nil {
+				row.Errors = append(row.Errors, importError(line, name, "starts_at must be an RFC 3339 time"))
+				continue
+			}
+			input.StartsAt = &startsAt
+		default:
+			if value == "" {
+				continue
+			}
+			if input.Attributes == nil {
+				input.Attributes = map[string]interface{}{}
+			}
+			input.Attributes[strings.TrimPrefix(name, "attr.")] = value
+		}
+	}
+
+	if len(row.Errors) == 0 {
+		row.Errors = bindingErrors(line, input)
+	}
+	return row
+}
+
+// readNDJSONImport reads one POST /auction body per line, skipping blank
+// lines.
+func readNDJSONImport(in io.Reader, maxRows int) ([]auction_usecase.AuctionImportRowDTO, error) {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1<<20)
+
+	var rows []auction_usecase.AuctionImportRowDTO
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("the file has more than %d rows", maxRows)
+		}
+
+		var input auction_usecase.AuctionInputDTO
+		if err := json.Unmarshal(data, &input); err != nil {
+			rows = append(rows, importRowError(line, "", "invalid JSON: "+err.Error()))
+			continue
+		}
+
+		rows = append(rows, auction_usecase.AuctionImportRowDTO{
+			Line:    line,
+			Auction: input,
+			Errors:  bindingErrors(line, &input),
+		})
+	}
+
+	return rows, scanner.Err()
+}
+
+// bindingErrors applies the binding rules of POST /auction to input.
+func bindingErrors(line int, input *auction_usecase.AuctionInputDTO) []auction_usecase.AuctionImportErrorDTO {
+	err := binding.Validator.ValidateStruct(input)
+	if err == nil {
+		return nil
+	}
+
+	restErr := validation.ValidateErr(err)
+	if len(restErr.Causes) == 0 {
+		return []auction_usecase.AuctionImportErrorDTO{importError(line, "", restErr.Message)}
+	}
+
+	// Causes name the struct fields; the report names the columns.
+	inputType := reflect.TypeOf(*input)
+	errs := make([]auction_usecase.AuctionImportErrorDTO, 0, len(restErr.Causes))
+	for _, cause := range restErr.Causes {
+		field := cause.Field
+		if structField, ok := inputType.FieldByName(field); ok {
+			field, _, _ = strings.Cut(structField.Tag.Get("json"), ",")
+		}
+		errs = append(errs, importError(line, field, cause.Message))
+	}
+	return errs
+}
+
+func importRowError(line int, field, message string) auction_usecase.AuctionImportRowDTO {
+	return auction_usecase.AuctionImportRowDTO{
+		Line:   line,
+		Errors: []auction_usecase.AuctionImportErrorDTO{importError(line, field, message)},
+	}
+}
+
+func importError(line int, field, message string) auction_usecase.AuctionImportErrorDTO {
+	return auction_usecase.AuctionImportErrorDTO{Line: line, Field: field, Message: message}
+}
@@ -29,7 +29,7 @@ func (u *AuctionController) CreateAuction(c *gin.Context) {
 		return
 	}
 
-	err := u.auctionUseCase.CreateAuction(context.Background(), auctionInputDTO)
+	_, err := u.auctionUseCase.CreateAuction(context.Background(), auctionInputDTO)
 	if err != nil {
 		restErr := rest_err.ConvertError(err)
 
@@ -39,3 +39,28 @@ func (u *AuctionController) CreateAuction(c *gin.Context) {
 
 	c.Status(http.StatusCreated)
 }
+
+func (u *AuctionController) ExtendAuction(c *gin.Context) {
+	auctionId, ok := validateAuctionId(c)
+	if !ok {
+		return
+	}
+
+	var extendInputDTO auction_usecase.AuctionExtendInputDTO
+	if err := c.ShouldBindJSON(&extendInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	auction, err := u.auctionUseCase.ExtendAuction(context.Background(), auctionId, extendInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, auction)
+}
@@ -3,14 +3,18 @@ package auction_controller
 import (
 	"context"
 	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
 	"fullcycle-auction_go/internal/usecase/auction_usecase"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+const attributeParamPrefix = "attr."
+
 func (u *AuctionController) FindAuctionById(c *gin.Context) {
 	auctionId := c.Param("auctionId")
 
@@ -35,24 +39,33 @@ func (u *AuctionController) FindAuctionById(c *gin.Context) {
 }
 
 func (u *AuctionController) FindAuctions(c *gin.Context) {
-	status := c.Query("status")
-	category := c.Query("category")
-	productName := c.Query("productName")
-
-	statusNumber, errConv := strconv.Atoi(status)
-	if status != "" {
-		statusNumber, errConv = strconv.Atoi(status)
-		if errConv != nil {
-			errRest := rest_err.NewBadRequestError("Error trying to validate auction status param")
-			c.JSON(errRest.Code, errRest)
-			return
+	var searchInputDTO auction_usecase.AuctionSearchInputDTO
+
+	if err := c.ShouldBindQuery(&searchInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if searchInputDTO.CreatedFrom != nil && searchInputDTO.CreatedTo != nil &&
+		searchInputDTO.CreatedFrom.After(*searchInputDTO.CreatedTo) {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "created_from",
+			Message: "created_from must be before created_to",
+		})
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	searchInputDTO.Attributes = map[string][]string{}
+	for param, values := range c.Request.URL.Query() {
+		if key, ok := strings.CutPrefix(param, attributeParamPrefix); ok {
+			searchInputDTO.Attributes[key] = values
 		}
-	} else {
-		statusNumber = 0
 	}
 
-	auctions, err := u.auctionUseCase.FindAuctions(context.Background(),
-		auction_usecase.AuctionStatus(statusNumber), category, productName)
+	auctions, err := u.auctionUseCase.FindAuctions(context.Background(), searchInputDTO)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
@@ -84,3 +97,26 @@ func (u *AuctionController) FindWinningBidByAuctionId(c *gin.Context) {
 
 	c.JSON(http.StatusOK, auctionData)
 }
+
+func (u *AuctionController) SuggestProductNames(c *gin.Context) {
+	query := c.Query("q")
+
+	limit, errConv := strconv.ParseInt(c.DefaultQuery("limit", "10"), 10, 64)
+	if errConv != nil || limit < 1 || limit > 20 || len(query) > 100 {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "q,limit",
+			Message: "q must have at most 100 characters and limit must be between 1 and 20",
+		})
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	suggestions, err := u.auctionUseCase.SuggestProductNames(context.Background(), query, limit)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
@@ -44,6 +44,9 @@ func (u *BidController) CreateBid(c *gin.Context) {
 	err := u.bidUseCase.CreateBid(context.Background(), bidInputDTO)
 	if err != nil {
 		restErr := rest_err.ConvertError(err)
+		if restErr.Code == http.StatusTooManyRequests || restErr.Code == http.StatusServiceUnavailable {
+			c.Header("Retry-After", "1")
+		}
 
 		c.JSON(restErr.Code, restErr)
 		return
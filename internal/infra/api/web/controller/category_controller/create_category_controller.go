@@ -0,0 +1,102 @@
+package category_controller
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/category_usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CategoryController struct {
+	categoryUseCase category_usecase.CategoryUseCaseInterface
+}
+
+func NewCategoryController(categoryUseCase category_usecase.CategoryUseCaseInterface) *CategoryController {
+	return &CategoryController{
+		categoryUseCase: categoryUseCase,
+	}
+}
+
+func (cc *CategoryController) CreateCategory(c *gin.Context) {
+	var categoryInputDTO category_usecase.CategoryInputDTO
+
+	if err := c.ShouldBindJSON(&categoryInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	categoryData, err := cc.categoryUseCase.CreateCategory(context.Background(), categoryInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, categoryData)
+}
+
+func (cc *CategoryController) UpdateCategory(c *gin.Context) {
+	categoryId, ok := validateCategoryId(c)
+	if !ok {
+		return
+	}
+
+	var categoryUpdateInputDTO category_usecase.CategoryUpdateInputDTO
+
+	if err := c.ShouldBindJSON(&categoryUpdateInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	categoryData, err := cc.categoryUseCase.UpdateCategory(
+		context.Background(), categoryId, categoryUpdateInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, categoryData)
+}
+
+func (cc *CategoryController) DeleteCategory(c *gin.Context) {
+	categoryId, ok := validateCategoryId(c)
+	if !ok {
+		return
+	}
+
+	if err := cc.categoryUseCase.DeleteCategory(context.Background(), categoryId); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func validateCategoryId(c *gin.Context) (string, bool) {
+	categoryId := c.Param("categoryId")
+
+	if err := uuid.Validate(categoryId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "categoryId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return categoryId, true
+}
@@ -0,0 +1,36 @@
+package category_controller
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/rest_err"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (cc *CategoryController) FindCategoryById(c *gin.Context) {
+	categoryId, ok := validateCategoryId(c)
+	if !ok {
+		return
+	}
+
+	categoryData, err := cc.categoryUseCase.FindCategoryById(context.Background(), categoryId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, categoryData)
+}
+
+func (cc *CategoryController) FindCategoryTree(c *gin.Context) {
+	categories, err := cc.categoryUseCase.FindCategoryTree(context.Background())
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
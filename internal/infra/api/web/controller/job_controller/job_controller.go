@@ -0,0 +1,139 @@
+package job_controller
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase/job_usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type JobController struct {
+	jobUseCase job_usecase.JobUseCaseInterface
+}
+
+func NewJobController(jobUseCase job_usecase.JobUseCaseInterface) *JobController {
+	return &JobController{
+		jobUseCase: jobUseCase,
+	}
+}
+
+func (jc *JobController) FindJobs(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	var status *job_usecase.JobStatus
+	if statusParam := c.Query("status"); statusParam != "" {
+		statusNumber, errConv := strconv.Atoi(statusParam)
+		if errConv != nil {
+			errRest := rest_err.NewBadRequestError("Error trying to validate job status param")
+			c.JSON(errRest.Code, errRest)
+			return
+		}
+
+		jobStatus := job_usecase.JobStatus(statusNumber)
+		status = &jobStatus
+	}
+
+	jobs, err := jc.jobUseCase.FindJobs(
+		context.Background(), status, c.Query("type"), page, limit)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+func (jc *JobController) FindJobById(c *gin.Context) {
+	jobId, ok := validateJobId(c)
+	if !ok {
+		return
+	}
+
+	jobData, err := jc.jobUseCase.FindJobById(context.Background(), jobId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobData)
+}
+
+func (jc *JobController) RetryJob(c *gin.Context) {
+	jobId, ok := validateJobId(c)
+	if !ok {
+		return
+	}
+
+	jobData, err := jc.jobUseCase.RetryJob(context.Background(), jobId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobData)
+}
+
+func (jc *JobController) CancelJob(c *gin.Context) {
+	jobId, ok := validateJobId(c)
+	if !ok {
+		return
+	}
+
+	jobData, err := jc.jobUseCase.CancelJob(context.Background(), jobId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobData)
+}
+
+func validateJobId(c *gin.Context) (string, bool) {
+	jobId := c.Param("jobId")
+
+	if err := uuid.Validate(jobId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "jobId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return jobId, true
+}
+
+func parsePagination(c *gin.Context) (int64, int64, bool) {
+	page, errPage := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
+	limit, errLimit := strconv.ParseInt(
+		c.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)), 10, 64)
+
+	if errPage != nil || errLimit != nil || page < 1 || limit < 1 || limit > maxPageLimit {
+		errRest := rest_err.NewBadRequestError("Invalid pagination params", rest_err.Causes{
+			Field:   "page,limit",
+			Message: "page must be >= 1 and limit must be between 1 and 100",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return 0, 0, false
+	}
+
+	return page, limit, true
+}
@@ -0,0 +1,32 @@
+package leader_controller
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase/leader_usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LeaderController struct {
+	leaderUseCase leader_usecase.LeaderUseCaseInterface
+}
+
+func NewLeaderController(leaderUseCase leader_usecase.LeaderUseCaseInterface) *LeaderController {
+	return &LeaderController{
+		leaderUseCase: leaderUseCase,
+	}
+}
+
+func (lc *LeaderController) FindLeader(c *gin.Context) {
+	leaderData, err := lc.leaderUseCase.FindLeader(context.Background())
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaderData)
+}
@@ -0,0 +1,74 @@
+package user_controller
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/user_usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (u *UserController) CreateUser(c *gin.Context) {
+	var userInputDTO user_usecase.UserInputDTO
+
+	if err := c.ShouldBindJSON(&userInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	userData, err := u.userUseCase.CreateUser(context.Background(), userInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, userData)
+}
+
+func (u *UserController) UpdateUser(c *gin.Context) {
+	userId, ok := validateUserId(c)
+	if !ok {
+		return
+	}
+
+	var userUpdateInputDTO user_usecase.UserUpdateInputDTO
+
+	if err := c.ShouldBindJSON(&userUpdateInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	userData, err := u.userUseCase.UpdateUser(context.Background(), userId, userUpdateInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, userData)
+}
+
+func (u *UserController) DeactivateUser(c *gin.Context) {
+	userId, ok := validateUserId(c)
+	if !ok {
+		return
+	}
+
+	if err := u.userUseCase.DeactivateUser(context.Background(), userId); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
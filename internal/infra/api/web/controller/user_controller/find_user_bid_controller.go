@@ -0,0 +1,68 @@
+package user_controller
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (u *UserController) FindUserBids(c *gin.Context) {
+	userId, ok := validateUserId(c)
+	if !ok {
+		return
+	}
+
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	var auctionStatus *auction_usecase.AuctionStatus
+	if statusParam := c.Query("status"); statusParam != "" {
+		statusNumber, errConv := strconv.Atoi(statusParam)
+		if errConv != nil {
+			errRest := rest_err.NewBadRequestError("Error trying to validate auction status param")
+			c.JSON(errRest.Code, errRest)
+			return
+		}
+
+		status := auction_usecase.AuctionStatus(statusNumber)
+		auctionStatus = &status
+	}
+
+	userBids, err := u.userUseCase.FindUserBids(
+		context.Background(), userId, auctionStatus, page, limit)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, userBids)
+}
+
+func (u *UserController) FindUserWonAuctions(c *gin.Context) {
+	userId, ok := validateUserId(c)
+	if !ok {
+		return
+	}
+
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	wonAuctions, err := u.userUseCase.FindUserWonAuctions(
+		context.Background(), userId, page, limit)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, wonAuctions)
+}
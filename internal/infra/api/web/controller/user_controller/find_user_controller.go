@@ -5,11 +5,17 @@ import (
 	"fullcycle-auction_go/configuration/rest_err"
 	"fullcycle-auction_go/internal/usecase/user_usecase"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
 type UserController struct {
 	userUseCase user_usecase.UserUseCaseInterface
 }
@@ -21,15 +27,8 @@ func NewUserController(userUseCase user_usecase.UserUseCaseInterface) *UserContr
 }
 
 func (u *UserController) FindUserById(c *gin.Context) {
-	userId := c.Param("userId")
-
-	if err := uuid.Validate(userId); err != nil {
-		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
-			Field:   "userId",
-			Message: "Invalid UUID value",
-		})
-
-		c.JSON(errRest.Code, errRest)
+	userId, ok := validateUserId(c)
+	if !ok {
 		return
 	}
 
@@ -43,26 +42,66 @@ func (u *UserController) FindUserById(c *gin.Context) {
 	c.JSON(http.StatusOK, userData)
 }
 
-func (u *UserController) CreateUser(c *gin.Context) {
-	var userInputDTO user_usecase.UserOutputDTO
+func (u *UserController) FindUsers(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
 
-	if err := c.ShouldBindJSON(&userInputDTO); err != nil {
+	var status *user_usecase.UserStatus
+	if statusParam := c.Query("status"); statusParam != "" {
+		statusNumber, errConv := strconv.Atoi(statusParam)
+		if errConv != nil {
+			errRest := rest_err.NewBadRequestError("Error trying to validate user status param")
+			c.JSON(errRest.Code, errRest)
+			return
+		}
+
+		userStatus := user_usecase.UserStatus(statusNumber)
+		status = &userStatus
+	}
+
+	users, err := u.userUseCase.FindUsers(
+		context.Background(), c.Query("name"), c.Query("email"), status, page, limit)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func validateUserId(c *gin.Context) (string, bool) {
+	userId := c.Param("userId")
+
+	if err := uuid.Validate(userId); err != nil {
 		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
-			Field:   "user",
-			Message: err.Error(),
+			Field:   "userId",
+			Message: "Invalid UUID value",
 		})
 
 		c.JSON(errRest.Code, errRest)
-		return
+		return "", false
 	}
 
-	err := u.userUseCase.CreateUser(context.Background(), &userInputDTO)
-	if err != nil {
-		restErr := rest_err.ConvertError(err)
+	return userId, true
+}
 
-		c.JSON(restErr.Code, restErr)
-		return
+func parsePagination(c *gin.Context) (int64, int64, bool) {
+	page, errPage := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
+	limit, errLimit := strconv.ParseInt(
+		c.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)), 10, 64)
+
+	if errPage != nil || errLimit != nil || page < 1 || limit < 1 || limit > maxPageLimit {
+		errRest := rest_err.NewBadRequestError("Invalid pagination params", rest_err.Causes{
+			Field:   "page,limit",
+			Message: "page must be >= 1 and limit must be between 1 and 100",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return 0, 0, false
 	}
 
-	c.Status(http.StatusCreated)
+	return page, limit, true
 }
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/rest_err"
+
+	"github.com/gin-gonic/gin"
+)
+
+const AdminTokenHeader = "X-Admin-Token"
+
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminToken := config.Current().AdminToken
+		requestToken := c.GetHeader(AdminTokenHeader)
+
+		if adminToken == "" ||
+			subtle.ConstantTimeCompare([]byte(adminToken), []byte(requestToken)) != 1 {
+			restErr := rest_err.NewForbiddenError("Admin privileges are required for this operation")
+
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+
+		c.Next()
+	}
+}
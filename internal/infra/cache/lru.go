@@ -0,0 +1,125 @@
+// Package cache provides bounded in-process caches whose hit and miss
+// counters are published through expvar under the "cache" map.
+package cache
+
+import (
+	"container/list"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var metrics = expvar.NewMap("cache")
+
+type Stats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+	Size      int   `json:"size"`
+}
+
+// LRU keeps at most capacity entries, evicting the least recently used one
+// when full. Entries older than ttl are treated as missing.
+type LRU[K comparable, V any] struct {
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mutex sync.Mutex
+	items map[K]*list.Element
+	order *list.List
+
+	hits, misses, evictions atomic.Int64
+}
+
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRU creates a cache and publishes its stats as cache.<name>; creating
+// another cache with the same name replaces the published stats.
+func NewLRU[K comparable, V any](name string, capacity int, ttl time.Duration) *LRU[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	cache := &LRU[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		now:      time.Now,
+		items:    map[K]*list.Element{},
+		order:    list.New(),
+	}
+	metrics.Set(name, expvar.Func(func() interface{} { return cache.Stats() }))
+
+	return cache
+}
+
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.items[key]
+	if ok {
+		entry := element.Value.(*lruEntry[K, V])
+		if c.now().Before(entry.expiresAt) {
+			c.order.MoveToFront(element)
+			c.hits.Add(1)
+			return entry.value, true
+		}
+		c.removeElement(element)
+	}
+
+	c.misses.Add(1)
+	var zero V
+	return zero, false
+}
+
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value, entry.expiresAt = value, expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	if c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+func (c *LRU[K, V]) Delete(key K) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+func (c *LRU[K, V]) Stats() Stats {
+	c.mutex.Lock()
+	size := c.order.Len()
+	c.mutex.Unlock()
+
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      size,
+	}
+}
+
+func (c *LRU[K, V]) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*lruEntry[K, V]).key)
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRU[string, int]("test_lru_eviction", 2, time.Minute)
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	if value, ok := cache.Get("a"); !ok || value != 1 {
+		t.Errorf("expected a to be kept, got %v %v", value, ok)
+	}
+	if value, ok := cache.Get("c"); !ok || value != 3 {
+		t.Errorf("expected c to be kept, got %v %v", value, ok)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 3 || stats.Misses != 1 || stats.Evictions != 1 || stats.Size != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	now := time.Now()
+	cache := NewLRU[string, int]("test_lru_expiry", 10, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("a", 1)
+	now = now.Add(59 * time.Second)
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatalf("expected a before its ttl")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("expected a to expire after its ttl")
+	}
+	if stats := cache.Stats(); stats.Size != 0 {
+		t.Errorf("expected expired entry to be removed, got size %d", stats.Size)
+	}
+}
+
+func TestLRUDelete(t *testing.T) {
+	cache := NewLRU[string, int]("test_lru_delete", 10, time.Minute)
+
+	cache.Set("a", 1)
+	cache.Delete("a")
+	cache.Delete("missing")
+
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("expected a to be deleted")
+	}
+}
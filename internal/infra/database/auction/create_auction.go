@@ -2,11 +2,16 @@ package auction
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"fullcycle-auction_go/configuration/config"
 	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
 	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/infra/cache"
+	"fullcycle-auction_go/internal/infra/events"
 	"fullcycle-auction_go/internal/internal_error"
-	"os"
 	"sync"
 	"time"
 
@@ -18,70 +23,107 @@ import (
 type AuctionEntityMongo struct {
 	Id          string                          `bson:"_id,omitempty"`
 	ProductName string                          `bson:"product_name"`
+	CategoryId  string                          `bson:"category_id"`
 	Category    string                          `bson:"category"`
 	Description string                          `bson:"description"`
 	Condition   auction_entity.ProductCondition `bson:"condition"`
 	Status      string                          `bson:"status"`
 	Timestamp   int64                           `bson:"timestamp"`
+	StartsAt    int64                           `bson:"starts_at"`
+	EndTime     int64                           `bson:"end_time"`
+	HighestBid  float64                         `bson:"highest_bid"`
+	BidCount    int64                           `bson:"bid_count"`
+	Images      []AuctionImageMongo             `bson:"images,omitempty"`
+	Attributes  map[string]interface{}          `bson:"attributes,omitempty"`
+	Score       float64                         `bson:"score,omitempty"`
 }
 
-type AuctionRepository struct {
-	Collection *mongo.Collection
-	mutex      sync.Mutex
+type AuctionImageMongo struct {
+	Id           string `bson:"id"`
+	Key          string `bson:"key"`
+	ThumbnailKey string `bson:"thumbnail_key"`
+	ContentType  string `bson:"content_type"`
+	Size         int64  `bson:"size"`
+	Timestamp    int64  `bson:"timestamp"`
 }
 
-func NewAuctionRepository(database *mongo.Database) *AuctionRepository {
-	repo := &AuctionRepository{
-		Collection: database.Collection("auctions"),
+var (
+	auctionStatusToMongo = map[auction_entity.AuctionStatus]string{
+		auction_entity.Active:    "active",
+		auction_entity.Completed: "closed",
+		auction_entity.Cancelled: "cancelled",
+		auction_entity.Scheduled: "scheduled",
 	}
-	go repo.StartAuctionClosureWorker()
-	return repo
-}
-
-func getAuctionDuration() time.Duration {
-	durationStr := os.Getenv("AUCTION_DURATION")
-	if durationStr == "" {
-		durationStr = "10m"
+	auctionStatusFromMongo = map[string]auction_entity.AuctionStatus{
+		"active":    auction_entity.Active,
+		"closed":    auction_entity.Completed,
+		"cancelled": auction_entity.Cancelled,
+		"scheduled": auction_entity.Scheduled,
 	}
-	duration, err := time.ParseDuration(durationStr)
-	if err != nil {
-		return 10 * time.Minute
-	}
-	return duration
+)
+
+func StatusToMongo(status auction_entity.AuctionStatus) string {
+	return auctionStatusToMongo[status]
 }
 
-func (ar *AuctionRepository) StartAuctionClosureWorker() {
-	interval := getAuctionInterval()
-	for {
-		time.Sleep(interval)
-		ar.CloseExpiredAuctions()
-	}
+type AuctionRepository struct {
+	Collection    *mongo.Collection
+	StatusChanged *events.Bus[auction_entity.AuctionStatusChanged]
+	mutex         sync.Mutex
+	statusCache   *cache.LRU[string, auction_entity.AuctionState]
+	clock         clock.Clock
 }
 
-func getAuctionInterval() time.Duration {
-	intervalStr := os.Getenv("AUCTION_INTERVAL")
-	if intervalStr == "" {
-		intervalStr = "20s"
-	}
-	interval, err := time.ParseDuration(intervalStr)
-	if err != nil {
-		return 20 * time.Second
+func NewAuctionRepository(database *mongo.Database, clock clock.Clock) *AuctionRepository {
+	repo := &AuctionRepository{
+		Collection:    database.Collection("auctions"),
+		clock:         clock,
+		StatusChanged: events.NewBus[auction_entity.AuctionStatusChanged](),
+		statusCache: cache.NewLRU[string, auction_entity.AuctionState](
+			"auction_status", config.Current().AuctionStatusCacheSize, config.Current().AuctionStatusCacheTTL),
 	}
-	return interval
+	repo.StatusChanged.Subscribe(func(event auction_entity.AuctionStatusChanged) {
+		repo.statusCache.Delete(event.AuctionId)
+	})
+	return repo
 }
 
+// CloseExpiredAuctions completes every active auction whose end time has
+// passed.
 func (ar *AuctionRepository) CloseExpiredAuctions() {
 	ar.mutex.Lock()
 	defer ar.mutex.Unlock()
 
 	ctx := context.Background()
-	cutoffTime := time.Now().Unix()
 
 	filter := bson.M{
-		"status":    "active",
-		"timestamp": bson.M{"$lt": cutoffTime},
+		"status":   "active",
+		"end_time": bson.M{"$lte": ar.clock.Now().UnixMilli()},
 	}
 
+	cursor, err := ar.Collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		logger.Error("Error finding expired auctions", err)
+		return
+	}
+
+	var expired []struct {
+		Id string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &expired); err != nil {
+		logger.Error("Error decoding expired auctions", err)
+		return
+	}
+	if len(expired) == 0 {
+		return
+	}
+
+	auctionIds := make([]string, 0, len(expired))
+	for _, auction := range expired {
+		auctionIds = append(auctionIds, auction.Id)
+	}
+	filter["_id"] = bson.M{"$in": auctionIds}
+
 	update := bson.M{
 		"$set": bson.M{"status": "closed"},
 	}
@@ -92,14 +134,182 @@ func (ar *AuctionRepository) CloseExpiredAuctions() {
 		return
 	}
 
+	for _, auctionId := range auctionIds {
+		ar.StatusChanged.Publish(auction_entity.AuctionStatusChanged{
+			AuctionId: auctionId,
+			Status:    auction_entity.Completed,
+		})
+	}
+
 	if result.ModifiedCount > 0 {
 		logger.Info(fmt.Sprintf("Closed %d expired auctions", result.ModifiedCount))
 	}
 }
 
+// CloseAuction completes an auction if it is still active and its end time
+// has passed, reporting whether it was closed. An auction extended after it
+// was scheduled is left open. MongoDB cannot update the auction on a
+// condition over the leases collection, so a fence is checked right before
+// the update: it refuses the writes of a holder deposed before the check,
+// not one deposed between the check and the update.
+func (ar *AuctionRepository) CloseAuction(
+	ctx context.Context, id string, fence lease_entity.Fence) (bool, *internal_error.InternalError) {
+	if !fence.IsZero() {
+		holds, err := ar.leaseHasToken(ctx, fence)
+		if err != nil || !holds {
+			return false, err
+		}
+	}
+
+	filter := bson.M{
+		"_id":      id,
+		"status":   auctionStatusToMongo[auction_entity.Active],
+		"end_time": bson.M{"$lte": ar.clock.Now().UnixMilli()},
+	}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"status": "closed"}})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to close auction %s", id), err)
+		return false, internal_error.NewInternalServerError("Error trying to close auction")
+	}
+
+	if result.ModifiedCount == 0 {
+		return false, nil
+	}
+
+	ar.StatusChanged.Publish(auction_entity.AuctionStatusChanged{
+		AuctionId: id,
+		Status:    auction_entity.Completed,
+	})
+
+	return true, nil
+}
+
+// StartAuction opens a scheduled auction for bids, reporting whether it did.
+func (ar *AuctionRepository) StartAuction(
+	ctx context.Context, id string) (bool, *internal_error.InternalError) {
+	filter := bson.M{"_id": id, "status": auctionStatusToMongo[auction_entity.Scheduled]}
+	update := bson.M{"$set": bson.M{"status": auctionStatusToMongo[auction_entity.Active]}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to start auction %s", id), err)
+		return false, internal_error.NewInternalServerError("Error trying to start auction")
+	}
+
+	if result.ModifiedCount == 0 {
+		return false, nil
+	}
+
+	ar.StatusChanged.Publish(auction_entity.AuctionStatusChanged{
+		AuctionId: id,
+		Status:    auction_entity.Active,
+	})
+
+	return true, nil
+}
+
+// ExtendAuction moves the end time of an active auction forward. The update
+// only matches while the auction is open and ends before endTime, so it cannot
+// reopen an auction that closed in the meantime.
+func (ar *AuctionRepository) ExtendAuction(
+	ctx context.Context,
+	id string,
+	endTime time.Time) (*auction_entity.Auction, *internal_error.InternalError) {
+	now := ar.clock.Now().UnixMilli()
+	filter := bson.M{
+		"_id":    id,
+		"status": auctionStatusToMongo[auction_entity.Active],
+		"end_time": bson.M{
+			"$gt": now,
+			"$lt": endTime.UnixMilli(),
+		},
+	}
+
+	var auctionEntityMongo AuctionEntityMongo
+	err := ar.Collection.FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": bson.M{"end_time": endTime.UnixMilli()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&auctionEntityMongo)
+	if err == mongo.ErrNoDocuments {
+		auction, findErr := ar.FindAuctionById(ctx, id)
+		if findErr != nil {
+			return nil, findErr
+		}
+		if auction.Status != auction_entity.Active || auction.EndTime.UnixMilli() <= now {
+			return nil, internal_error.NewBadRequestError("Only active auctions can be extended")
+		}
+		return nil, internal_error.NewBadRequestError("End time must be after the current end time")
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to extend auction %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to extend auction")
+	}
+
+	ar.statusCache.Delete(id)
+
+	return toAuctionEntity(auctionEntityMongo), nil
+}
+
+// FindAuctionEndTimes lists the end time of every active auction.
+func (ar *AuctionRepository) FindAuctionEndTimes(
+	ctx context.Context) ([]auction_entity.AuctionEndTime, *internal_error.InternalError) {
+	cursor, err := ar.Collection.Find(ctx,
+		bson.M{"status": auctionStatusToMongo[auction_entity.Active]},
+		options.Find().SetProjection(bson.M{"_id": 1, "end_time": 1}))
+	if err != nil {
+		logger.Error("Error trying to find auction end times", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction end times")
+	}
+
+	var auctions []AuctionEntityMongo
+	if err := cursor.All(ctx, &auctions); err != nil {
+		logger.Error("Error trying to decode auction end times", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction end times")
+	}
+
+	endTimes := make([]auction_entity.AuctionEndTime, 0, len(auctions))
+	for _, auction := range auctions {
+		endTimes = append(endTimes, auction_entity.AuctionEndTime{
+			AuctionId: auction.Id,
+			EndTime:   time.UnixMilli(auction.EndTime),
+		})
+	}
+
+	return endTimes, nil
+}
+
+// FindOverdueScheduledAuctions lists the ids of the scheduled auctions whose
+// start time is not after cutoff, through the status_starts_at index.
+func (ar *AuctionRepository) FindOverdueScheduledAuctions(
+	ctx context.Context, cutoff time.Time) ([]string, *internal_error.InternalError) {
+	cursor, err := ar.Collection.Find(ctx,
+		bson.M{
+			"status":    auctionStatusToMongo[auction_entity.Scheduled],
+			"starts_at": bson.M{"$lte": cutoff.UnixMilli()},
+		},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		logger.Error("Error trying to find overdue scheduled auctions", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find overdue scheduled auctions")
+	}
+
+	var auctions []AuctionEntityMongo
+	if err := cursor.All(ctx, &auctions); err != nil {
+		logger.Error("Error trying to decode overdue scheduled auctions", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find overdue scheduled auctions")
+	}
+
+	auctionIds := make([]string, 0, len(auctions))
+	for _, auction := range auctions {
+		auctionIds = append(auctionIds, auction.Id)
+	}
+
+	return auctionIds, nil
+}
+
 func NewMongoDBConnection(ctx context.Context) (*mongo.Database, error) {
-	mongoURL := os.Getenv("MONGODB_URL")
-	databaseName := os.Getenv("MONGODB_DB")
+	mongoURL := config.Current().MongoDBURL
+	databaseName := config.Current().MongoDBDB
 	if mongoURL == "" {
 		return nil, fmt.Errorf("MONGODB_URL is not set")
 	}
@@ -117,26 +327,180 @@ func NewMongoDBConnection(ctx context.Context) (*mongo.Database, error) {
 }
 
 func (ar *AuctionRepository) CreateAuction(ctx context.Context, auctionEntity *auction_entity.Auction) *internal_error.InternalError {
-	statusMap := map[auction_entity.AuctionStatus]string{
-		0: "active",
-		1: "closed",
+	auctionEntityMongo := newAuctionEntityMongo(auctionEntity)
+
+	_, err := ar.Collection.InsertOne(ctx, auctionEntityMongo)
+	if err != nil {
+		logger.Error("Error trying to insert auction", err)
+		return internal_error.NewInternalServerError("Error trying to insert auction")
 	}
 
-	auctionEntityMongo := AuctionEntityMongo{
+	ar.cacheNewAuction(auctionEntity.Status, auctionEntityMongo)
+
+	return nil
+}
+
+// CreateAuctions inserts the batch with a single ordered InsertMany. Without
+// a transaction, the auctions inserted before a failure are deleted again.
+func (ar *AuctionRepository) CreateAuctions(
+	ctx context.Context, auctionEntities []*auction_entity.Auction) *internal_error.InternalError {
+	if len(auctionEntities) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, len(auctionEntities))
+	ids := make([]string, len(auctionEntities))
+	for i, auctionEntity := range auctionEntities {
+		documents[i] = newAuctionEntityMongo(auctionEntity)
+		ids[i] = auctionEntity.Id
+	}
+
+	if _, err := ar.Collection.InsertMany(ctx, documents); err != nil {
+		logger.Error("Error trying to insert auctions", err)
+
+		// An ordered insert stops at the first failed document, which may be
+		// an auction stored before under the same id: only the documents
+		// ahead of it are removed.
+		var writeErr mongo.BulkWriteException
+		if errors.As(err, &writeErr) && len(writeErr.WriteErrors) > 0 {
+			ids = ids[:writeErr.WriteErrors[0].Index]
+		}
+		if _, err := ar.Collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			logger.Error("Error trying to remove the auctions of a failed batch", err)
+		}
+		return internal_error.NewInternalServerError("Error trying to insert auctions")
+	}
+
+	for i, auctionEntity := range auctionEntities {
+		ar.cacheNewAuction(auctionEntity.Status, documents[i].(AuctionEntityMongo))
+	}
+
+	return nil
+}
+
+func newAuctionEntityMongo(auctionEntity *auction_entity.Auction) AuctionEntityMongo {
+	return AuctionEntityMongo{
 		Id:          auctionEntity.Id,
 		ProductName: auctionEntity.ProductName,
+		CategoryId:  auctionEntity.CategoryId,
 		Category:    auctionEntity.Category,
 		Description: auctionEntity.Description,
 		Condition:   auctionEntity.Condition,
-		Status:      statusMap[auctionEntity.Status],
+		Status:      auctionStatusToMongo[auctionEntity.Status],
 		Timestamp:   auctionEntity.Timestamp.Unix(),
+		StartsAt:    auctionEntity.StartsAt.UnixMilli(),
+		EndTime:     auctionEntity.EndTime.UnixMilli(),
+		Attributes:  auctionEntity.Attributes,
 	}
+}
 
-	_, err := ar.Collection.InsertOne(ctx, auctionEntityMongo)
+func (ar *AuctionRepository) cacheNewAuction(
+	status auction_entity.AuctionStatus, auctionEntityMongo AuctionEntityMongo) {
+	ar.statusCache.Set(auctionEntityMongo.Id, auction_entity.AuctionState{
+		Status:   status,
+		StartsAt: time.UnixMilli(auctionEntityMongo.StartsAt),
+		EndTime:  time.UnixMilli(auctionEntityMongo.EndTime),
+	})
+}
+
+func (ar *AuctionRepository) leaseHasToken(
+	ctx context.Context, fence lease_entity.Fence) (bool, *internal_error.InternalError) {
+	count, err := ar.Collection.Database().Collection("leases").CountDocuments(ctx,
+		bson.M{"_id": fence.Lease, "token": fence.Token}, options.Count().SetLimit(1))
 	if err != nil {
-		logger.Error("Error trying to insert auction", err)
-		return internal_error.NewInternalServerError("Error trying to insert auction")
+		logger.Error(fmt.Sprintf("Error trying to check lease %s", fence.Lease), err)
+		return false, internal_error.NewInternalServerError("Error trying to close auction")
 	}
 
-	return nil
+	if count == 0 {
+		logger.Info(fmt.Sprintf("Lease %s no longer has token %d", fence.Lease, fence.Token))
+	}
+	return count > 0, nil
+}
+
+// FindAuctionState returns the status, start and end time of an auction,
+// served from the status cache when possible.
+func (ar *AuctionRepository) FindAuctionState(
+	ctx context.Context, id string) (*auction_entity.AuctionState, *internal_error.InternalError) {
+	if entry, ok := ar.statusCache.Get(id); ok {
+		return &entry, nil
+	}
+
+	var auctionEntityMongo AuctionEntityMongo
+	opts := options.FindOne().SetProjection(bson.M{"status": 1, "starts_at": 1, "end_time": 1})
+	if err := ar.Collection.FindOne(ctx, bson.M{"_id": id}, opts).Decode(&auctionEntityMongo); err != nil {
+		if err == mongo.ErrNoDocuments {
+			logger.Info(fmt.Sprintf("Auction not found with id = %s", id))
+			return nil, internal_error.NewNotFoundError("Auction not found")
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find status of auction %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction by id")
+	}
+
+	entry := auction_entity.AuctionState{
+		Status:   auctionStatusFromMongo[auctionEntityMongo.Status],
+		StartsAt: time.UnixMilli(auctionEntityMongo.StartsAt),
+		EndTime:  time.UnixMilli(auctionEntityMongo.EndTime),
+	}
+	ar.statusCache.Set(id, entry)
+
+	return &entry, nil
+}
+
+// AcceptBids records count bids of an auction on its stats in a single
+// conditional update, matching only while the auction is active and within
+// its end time. Closure updates the same document, so bids are either counted
+// before the auction closes or rejected; it reports false when they were
+// rejected.
+func (ar *AuctionRepository) AcceptBids(
+	ctx context.Context, auctionId string, highestAmount float64, count int) (bool, *internal_error.InternalError) {
+	filter := bson.M{
+		"_id":      auctionId,
+		"status":   auctionStatusToMongo[auction_entity.Active],
+		"end_time": bson.M{"$gt": ar.clock.Now().UnixMilli()},
+	}
+
+	update := bson.M{
+		"$max": bson.M{"highest_bid": highestAmount},
+		"$inc": bson.M{"bid_count": count},
+	}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to accept bids on auction %s", auctionId), err)
+		return false, internal_error.NewInternalServerError("Error trying to update auction bid stats")
+	}
+
+	if result.MatchedCount == 0 {
+		// The auction may have been closed by another instance; drop the
+		// cached status so the next bid sees the stored one.
+		ar.statusCache.Delete(auctionId)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// RevertAcceptedBids undoes the stats of count accepted bids that could not
+// be stored. The highest bid is only lowered to storedHighest, the highest
+// stored bid, while it is still revertedHighest, the highest of the bids
+// reverted; a higher bid accepted meanwhile is kept.
+func (ar *AuctionRepository) RevertAcceptedBids(
+	ctx context.Context, auctionId string, count int, revertedHighest, storedHighest float64) {
+	_, err := ar.Collection.UpdateByID(ctx, auctionId, bson.M{"$inc": bson.M{"bid_count": -count}})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to revert bid count of auction %s", auctionId), err)
+	}
+
+	if storedHighest >= revertedHighest {
+		return
+	}
+
+	_, err = ar.Collection.UpdateOne(ctx,
+		bson.M{"_id": auctionId, "highest_bid": revertedHighest},
+		bson.M{"$set": bson.M{"highest_bid": storedHighest}})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to revert highest bid of auction %s", auctionId), err)
+	}
 }
@@ -2,6 +2,8 @@ package auction
 
 import (
 	"context"
+	"fullcycle-auction_go/internal/clock"
+	"os"
 	"testing"
 	"time"
 
@@ -9,13 +11,17 @@ import (
 )
 
 func TestCloseExpiredAuctions(t *testing.T) {
+	if os.Getenv("MONGODB_URL") == "" {
+		t.Skip("MONGODB_URL is not set")
+	}
+
 	ctx := context.Background()
 	db, err := NewMongoDBConnection(ctx)
 	if err != nil {
 		t.Fatalf("Failed to connect to MongoDB: %v", err)
 	}
 
-	repo := NewAuctionRepository(db)
+	repo := NewAuctionRepository(db, clock.Real)
 
 	repo.Collection.DeleteOne(ctx, bson.M{"_id": "test-auction"})
 
@@ -23,6 +29,7 @@ func TestCloseExpiredAuctions(t *testing.T) {
 		"_id":       "test-auction",
 		"status":    "active",
 		"timestamp": time.Now().Add(-15 * time.Minute).Unix(),
+		"end_time":  time.Now().Add(-5 * time.Minute).UnixMilli(),
 	})
 	if err != nil {
 		t.Fatalf("Failed to insert test auction: %v", err)
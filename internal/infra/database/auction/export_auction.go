@@ -0,0 +1,87 @@
+package auction
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExportSort is the order exports go through auctions in.
+var ExportSort = bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}}
+
+// ExportFilterToMongo returns the query matching the auctions selected by an
+// export filter.
+func ExportFilterToMongo(exportFilter auction_entity.AuctionExportFilter) bson.M {
+	filter := bson.M{}
+
+	if exportFilter.Status != nil {
+		filter["status"] = auctionStatusToMongo[*exportFilter.Status]
+	}
+
+	if len(exportFilter.CategoryIds) > 0 {
+		filter["category_id"] = bson.M{"$in": exportFilter.CategoryIds}
+	}
+
+	if exportFilter.CreatedFrom != nil || exportFilter.CreatedTo != nil {
+		timestampFilter := bson.M{}
+		if exportFilter.CreatedFrom != nil {
+			timestampFilter["$gte"] = exportFilter.CreatedFrom.Unix()
+		}
+		if exportFilter.CreatedTo != nil {
+			timestampFilter["$lte"] = exportFilter.CreatedTo.Unix()
+		}
+		filter["timestamp"] = timestampFilter
+	}
+
+	if exportFilter.EndedFrom != nil || exportFilter.EndedTo != nil {
+		endTimeFilter := bson.M{}
+		if exportFilter.EndedFrom != nil {
+			endTimeFilter["$gte"] = exportFilter.EndedFrom.UnixMilli()
+		}
+		if exportFilter.EndedTo != nil {
+			endTimeFilter["$lte"] = exportFilter.EndedTo.UnixMilli()
+		}
+		filter["end_time"] = endTimeFilter
+	}
+
+	return filter
+}
+
+func (ar *AuctionRepository) ExportAuctions(
+	ctx context.Context,
+	exportFilter auction_entity.AuctionExportFilter,
+	fn func(auction *auction_entity.Auction) *internal_error.InternalError) *internal_error.InternalError {
+	logger.Info(fmt.Sprintf("Exporting auctions with filter = %+v", exportFilter))
+
+	opts := options.Find().SetSort(ExportSort).SetProjection(bson.M{"images": 0})
+	cursor, err := ar.Collection.Find(ctx, ExportFilterToMongo(exportFilter), opts)
+	if err != nil {
+		logger.Error("Error exporting auctions", err)
+		return internal_error.NewInternalServerError("Error exporting auctions")
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var auctionEntityMongo AuctionEntityMongo
+		if err := cursor.Decode(&auctionEntityMongo); err != nil {
+			logger.Error("Error decoding exported auction", err)
+			return internal_error.NewInternalServerError("Error exporting auctions")
+		}
+
+		if err := fn(toAuctionEntity(auctionEntityMongo)); err != nil {
+			return err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		logger.Error("Error exporting auctions", err)
+		return internal_error.NewInternalServerError("Error exporting auctions")
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/internal_error"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,6 +14,15 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+var auctionSortFields = map[auction_entity.AuctionSort]string{
+	auction_entity.SortNewest:          "timestamp",
+	auction_entity.SortEndingSoonest:   "end_time",
+	auction_entity.SortStartingSoonest: "starts_at",
+	auction_entity.SortHighestBid:      "highest_bid",
+	auction_entity.SortMostBids:        "bid_count",
+	auction_entity.SortRelevance:       "score",
+}
+
 func (ar *AuctionRepository) FindAuctionById(
 	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
 
@@ -35,46 +45,94 @@ func (ar *AuctionRepository) FindAuctionById(
 		return nil, internal_error.NewInternalServerError("Error trying to find auction by id")
 	}
 
-	statusMap := map[string]auction_entity.AuctionStatus{
-		"active": 0,
-		"closed": 1,
-	}
-
-	return &auction_entity.Auction{
-		Id:          auctionEntityMongo.Id,
-		ProductName: auctionEntityMongo.ProductName,
-		Category:    auctionEntityMongo.Category,
-		Description: auctionEntityMongo.Description,
-		Condition:   auctionEntityMongo.Condition,
-		Status:      statusMap[auctionEntityMongo.Status],
-		Timestamp:   time.Unix(auctionEntityMongo.Timestamp, 0),
-	}, nil
+	return toAuctionEntity(auctionEntityMongo), nil
 }
 
 func (repo *AuctionRepository) FindAuctions(
 	ctx context.Context,
-	status auction_entity.AuctionStatus,
-	category string,
-	productName string) ([]auction_entity.Auction, *internal_error.InternalError) {
+	auctionFilter auction_entity.AuctionFilter) (*auction_entity.AuctionPage, *internal_error.InternalError) {
 	filter := bson.M{}
 
-	// if status != 0 {
-	// 	filter["status"] = status
-	// }
+	if auctionFilter.Query != "" {
+		filter["$text"] = bson.M{"$search": auctionFilter.Query}
+	}
+
+	if auctionFilter.Status != nil {
+		filter["status"] = auctionStatusToMongo[*auctionFilter.Status]
+	}
+
+	if len(auctionFilter.CategoryIds) > 0 {
+		filter["category_id"] = bson.M{"$in": auctionFilter.CategoryIds}
+	}
+
+	if auctionFilter.ProductName != "" {
+		filter["product_name"] = primitive.Regex{
+			Pattern: regexp.QuoteMeta(auctionFilter.ProductName), Options: "i"}
+	}
+
+	if auctionFilter.CreatedFrom != nil || auctionFilter.CreatedTo != nil {
+		timestampFilter := bson.M{}
+		if auctionFilter.CreatedFrom != nil {
+			timestampFilter["$gte"] = auctionFilter.CreatedFrom.Unix()
+		}
+		if auctionFilter.CreatedTo != nil {
+			timestampFilter["$lte"] = auctionFilter.CreatedTo.Unix()
+		}
+		filter["timestamp"] = timestampFilter
+	}
+
+	for _, attributeFilter := range auctionFilter.Attributes {
+		field := "attributes." + attributeFilter.Key
+		fieldFilter, ok := filter[field].(bson.M)
+		if !ok {
+			fieldFilter = bson.M{}
+			filter[field] = fieldFilter
+		}
+
+		if attributeFilter.Operator == auction_entity.AttributeEq {
+			fieldFilter["$in"] = attributeFilter.Values
+		} else if len(attributeFilter.Values) > 0 {
+			fieldFilter["$"+string(attributeFilter.Operator)] = attributeFilter.Values[0]
+		}
+	}
+
+	logger.Info(fmt.Sprintf("Finding auctions with filter = %+v", auctionFilter))
+
+	total, err := repo.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error("Error counting auctions", err)
+		return nil, internal_error.NewInternalServerError("Error finding auctions")
+	}
+
+	pipeline := []bson.M{{"$match": filter}}
+	if auctionFilter.Sort == auction_entity.SortRelevance {
+		pipeline = append(pipeline, bson.M{"$addFields": bson.M{"score": bson.M{"$meta": "textScore"}}})
+	}
 
-	if category != "" {
-		filter["category"] = category
+	sortField := auctionSortFields[auctionFilter.Sort]
+	sortDirection, cursorOperator := 1, "$gt"
+	if auctionFilter.Sort.Descending() {
+		sortDirection, cursorOperator = -1, "$lt"
 	}
 
-	if productName != "" {
-		filter["productName"] = primitive.Regex{Pattern: productName, Options: "i"}
+	if auctionFilter.After != "" {
+		cursor, err := auction_entity.DecodeAuctionCursor(auctionFilter.After)
+		if err != nil {
+			return nil, err
+		}
+
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"$or": bson.A{
+			bson.M{sortField: bson.M{cursorOperator: cursor.Value}},
+			bson.M{sortField: cursor.Value, "_id": bson.M{"$gt": cursor.Id}},
+		}}})
 	}
 
-	//add logs data
-	logger.Info(fmt.Sprintf("Finding auctions with status = %d, category = %s, productName = %s", status, category, productName))
-	//add logs data
+	pipeline = append(pipeline,
+		bson.M{"$sort": bson.D{{Key: sortField, Value: sortDirection}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": auctionFilter.Limit + 1},
+	)
 
-	cursor, err := repo.Collection.Find(ctx, filter)
+	cursor, err := repo.Collection.Aggregate(ctx, pipeline)
 	if err != nil {
 		logger.Error("Error finding auctions", err)
 		return nil, internal_error.NewInternalServerError("Error finding auctions")
@@ -87,25 +145,97 @@ func (repo *AuctionRepository) FindAuctions(
 		return nil, internal_error.NewInternalServerError("Error decoding auctions")
 	}
 
-	// Exibir os dados brutos retornados do MongoDB para verificar a estrutura
-	logger.Info(fmt.Sprintf("Raw MongoDB Data: %+v", auctionsMongo))
+	auctionPage := &auction_entity.AuctionPage{
+		Auctions: []auction_entity.Auction{},
+		Total:    total,
+	}
+
+	for i, auction := range auctionsMongo {
+		if int64(i) == auctionFilter.Limit {
+			lastAuction := auctionsMongo[i-1]
+			auctionPage.NextCursor = auction_entity.AuctionCursor{
+				Value: lastAuction.sortValue(auctionFilter.Sort),
+				Id:    lastAuction.Id,
+			}.Encode()
+			break
+		}
+
+		auctionPage.Auctions = append(auctionPage.Auctions, *toAuctionEntity(auction))
+	}
+
+	return auctionPage, nil
+}
+
+func (repo *AuctionRepository) SuggestProductNames(
+	ctx context.Context,
+	prefix string,
+	limit int64) ([]string, *internal_error.InternalError) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"product_name": primitive.Regex{
+			Pattern: "^" + regexp.QuoteMeta(prefix), Options: "i"}}},
+		{"$group": bson.M{"_id": "$product_name"}},
+		{"$sort": bson.M{"_id": 1}},
+		{"$limit": limit},
+	}
+
+	cursor, err := repo.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error suggesting product names for prefix %s", prefix), err)
+		return nil, internal_error.NewInternalServerError("Error suggesting product names")
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ProductName string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		logger.Error(fmt.Sprintf("Error decoding product name suggestions for prefix %s", prefix), err)
+		return nil, internal_error.NewInternalServerError("Error suggesting product names")
+	}
+
+	suggestions := make([]string, 0, len(results))
+	for _, result := range results {
+		suggestions = append(suggestions, result.ProductName)
+	}
 
-	statusMap := map[string]auction_entity.AuctionStatus{
-		"active": 0,
-		"closed": 1,
+	return suggestions, nil
+}
+
+func (am AuctionEntityMongo) sortValue(sort auction_entity.AuctionSort) float64 {
+	if sort == auction_entity.SortRelevance {
+		return am.Score
 	}
-	var auctionsEntity []auction_entity.Auction
-	for _, auction := range auctionsMongo {
-		auctionsEntity = append(auctionsEntity, auction_entity.Auction{
-			Id:          auction.Id,
-			ProductName: auction.ProductName,
-			Category:    auction.Category,
-			Status:      statusMap[auction.Status],
-			Description: auction.Description,
-			Condition:   auction.Condition,
-			Timestamp:   time.Unix(auction.Timestamp, 0),
+
+	return toAuctionEntity(am).SortValue(sort)
+}
+
+func toAuctionEntity(auctionEntityMongo AuctionEntityMongo) *auction_entity.Auction {
+	images := make([]auction_entity.AuctionImage, 0, len(auctionEntityMongo.Images))
+	for _, image := range auctionEntityMongo.Images {
+		images = append(images, auction_entity.AuctionImage{
+			Id:           image.Id,
+			Key:          image.Key,
+			ThumbnailKey: image.ThumbnailKey,
+			ContentType:  image.ContentType,
+			Size:         image.Size,
+			Timestamp:    time.Unix(image.Timestamp, 0),
 		})
 	}
 
-	return auctionsEntity, nil
+	return &auction_entity.Auction{
+		Id:          auctionEntityMongo.Id,
+		ProductName: auctionEntityMongo.ProductName,
+		CategoryId:  auctionEntityMongo.CategoryId,
+		Category:    auctionEntityMongo.Category,
+		Description: auctionEntityMongo.Description,
+		Condition:   auctionEntityMongo.Condition,
+		Status:      auctionStatusFromMongo[auctionEntityMongo.Status],
+		Timestamp:   time.Unix(auctionEntityMongo.Timestamp, 0),
+		StartsAt:    time.UnixMilli(auctionEntityMongo.StartsAt),
+		EndTime:     time.UnixMilli(auctionEntityMongo.EndTime),
+		HighestBid:  auctionEntityMongo.HighestBid,
+		BidCount:    auctionEntityMongo.BidCount,
+		Images:      images,
+		Attributes:  auctionEntityMongo.Attributes,
+	}
 }
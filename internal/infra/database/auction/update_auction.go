@@ -0,0 +1,180 @@
+package auction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (ar *AuctionRepository) AddAuctionImage(
+	ctx context.Context,
+	auctionId string,
+	image auction_entity.AuctionImage,
+	maxImages int) *internal_error.InternalError {
+	filter := bson.M{
+		"_id":                                 auctionId,
+		"status":                              bson.M{"$in": openStatuses()},
+		fmt.Sprintf("images.%d", maxImages-1): bson.M{"$exists": false},
+	}
+
+	update := bson.M{
+		"$push": bson.M{"images": AuctionImageMongo{
+			Id:           image.Id,
+			Key:          image.Key,
+			ThumbnailKey: image.ThumbnailKey,
+			ContentType:  image.ContentType,
+			Size:         image.Size,
+			Timestamp:    image.Timestamp.Unix(),
+		}},
+	}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to add image to auction %s", auctionId), err)
+		return internal_error.NewInternalServerError("Error trying to add auction image")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewBadRequestError(fmt.Sprintf(
+			"Auction must be active or scheduled and have less than %d images", maxImages))
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) RemoveAuctionImage(
+	ctx context.Context,
+	auctionId, imageId string) *internal_error.InternalError {
+	filter := bson.M{"_id": auctionId, "images.id": imageId}
+	update := bson.M{"$pull": bson.M{"images": bson.M{"id": imageId}}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to remove image %s from auction %s", imageId, auctionId), err)
+		return internal_error.NewInternalServerError("Error trying to remove auction image")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError("Auction image not found")
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) CancelAuction(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	filter := bson.M{"_id": id, "status": bson.M{"$in": openStatuses()}}
+	update := bson.M{
+		"$set":   bson.M{"status": auctionStatusToMongo[auction_entity.Cancelled]},
+		"$unset": bson.M{"images": ""},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+
+	var auctionEntityMongo AuctionEntityMongo
+	if err := ar.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&auctionEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, findErr := ar.FindAuctionById(ctx, id); findErr != nil {
+				return nil, findErr
+			}
+			return nil, internal_error.NewBadRequestError("Only active or scheduled auctions can be cancelled")
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to cancel auction %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to cancel auction")
+	}
+
+	ar.StatusChanged.Publish(auction_entity.AuctionStatusChanged{
+		AuctionId: id,
+		Status:    auction_entity.Cancelled,
+	})
+
+	logger.Info(fmt.Sprintf("Auction %s cancelled", id))
+
+	return toAuctionEntity(auctionEntityMongo), nil
+}
+
+// openStatuses are the stored statuses of auctions that are not finished.
+func openStatuses() bson.A {
+	return bson.A{
+		auctionStatusToMongo[auction_entity.Active],
+		auctionStatusToMongo[auction_entity.Scheduled],
+	}
+}
+
+// ForceCloseAuction completes an active auction right away, moving its end
+// time to now so bids racing with it are rejected like after a regular
+// closure.
+func (ar *AuctionRepository) ForceCloseAuction(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	filter := bson.M{"_id": id, "status": auctionStatusToMongo[auction_entity.Active]}
+	update := bson.M{"$set": bson.M{
+		"status":   auctionStatusToMongo[auction_entity.Completed],
+		"end_time": ar.clock.Now().UnixMilli(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var auctionEntityMongo AuctionEntityMongo
+	if err := ar.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&auctionEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, findErr := ar.FindAuctionById(ctx, id); findErr != nil {
+				return nil, findErr
+			}
+			return nil, internal_error.NewBadRequestError("Only active auctions can be closed")
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to close auction %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to close auction")
+	}
+
+	ar.StatusChanged.Publish(auction_entity.AuctionStatusChanged{
+		AuctionId: id,
+		Status:    auction_entity.Completed,
+	})
+
+	logger.Info(fmt.Sprintf("Auction %s closed", id))
+
+	return toAuctionEntity(auctionEntityMongo), nil
+}
+
+// UpdateBidStats overwrites the highest bid and bid count of an auction, such
+// as after recomputing them from its stored bids.
+func (ar *AuctionRepository) UpdateBidStats(
+	ctx context.Context,
+	id string,
+	highestBid float64,
+	bidCount int64) *internal_error.InternalError {
+	result, err := ar.Collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{
+		"highest_bid": highestBid,
+		"bid_count":   bidCount,
+	}})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to update bid stats of auction %s", id), err)
+		return internal_error.NewInternalServerError("Error trying to update auction bid stats")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError("Auction not found")
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) RenameCategory(
+	ctx context.Context, categoryId, name string) *internal_error.InternalError {
+	_, err := ar.Collection.UpdateMany(ctx,
+		bson.M{"category_id": categoryId},
+		bson.M{"$set": bson.M{"category": name}})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to rename category %s of auctions", categoryId), err)
+		return internal_error.NewInternalServerError("Error trying to rename the category of auctions")
+	}
+
+	return nil
+}
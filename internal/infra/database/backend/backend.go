@@ -0,0 +1,112 @@
+// Package backend opens the storage backend selected by the configuration
+// and builds its repositories, so the server and the admin CLI share them.
+package backend
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/entity/job_entity"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/database/auction"
+	"fullcycle-auction_go/internal/infra/database/bid"
+	"fullcycle-auction_go/internal/infra/database/category"
+	"fullcycle-auction_go/internal/infra/database/job"
+	"fullcycle-auction_go/internal/infra/database/lease"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"fullcycle-auction_go/internal/infra/database/sqldb"
+	"fullcycle-auction_go/internal/infra/database/user"
+	"os"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaultSQLitePath is used when the sqlite backend has no SQL_DSN.
+const defaultSQLitePath = "data/auction.db"
+
+type Repositories struct {
+	Auctions   auction_entity.AuctionRepositoryInterface
+	Bids       bid_entity.BidEntityRepository
+	Users      user_entity.UserRepositoryInterface
+	Categories category_entity.CategoryRepositoryInterface
+	Leases     lease_entity.LeaseRepositoryInterface
+	Jobs       job_entity.JobRepositoryInterface
+
+	// Mongo is the database of the mongodb backend, nil on the others.
+	Mongo *mongo.Database
+}
+
+// Open connects to the backend of cfg and builds its repositories. The
+// returned close function releases the connection.
+func Open(
+	ctx context.Context,
+	cfg *config.Config,
+	clock clock.Clock) (*Repositories, func(context.Context) error, error) {
+	switch cfg.StorageBackend {
+	case config.MemoryBackend:
+		auctionRepository := memory.NewAuctionRepository(clock)
+		leaseRepository := memory.NewLeaseRepository(clock)
+		auctionRepository.UseLeases(leaseRepository)
+		return &Repositories{
+			Auctions:   auctionRepository,
+			Bids:       memory.NewBidRepository(auctionRepository),
+			Users:      memory.NewUserRepository(),
+			Categories: memory.NewCategoryRepository(),
+			Leases:     leaseRepository,
+			Jobs:       memory.NewJobRepository(),
+		}, func(context.Context) error { return nil }, nil
+
+	case config.SQLiteBackend, config.PostgresBackend:
+		dsn, err := sqlDSN(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		database, err := sqldb.Open(ctx, cfg.StorageBackend, dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &Repositories{
+			Auctions:   sqldb.NewAuctionRepository(database, clock),
+			Bids:       sqldb.NewBidRepository(database, clock),
+			Users:      sqldb.NewUserRepository(database),
+			Categories: sqldb.NewCategoryRepository(database),
+			Leases:     sqldb.NewLeaseRepository(database, clock),
+			Jobs:       sqldb.NewJobRepository(database),
+		}, func(context.Context) error { return database.Close() }, nil
+
+	default:
+		database, err := mongodb.NewMongoDBConnection(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		auctionRepository := auction.NewAuctionRepository(database, clock)
+		return &Repositories{
+			Auctions:   auctionRepository,
+			Bids:       bid.NewBidRepository(database, auctionRepository, clock),
+			Users:      user.NewUserRepository(database),
+			Categories: category.NewCategoryRepository(database),
+			Leases:     lease.NewLeaseRepository(database, clock),
+			Jobs:       job.NewJobRepository(database),
+			Mongo:      database,
+		}, database.Client().Disconnect, nil
+	}
+}
+
+func sqlDSN(cfg *config.Config) (string, error) {
+	if cfg.SQLDSN != "" {
+		return cfg.SQLDSN, nil
+	}
+
+	if err := os.MkdirAll("data", 0o755); err != nil {
+		return "", err
+	}
+	return defaultSQLitePath, nil
+}
@@ -2,17 +2,19 @@ package bid
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"fullcycle-auction_go/configuration/config"
 	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
 	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/entity/bid_entity"
 	"fullcycle-auction_go/internal/infra/database/auction"
 	"fullcycle-auction_go/internal/internal_error"
-	"os"
-	"sync"
-	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type BidEntityMongo struct {
@@ -24,87 +26,304 @@ type BidEntityMongo struct {
 }
 
 type BidRepository struct {
-	Collection            *mongo.Collection
-	AuctionRepository     *auction.AuctionRepository
-	auctionInterval       time.Duration
-	auctionStatusMap      map[string]auction_entity.AuctionStatus
-	auctionEndTimeMap     map[string]time.Time
-	auctionStatusMapMutex *sync.Mutex
-	auctionEndTimeMutex   *sync.Mutex
+	Collection        *mongo.Collection
+	AuctionRepository *auction.AuctionRepository
+	clock             clock.Clock
 }
 
-func NewBidRepository(database *mongo.Database, auctionRepository *auction.AuctionRepository) *BidRepository {
+func NewBidRepository(
+	database *mongo.Database, auctionRepository *auction.AuctionRepository, clock clock.Clock) *BidRepository {
 	return &BidRepository{
-		auctionInterval:       getAuctionInterval(),
-		auctionStatusMap:      make(map[string]auction_entity.AuctionStatus),
-		auctionEndTimeMap:     make(map[string]time.Time),
-		auctionStatusMapMutex: &sync.Mutex{},
-		auctionEndTimeMutex:   &sync.Mutex{},
-		Collection:            database.Collection("bids"),
-		AuctionRepository:     auctionRepository,
+		Collection:        database.Collection("bids"),
+		AuctionRepository: auctionRepository,
+		clock:             clock,
 	}
 }
 
-func (bd *BidRepository) CreateBid(ctx context.Context, bidEntities []bid_entity.Bid) *internal_error.InternalError {
-	for _, bid := range bidEntities {
-		bd.auctionStatusMapMutex.Lock()
-		auctionStatus, okStatus := bd.auctionStatusMap[bid.AuctionId]
-		bd.auctionStatusMapMutex.Unlock()
+// CreateBid accepts the bids of each auction through
+// AuctionRepository.AcceptBids before storing them, so acceptance and the
+// auction closure are ordered by the same document update instead of a
+// separate status read. The cached auction status only short-circuits bids on
+// completed or cancelled auctions, which never reopen: the cache of another
+// replica may still hold an auction that was since started or extended, so
+// any other state is left to AcceptBids. Accepted bids are then written with a
+// single unordered InsertMany. Bids are idempotent by id, so replaying a
+// journaled bid stores it once.
+func (bd *BidRepository) CreateBid(
+	ctx context.Context, bidEntities []bid_entity.Bid) ([]bid_entity.BidResult, *internal_error.InternalError) {
+	results := make([]bid_entity.BidResult, len(bidEntities))
+	for i, bid := range bidEntities {
+		results[i].BidId = bid.Id
+	}
+
+	storedIds, err := bd.findStoredBidIds(ctx, bidEntities)
+	if err != nil {
+		return nil, err
+	}
+
+	var accepted []int
+	for _, group := range groupBidsByAuction(bidEntities, storedIds, results) {
+		if bd.acceptAuctionBids(ctx, bidEntities, group, results) {
+			accepted = append(accepted, group...)
+		}
+	}
+
+	bd.insertBids(ctx, bidEntities, accepted, results)
+
+	// Bids accepted on the auction stats but not stored give their count back,
+	// and the highest bid is recomputed from the bids that were stored.
+	reverted := map[string]*revertedBids{}
+	for _, i := range accepted {
+		if results[i].Status == bid_entity.BidStored {
+			continue
+		}
+
+		bid := bidEntities[i]
+		stats, ok := reverted[bid.AuctionId]
+		if !ok {
+			stats = &revertedBids{}
+			reverted[bid.AuctionId] = stats
+		}
+		stats.count++
+		if bid.Amount > stats.highest {
+			stats.highest = bid.Amount
+		}
+	}
+	for auctionId, stats := range reverted {
+		storedHighest, err := bd.findHighestStoredAmount(ctx, auctionId)
+		if err != nil {
+			// Without it the highest bid is left as is, as before a revert.
+			storedHighest = stats.highest
+		}
+		bd.AuctionRepository.RevertAcceptedBids(ctx, auctionId, stats.count, stats.highest, storedHighest)
+	}
+
+	return results, nil
+}
+
+type revertedBids struct {
+	count   int
+	highest float64
+}
+
+// findHighestStoredAmount returns the amount of the highest bid stored for an
+// auction, or zero when it has none.
+func (bd *BidRepository) findHighestStoredAmount(
+	ctx context.Context, auctionId string) (float64, *internal_error.InternalError) {
+	var highest BidEntityMongo
+	err := bd.Collection.FindOne(ctx, bson.M{"auction_id": auctionId},
+		options.FindOne().SetSort(bson.D{{Key: "amount", Value: -1}}).SetProjection(bson.M{"amount": 1})).
+		Decode(&highest)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find highest bid of auction %s", auctionId), err)
+		return 0, internal_error.NewInternalServerError("Error trying to find highest bid")
+	}
+
+	return highest.Amount, nil
+}
+
+func (bd *BidRepository) findStoredBidIds(
+	ctx context.Context, bidEntities []bid_entity.Bid) (map[string]bool, *internal_error.InternalError) {
+	ids := make([]string, len(bidEntities))
+	for i, bid := range bidEntities {
+		ids[i] = bid.Id
+	}
+
+	cursor, err := bd.Collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		logger.Error("Error checking which bids are already stored", err)
+		return nil, internal_error.NewInternalServerError("Erro ao inserir bid no banco de dados")
+	}
+	defer cursor.Close(ctx)
+
+	var storedBids []BidEntityMongo
+	if err := cursor.All(ctx, &storedBids); err != nil {
+		logger.Error("Error decoding already stored bids", err)
+		return nil, internal_error.NewInternalServerError("Erro ao inserir bid no banco de dados")
+	}
+
+	storedIds := map[string]bool{}
+	for _, bid := range storedBids {
+		storedIds[bid.Id] = true
+	}
+
+	return storedIds, nil
+}
+
+// acceptAuctionBids records the bids at indexes, all of the same auction, on
+// its stats and reports whether they were accepted.
+func (bd *BidRepository) acceptAuctionBids(
+	ctx context.Context,
+	bidEntities []bid_entity.Bid,
+	indexes []int,
+	results []bid_entity.BidResult) bool {
+	auctionId := bidEntities[indexes[0]].AuctionId
+
+	auctionState, err := bd.AuctionRepository.FindAuctionState(ctx, auctionId)
+	if err != nil && err.Err == "not_found" {
+		setBidResults(results, indexes, bid_entity.BidRejected, err)
+		return false
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("Erro ao buscar leilão ID %s", auctionId), err)
+		setBidResults(results, indexes, bid_entity.BidFailed, err)
+		return false
+	}
+
+	if auctionState.Status == auction_entity.Completed || auctionState.Status == auction_entity.Cancelled {
+		logger.Info(fmt.Sprintf("Auction %s is %s, bids rejected", auctionId, auctionState.Status))
+		setBidResults(results, indexes, bid_entity.BidRejected, nil)
+		return false
+	}
+
+	highestAmount := 0.0
+	for _, i := range indexes {
+		if bidEntities[i].Amount > highestAmount {
+			highestAmount = bidEntities[i].Amount
+		}
+	}
+
+	accepted, err := bd.AuctionRepository.AcceptBids(ctx, auctionId, highestAmount, len(indexes))
+	if err != nil {
+		setBidResults(results, indexes, bid_entity.BidFailed, err)
+		return false
+	}
+	if !accepted {
+		logger.Info(fmt.Sprintf("Auction %s is not active, bids rejected", auctionId))
+		setBidResults(results, indexes, bid_entity.BidRejected, nil)
+		return false
+	}
+
+	return true
+}
 
-		bd.auctionEndTimeMutex.Lock()
-		auctionEndTime, okEndTime := bd.auctionEndTimeMap[bid.AuctionId]
-		bd.auctionEndTimeMutex.Unlock()
+// insertBids writes the bids at indexes with unordered InsertMany calls,
+// retrying the ones that failed transiently. A duplicate key on a retry means
+// an earlier attempt stored the bid before failing, so it counts as stored.
+func (bd *BidRepository) insertBids(
+	ctx context.Context,
+	bidEntities []bid_entity.Bid,
+	indexes []int,
+	results []bid_entity.BidResult) {
+	maxRetries := config.Current().BidInsertMaxRetries
+	backoff := config.Current().BidInsertRetryBackoff
 
-		if okEndTime && okStatus {
-			if auctionStatus == auction_entity.Completed || time.Now().After(auctionEndTime) {
-				logger.Info(fmt.Sprintf("Auction %s completed", bid.AuctionId))
-				continue
+	pending := indexes
+	for attempt := 0; len(pending) > 0; attempt++ {
+		documents := make([]interface{}, len(pending))
+		for position, i := range pending {
+			bid := bidEntities[i]
+			documents[position] = &BidEntityMongo{
+				Id:        bid.Id,
+				UserId:    bid.UserId,
+				AuctionId: bid.AuctionId,
+				Amount:    bid.Amount,
+				Timestamp: bid.Timestamp.Unix(),
 			}
-		} else {
-			auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, bid.AuctionId)
-			if err != nil {
-				logger.Error(fmt.Sprintf("Erro ao buscar leilão ID %s", bid.AuctionId), err)
-				return internal_error.NewInternalServerError("Erro ao buscar leilão")
+		}
+
+		_, err := bd.Collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+		if err == nil {
+			setBidResults(results, pending, bid_entity.BidStored, nil)
+			return
+		}
+
+		retryable := isTransientError(err)
+		failedWrites := map[int]error{}
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) {
+			for _, writeErr := range bulkErr.WriteErrors {
+				failedWrites[writeErr.Index] = writeErr.WriteError
 			}
-			if auctionEntity.Status == auction_entity.Completed {
-				logger.Info(fmt.Sprintf("Auction %s is completed, bid rejected", bid.AuctionId))
-				continue
+			// Without a write concern error, writes not reported as failed
+			// were acknowledged.
+			retryable = retryable || bulkErr.WriteConcernError != nil
+			if bulkErr.WriteConcernError == nil {
+				err = nil
 			}
+		}
 
-			bd.auctionStatusMapMutex.Lock()
-			bd.auctionStatusMap[bid.AuctionId] = auctionEntity.Status
-			bd.auctionStatusMapMutex.Unlock()
+		var retry []int
+		for position, i := range pending {
+			writeErr, failed := failedWrites[position]
+			if !failed {
+				writeErr = err
+			}
 
-			bd.auctionEndTimeMutex.Lock()
-			bd.auctionEndTimeMap[bid.AuctionId] = auctionEntity.Timestamp
-			bd.auctionEndTimeMutex.Unlock()
+			switch {
+			case writeErr == nil:
+				results[i].Status = bid_entity.BidStored
+			case mongo.IsDuplicateKeyError(writeErr) && attempt > 0:
+				results[i].Status = bid_entity.BidStored
+			case mongo.IsDuplicateKeyError(writeErr):
+				logger.Info(fmt.Sprintf("Bid %s already stored, skipping", bidEntities[i].Id))
+				results[i].Status = bid_entity.BidDuplicate
+			case retryable && attempt < maxRetries:
+				retry = append(retry, i)
+			default:
+				logger.Error(fmt.Sprintf("Error inserting bid %s", bidEntities[i].Id), writeErr)
+				results[i].Status = bid_entity.BidFailed
+				results[i].Err = internal_error.NewInternalServerError("Erro ao inserir bid no banco de dados")
+			}
 		}
 
-		bidEntityMongo := &BidEntityMongo{
-			Id:        bid.Id,
-			UserId:    bid.UserId,
-			AuctionId: bid.AuctionId,
-			Amount:    bid.Amount,
-			Timestamp: bid.Timestamp.Unix(),
+		pending = retry
+		if len(pending) == 0 {
+			return
 		}
 
-		_, err := bd.Collection.InsertOne(ctx, bidEntityMongo)
-		if err != nil {
-			logger.Error("Error inserting bid entity", err)
-			return internal_error.NewInternalServerError("Erro ao inserir bid no banco de dados")
+		logger.Info(fmt.Sprintf("Retrying %d bids after a transient error", len(pending)))
+		timer := bd.clock.NewTimer(backoff << attempt)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			setBidResults(results, pending, bid_entity.BidFailed,
+				internal_error.NewInternalServerError("Erro ao inserir bid no banco de dados"))
+			return
+		case <-timer.C():
 		}
+	}
+}
+
+func isTransientError(err error) bool {
+	var labeled mongo.LabeledError
+	return mongo.IsNetworkError(err) || mongo.IsTimeout(err) ||
+		(errors.As(err, &labeled) && labeled.HasErrorLabel("RetryableWriteError"))
+}
 
-		logger.Info("✅ Bid inserido com sucesso no MongoDB")
+// groupBidsByAuction returns the indexes of the bids of each auction, in the
+// order the auctions first appear, marking bids already stored as duplicates.
+func groupBidsByAuction(
+	bidEntities []bid_entity.Bid, storedIds map[string]bool, results []bid_entity.BidResult) [][]int {
+	var groups [][]int
+	groupOf := map[string]int{}
+	for i, bid := range bidEntities {
+		if storedIds[bid.Id] {
+			logger.Info(fmt.Sprintf("Bid %s already stored, skipping", bid.Id))
+			results[i].Status = bid_entity.BidDuplicate
+			continue
+		}
+
+		group, ok := groupOf[bid.AuctionId]
+		if !ok {
+			group = len(groups)
+			groupOf[bid.AuctionId] = group
+			groups = append(groups, nil)
+		}
+		groups[group] = append(groups[group], i)
 	}
 
-	return nil
+	return groups
 }
 
-func getAuctionInterval() time.Duration {
-	auctionInterval := os.Getenv("AUCTION_INTERVAL")
-	duration, err := time.ParseDuration(auctionInterval)
-	if err != nil {
-		return time.Minute * 5 // Valor padrão caso a conversão falhe
+func setBidResults(
+	results []bid_entity.BidResult,
+	indexes []int,
+	status bid_entity.BidResultStatus,
+	err *internal_error.InternalError) {
+	for _, i := range indexes {
+		results[i].Status, results[i].Err = status, err
 	}
-	return duration
 }
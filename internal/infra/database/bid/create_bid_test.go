@@ -0,0 +1,162 @@
+package bid
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/infra/database/auction"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const benchmarkBatchSize = 100
+
+// BenchmarkCreateBid compares storing a batch with CreateBid against the
+// previous loop that checked, accepted and inserted one bid at a time.
+func BenchmarkCreateBid(b *testing.B) {
+	mongoURL := os.Getenv("MONGODB_URL")
+	if mongoURL == "" {
+		b.Skip("MONGODB_URL is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURL))
+	if err != nil {
+		b.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database(fmt.Sprintf("auctions_benchmark_%d", time.Now().UnixNano()))
+	defer database.Drop(ctx)
+
+	auctionRepository := auction.NewAuctionRepository(database, clock.Real)
+	bidRepository := NewBidRepository(database, auctionRepository, clock.Real)
+
+	auctionIds := make([]string, 10)
+	for i := range auctionIds {
+		auctionEntity, err := auction_entity.CreateAuction(
+			fmt.Sprintf("Benchmark %d", i), uuid.New().String(), "A description long enough", auction_entity.New, time.Now())
+		if err != nil {
+			b.Fatalf("CreateAuction entity: %v", err)
+		}
+		if err := auctionRepository.CreateAuction(ctx, auctionEntity); err != nil {
+			b.Fatalf("CreateAuction: %v", err)
+		}
+		auctionIds[i] = auctionEntity.Id
+	}
+
+	newBatch := func() []bid_entity.Bid {
+		bids := make([]bid_entity.Bid, benchmarkBatchSize)
+		for i := range bids {
+			bids[i] = bid_entity.Bid{
+				Id:        uuid.New().String(),
+				UserId:    uuid.New().String(),
+				AuctionId: auctionIds[i%len(auctionIds)],
+				Amount:    float64(i + 1),
+				Timestamp: time.Now(),
+			}
+		}
+		return bids
+	}
+
+	b.Run("InsertMany", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			bids := newBatch()
+			if _, err := bidRepository.CreateBid(ctx, bids); err != nil {
+				b.Fatalf("CreateBid: %v", err)
+			}
+		}
+		b.ReportMetric(float64(b.N*benchmarkBatchSize)/b.Elapsed().Seconds(), "bids/s")
+	})
+
+	b.Run("InsertOneLoop", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for _, bid := range newBatch() {
+				if _, err := bidRepository.Collection.CountDocuments(
+					ctx, bson.M{"_id": bid.Id}, options.Count().SetLimit(1)); err != nil {
+					b.Fatalf("CountDocuments: %v", err)
+				}
+
+				if _, err := auctionRepository.AcceptBids(ctx, bid.AuctionId, bid.Amount, 1); err != nil {
+					b.Fatalf("AcceptBids: %v", err)
+				}
+
+				_, err := bidRepository.Collection.InsertOne(ctx, &BidEntityMongo{
+					Id:        bid.Id,
+					UserId:    bid.UserId,
+					AuctionId: bid.AuctionId,
+					Amount:    bid.Amount,
+					Timestamp: bid.Timestamp.Unix(),
+				})
+				if err != nil {
+					b.Fatalf("InsertOne: %v", err)
+				}
+			}
+		}
+		b.ReportMetric(float64(b.N*benchmarkBatchSize)/b.Elapsed().Seconds(), "bids/s")
+	})
+}
+
+// TestCreateBidIgnoresStaleCachedState starts an auction through a second
+// repository, as another replica would, after the first one cached it as
+// scheduled: the stale cache must not reject the bid.
+func TestCreateBidIgnoresStaleCachedState(t *testing.T) {
+	mongoURL := os.Getenv("MONGODB_URL")
+	if mongoURL == "" {
+		t.Skip("MONGODB_URL is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURL))
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database(fmt.Sprintf("auctions_test_%d", time.Now().UnixNano()))
+	defer database.Drop(ctx)
+
+	replica := auction.NewAuctionRepository(database, clock.Real)
+	otherReplica := auction.NewAuctionRepository(database, clock.Real)
+	bidRepository := NewBidRepository(database, replica, clock.Real)
+
+	auctionEntity, createErr := auction_entity.CreateAuction(
+		"Pocket Watch", uuid.New().String(), "A description long enough", auction_entity.New, time.Now())
+	if createErr != nil {
+		t.Fatalf("CreateAuction entity: %v", createErr)
+	}
+	auctionEntity.Status = auction_entity.Scheduled
+	auctionEntity.EndTime = time.Now().Add(time.Hour)
+	if err := otherReplica.CreateAuction(ctx, auctionEntity); err != nil {
+		t.Fatalf("CreateAuction: %v", err)
+	}
+
+	if state, err := replica.FindAuctionState(ctx, auctionEntity.Id); err != nil || state.Status != auction_entity.Scheduled {
+		t.Fatalf("expected the auction to be cached as scheduled, got %+v %v", state, err)
+	}
+	if started, err := otherReplica.StartAuction(ctx, auctionEntity.Id); err != nil || !started {
+		t.Fatalf("StartAuction: %v %v", started, err)
+	}
+
+	results, bidErr := bidRepository.CreateBid(ctx, []bid_entity.Bid{{
+		Id:        uuid.New().String(),
+		UserId:    uuid.New().String(),
+		AuctionId: auctionEntity.Id,
+		Amount:    100,
+		Timestamp: time.Now(),
+	}})
+	if bidErr != nil {
+		t.Fatalf("CreateBid: %v", bidErr)
+	}
+	if results[0].Status != bid_entity.BidStored {
+		t.Errorf("expected the bid to be stored, got %+v", results[0])
+	}
+}
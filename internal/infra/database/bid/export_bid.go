@@ -0,0 +1,74 @@
+package bid
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/infra/database/auction"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ExportBids walks the matching auctions and joins their bids. The $lookup
+// is directly unwound, which the server runs without building the array of
+// bids of each auction, so auctions with many bids stay under the document
+// size limit.
+func (bd *BidRepository) ExportBids(
+	ctx context.Context,
+	exportFilter auction_entity.AuctionExportFilter,
+	fn func(bid *bid_entity.Bid) *internal_error.InternalError) *internal_error.InternalError {
+	logger.Info(fmt.Sprintf("Exporting bids with filter = %+v", exportFilter))
+
+	pipeline := []bson.M{
+		{"$match": auction.ExportFilterToMongo(exportFilter)},
+		{"$sort": auction.ExportSort},
+		{"$project": bson.M{"_id": 1}},
+		{"$lookup": bson.M{
+			"from": bd.Collection.Name(),
+			"let":  bson.M{"auctionId": "$_id"},
+			"pipeline": []bson.M{
+				{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$auction_id", "$$auctionId"}}}},
+				{"$sort": bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}}},
+			},
+			"as": "bid",
+		}},
+		{"$unwind": "$bid"},
+		{"$replaceRoot": bson.M{"newRoot": "$bid"}},
+	}
+
+	cursor, err := bd.AuctionRepository.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error("Error exporting bids", err)
+		return internal_error.NewInternalServerError("Error exporting bids")
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var bidEntityMongo BidEntityMongo
+		if err := cursor.Decode(&bidEntityMongo); err != nil {
+			logger.Error("Error decoding exported bid", err)
+			return internal_error.NewInternalServerError("Error exporting bids")
+		}
+
+		if err := fn(&bid_entity.Bid{
+			Id:        bidEntityMongo.Id,
+			UserId:    bidEntityMongo.UserId,
+			AuctionId: bidEntityMongo.AuctionId,
+			Amount:    bidEntityMongo.Amount,
+			Timestamp: time.Unix(bidEntityMongo.Timestamp, 0),
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		logger.Error("Error exporting bids", err)
+		return internal_error.NewInternalServerError("Error exporting bids")
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package bid
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/infra/database/auction"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type bidPageMongo struct {
+	Bids  []BidEntityMongo `bson:"bids"`
+	Total []struct {
+		Count int64 `bson:"count"`
+	} `bson:"total"`
+}
+
+func (bd *BidRepository) FindBidsByUserId(
+	ctx context.Context,
+	userId string,
+	auctionStatus *auction_entity.AuctionStatus,
+	page, limit int64) ([]bid_entity.Bid, int64, *internal_error.InternalError) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"user_id": userId}},
+	}
+
+	if auctionStatus != nil {
+		pipeline = append(pipeline,
+			bson.M{"$lookup": bson.M{
+				"from":         bd.AuctionRepository.Collection.Name(),
+				"localField":   "auction_id",
+				"foreignField": "_id",
+				"as":           "auction",
+			}},
+			bson.M{"$match": bson.M{"auction.status": auction.StatusToMongo(*auctionStatus)}},
+			bson.M{"$project": bson.M{"auction": 0}},
+		)
+	}
+
+	pipeline = append(pipeline, bson.M{"$sort": bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: 1}}})
+
+	return bd.aggregateBidPage(ctx, pipeline, page, limit,
+		fmt.Sprintf("Error trying to find bids by userId %s", userId))
+}
+
+func (bd *BidRepository) FindWinningBidsByUserId(
+	ctx context.Context,
+	userId string,
+	page, limit int64) ([]bid_entity.Bid, int64, *internal_error.InternalError) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"user_id": userId}},
+		{"$group": bson.M{"_id": "$auction_id"}},
+		{"$lookup": bson.M{
+			"from":         bd.AuctionRepository.Collection.Name(),
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "auction",
+		}},
+		{"$match": bson.M{"auction.status": auction.StatusToMongo(auction_entity.Completed)}},
+		{"$lookup": bson.M{
+			"from": bd.Collection.Name(),
+			"let":  bson.M{"auctionId": "$_id"},
+			"pipeline": []bson.M{
+				{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$auction_id", "$$auctionId"}}}},
+				{"$sort": winningBidSort},
+				{"$limit": 1},
+			},
+			"as": "winning",
+		}},
+		{"$unwind": "$winning"},
+		{"$match": bson.M{"winning.user_id": userId}},
+		{"$replaceRoot": bson.M{"newRoot": "$winning"}},
+		{"$sort": bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+
+	return bd.aggregateBidPage(ctx, pipeline, page, limit,
+		fmt.Sprintf("Error trying to find auctions won by userId %s", userId))
+}
+
+func (bd *BidRepository) aggregateBidPage(
+	ctx context.Context,
+	pipeline []bson.M,
+	page, limit int64,
+	errorMessage string) ([]bid_entity.Bid, int64, *internal_error.InternalError) {
+	pipeline = append(pipeline, bson.M{"$facet": bson.M{
+		"bids":  []bson.M{{"$skip": (page - 1) * limit}, {"$limit": limit}},
+		"total": []bson.M{{"$count": "count"}},
+	}})
+
+	cursor, err := bd.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error(errorMessage, err)
+		return nil, 0, internal_error.NewInternalServerError(errorMessage)
+	}
+	defer cursor.Close(ctx)
+
+	var pages []bidPageMongo
+	if err := cursor.All(ctx, &pages); err != nil {
+		logger.Error(errorMessage, err)
+		return nil, 0, internal_error.NewInternalServerError(errorMessage)
+	}
+
+	if len(pages) == 0 {
+		return []bid_entity.Bid{}, 0, nil
+	}
+
+	var total int64
+	if len(pages[0].Total) > 0 {
+		total = pages[0].Total[0].Count
+	}
+
+	bidEntities := make([]bid_entity.Bid, 0, len(pages[0].Bids))
+	for _, bidEntityMongo := range pages[0].Bids {
+		bidEntities = append(bidEntities, bid_entity.Bid{
+			Id:        bidEntityMongo.Id,
+			UserId:    bidEntityMongo.UserId,
+			AuctionId: bidEntityMongo.AuctionId,
+			Amount:    bidEntityMongo.Amount,
+			Timestamp: time.Unix(bidEntityMongo.Timestamp, 0),
+		})
+	}
+
+	return bidEntities, total, nil
+}
@@ -0,0 +1,129 @@
+package category
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CategoryEntityMongo struct {
+	Id         string                     `bson:"_id"`
+	Name       string                     `bson:"name"`
+	Slug       string                     `bson:"slug"`
+	ParentId   string                     `bson:"parent_id,omitempty"`
+	Attributes []AttributeDefinitionMongo `bson:"attributes,omitempty"`
+	Timestamp  int64                      `bson:"timestamp"`
+}
+
+type AttributeDefinitionMongo struct {
+	Key      string   `bson:"key"`
+	Type     string   `bson:"type"`
+	Required bool     `bson:"required"`
+	Options  []string `bson:"options,omitempty"`
+}
+
+type CategoryRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewCategoryRepository(database *mongo.Database) *CategoryRepository {
+	return &CategoryRepository{
+		Collection: database.Collection("categories"),
+	}
+}
+
+func (cr *CategoryRepository) CreateCategory(
+	ctx context.Context, category *category_entity.Category) *internal_error.InternalError {
+	_, err := cr.Collection.InsertOne(ctx, toCategoryEntityMongo(category))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			logger.Info(fmt.Sprintf("Category already exists with slug: %s", category.Slug))
+			return internal_error.NewConflictError("Category already exists with this name")
+		}
+
+		logger.Error(fmt.Sprintf("Error creating category with id: %s", category.Id), err)
+		return internal_error.NewInternalServerError("Error trying to create category")
+	}
+
+	return nil
+}
+
+func (cr *CategoryRepository) UpdateCategory(
+	ctx context.Context, category *category_entity.Category) *internal_error.InternalError {
+	update := bson.M{
+		"$set": bson.M{
+			"name":       category.Name,
+			"slug":       category.Slug,
+			"attributes": toAttributeDefinitionsMongo(category.Attributes),
+		},
+	}
+
+	if category.ParentId == "" {
+		update["$unset"] = bson.M{"parent_id": ""}
+	} else {
+		update["$set"].(bson.M)["parent_id"] = category.ParentId
+	}
+
+	result, err := cr.Collection.UpdateByID(ctx, category.Id, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			logger.Info(fmt.Sprintf("Category already exists with slug: %s", category.Slug))
+			return internal_error.NewConflictError("Category already exists with this name")
+		}
+
+		logger.Error(fmt.Sprintf("Error updating category with id: %s", category.Id), err)
+		return internal_error.NewInternalServerError("Error trying to update category")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError("Category not found")
+	}
+
+	return nil
+}
+
+func (cr *CategoryRepository) DeleteCategory(
+	ctx context.Context, id string) *internal_error.InternalError {
+	result, err := cr.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error deleting category with id: %s", id), err)
+		return internal_error.NewInternalServerError("Error trying to delete category")
+	}
+
+	if result.DeletedCount == 0 {
+		return internal_error.NewNotFoundError("Category not found")
+	}
+
+	return nil
+}
+
+func toCategoryEntityMongo(category *category_entity.Category) CategoryEntityMongo {
+	return CategoryEntityMongo{
+		Id:         category.Id,
+		Name:       category.Name,
+		Slug:       category.Slug,
+		ParentId:   category.ParentId,
+		Attributes: toAttributeDefinitionsMongo(category.Attributes),
+		Timestamp:  category.Timestamp.Unix(),
+	}
+}
+
+func toAttributeDefinitionsMongo(
+	definitions []category_entity.AttributeDefinition) []AttributeDefinitionMongo {
+	definitionsMongo := make([]AttributeDefinitionMongo, 0, len(definitions))
+	for _, definition := range definitions {
+		definitionsMongo = append(definitionsMongo, AttributeDefinitionMongo{
+			Key:      definition.Key,
+			Type:     string(definition.Type),
+			Required: definition.Required,
+			Options:  definition.Options,
+		})
+	}
+
+	return definitionsMongo
+}
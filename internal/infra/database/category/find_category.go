@@ -0,0 +1,86 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (cr *CategoryRepository) FindCategoryById(
+	ctx context.Context, id string) (*category_entity.Category, *internal_error.InternalError) {
+	return cr.findOneCategory(ctx, bson.M{"_id": id})
+}
+
+func (cr *CategoryRepository) FindCategoryBySlug(
+	ctx context.Context, slug string) (*category_entity.Category, *internal_error.InternalError) {
+	return cr.findOneCategory(ctx, bson.M{"slug": slug})
+}
+
+func (cr *CategoryRepository) FindCategories(
+	ctx context.Context) ([]category_entity.Category, *internal_error.InternalError) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := cr.Collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.Error("Error trying to find categories", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find categories")
+	}
+	defer cursor.Close(ctx)
+
+	var categoriesMongo []CategoryEntityMongo
+	if err := cursor.All(ctx, &categoriesMongo); err != nil {
+		logger.Error("Error trying to decode categories", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find categories")
+	}
+
+	categories := make([]category_entity.Category, 0, len(categoriesMongo))
+	for _, categoryMongo := range categoriesMongo {
+		categories = append(categories, *toCategoryEntity(categoryMongo))
+	}
+
+	return categories, nil
+}
+
+func (cr *CategoryRepository) findOneCategory(
+	ctx context.Context, filter bson.M) (*category_entity.Category, *internal_error.InternalError) {
+	var categoryMongo CategoryEntityMongo
+	if err := cr.Collection.FindOne(ctx, filter).Decode(&categoryMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError("Category not found")
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find category with filter %v", filter), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find category")
+	}
+
+	return toCategoryEntity(categoryMongo), nil
+}
+
+func toCategoryEntity(categoryMongo CategoryEntityMongo) *category_entity.Category {
+	attributes := make([]category_entity.AttributeDefinition, 0, len(categoryMongo.Attributes))
+	for _, definition := range categoryMongo.Attributes {
+		attributes = append(attributes, category_entity.AttributeDefinition{
+			Key:      definition.Key,
+			Type:     category_entity.AttributeType(definition.Type),
+			Required: definition.Required,
+			Options:  definition.Options,
+		})
+	}
+
+	return &category_entity.Category{
+		Id:         categoryMongo.Id,
+		Name:       categoryMongo.Name,
+		Slug:       categoryMongo.Slug,
+		ParentId:   categoryMongo.ParentId,
+		Attributes: attributes,
+		Timestamp:  time.Unix(categoryMongo.Timestamp, 0),
+	}
+}
@@ -0,0 +1,309 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/job_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type JobEntityMongo struct {
+	Id             string `bson:"_id"`
+	Type           string `bson:"type"`
+	Payload        []byte `bson:"payload,omitempty"`
+	Status         string `bson:"status"`
+	RunAt          int64  `bson:"run_at"`
+	Cron           string `bson:"cron,omitempty"`
+	IdempotencyKey string `bson:"idempotency_key,omitempty"`
+	Attempts       int    `bson:"attempts"`
+	MaxAttempts    int    `bson:"max_attempts"`
+	LastError      string `bson:"last_error,omitempty"`
+	ClaimedBy      string `bson:"claimed_by,omitempty"`
+	Token          int64  `bson:"token"`
+	LeaseExpiresAt int64  `bson:"lease_expires_at"`
+	CreatedAt      int64  `bson:"created_at"`
+	UpdatedAt      int64  `bson:"updated_at"`
+}
+
+var (
+	jobStatusToMongo = map[job_entity.JobStatus]string{
+		job_entity.Pending:   "pending",
+		job_entity.Running:   "running",
+		job_entity.Succeeded: "succeeded",
+		job_entity.Failed:    "failed",
+		job_entity.Cancelled: "cancelled",
+	}
+	jobStatusFromMongo = map[string]job_entity.JobStatus{
+		"pending":   job_entity.Pending,
+		"running":   job_entity.Running,
+		"succeeded": job_entity.Succeeded,
+		"failed":    job_entity.Failed,
+		"cancelled": job_entity.Cancelled,
+	}
+)
+
+type JobRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewJobRepository(database *mongo.Database) *JobRepository {
+	return &JobRepository{
+		Collection: database.Collection("jobs"),
+	}
+}
+
+func (jr *JobRepository) CreateJob(
+	ctx context.Context, job *job_entity.Job) (*job_entity.Job, bool, *internal_error.InternalError) {
+	jobMongo := toJobEntityMongo(job)
+	if _, err := jr.Collection.InsertOne(ctx, jobMongo); err == nil {
+		return toJobEntity(jobMongo), true, nil
+	} else if !mongo.IsDuplicateKeyError(err) || job.IdempotencyKey == "" {
+		logger.Error(fmt.Sprintf("Error trying to insert job %s", job.Type), err)
+		return nil, false, internal_error.NewInternalServerError("Error trying to insert job")
+	}
+
+	var existing JobEntityMongo
+	if err := jr.Collection.FindOne(ctx, bson.M{"idempotency_key": job.IdempotencyKey}).Decode(&existing); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find job with idempotency key %s", job.IdempotencyKey), err)
+		return nil, false, internal_error.NewInternalServerError("Error trying to insert job")
+	}
+
+	return toJobEntity(existing), false, nil
+}
+
+func (jr *JobRepository) ClaimJob(
+	ctx context.Context,
+	workerId string,
+	now time.Time,
+	leaseTTL time.Duration) (*job_entity.Job, *internal_error.InternalError) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": jobStatusToMongo[job_entity.Pending], "run_at": bson.M{"$lte": now.UnixMilli()}},
+		bson.M{"status": jobStatusToMongo[job_entity.Running], "lease_expires_at": bson.M{"$lte": now.UnixMilli()}},
+	}}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":           jobStatusToMongo[job_entity.Running],
+			"claimed_by":       workerId,
+			"lease_expires_at": now.Add(leaseTTL).UnixMilli(),
+			"updated_at":       now.UnixMilli(),
+		},
+		"$inc": bson.M{"token": 1, "attempts": 1},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "run_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var jobMongo JobEntityMongo
+	if err := jr.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&jobMongo); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		logger.Error("Error trying to claim job", err)
+		return nil, internal_error.NewInternalServerError("Error trying to claim job")
+	}
+
+	return toJobEntity(jobMongo), nil
+}
+
+func (jr *JobRepository) FinishJob(
+	ctx context.Context, job *job_entity.Job) (bool, *internal_error.InternalError) {
+	filter := bson.M{
+		"_id":        job.Id,
+		"status":     jobStatusToMongo[job_entity.Running],
+		"claimed_by": job.ClaimedBy,
+		"token":      job.Token,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":           jobStatusToMongo[job.Status],
+			"run_at":           job.RunAt.UnixMilli(),
+			"attempts":         job.Attempts,
+			"last_error":       job.LastError,
+			"lease_expires_at": int64(0),
+			"updated_at":       job.UpdatedAt.UnixMilli(),
+		},
+		"$unset": bson.M{"claimed_by": ""},
+	}
+
+	result, err := jr.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to finish job %s", job.Id), err)
+		return false, internal_error.NewInternalServerError("Error trying to finish job")
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (jr *JobRepository) FindJobById(
+	ctx context.Context, id string) (*job_entity.Job, *internal_error.InternalError) {
+	var jobMongo JobEntityMongo
+	if err := jr.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&jobMongo); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, internal_error.NewNotFoundError("Job not found")
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find job %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find job")
+	}
+
+	return toJobEntity(jobMongo), nil
+}
+
+func (jr *JobRepository) FindJobs(
+	ctx context.Context, jobFilter job_entity.JobFilter) ([]job_entity.Job, int64, *internal_error.InternalError) {
+	filter := bson.M{}
+
+	if jobFilter.Status != nil {
+		filter["status"] = jobStatusToMongo[*jobFilter.Status]
+	}
+
+	if jobFilter.Type != "" {
+		filter["type"] = jobFilter.Type
+	}
+
+	total, err := jr.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error("Error trying to count jobs", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find jobs")
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "run_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip((jobFilter.Page - 1) * jobFilter.Limit).
+		SetLimit(jobFilter.Limit)
+
+	cursor, err := jr.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Error trying to find jobs", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find jobs")
+	}
+	defer cursor.Close(ctx)
+
+	var jobsMongo []JobEntityMongo
+	if err := cursor.All(ctx, &jobsMongo); err != nil {
+		logger.Error("Error trying to decode jobs", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find jobs")
+	}
+
+	jobs := make([]job_entity.Job, 0, len(jobsMongo))
+	for _, jobMongo := range jobsMongo {
+		jobs = append(jobs, *toJobEntity(jobMongo))
+	}
+
+	return jobs, total, nil
+}
+
+func (jr *JobRepository) RetryJob(
+	ctx context.Context, id string, runAt time.Time) (*job_entity.Job, *internal_error.InternalError) {
+	return jr.transition(ctx, id,
+		[]job_entity.JobStatus{job_entity.Failed, job_entity.Cancelled},
+		bson.M{
+			"status":   jobStatusToMongo[job_entity.Pending],
+			"run_at":   runAt.UnixMilli(),
+			"attempts": 0,
+		},
+		"Only failed or cancelled jobs can be retried")
+}
+
+func (jr *JobRepository) CancelJob(
+	ctx context.Context, id string) (*job_entity.Job, *internal_error.InternalError) {
+	return jr.transition(ctx, id,
+		[]job_entity.JobStatus{job_entity.Pending, job_entity.Failed},
+		bson.M{"status": jobStatusToMongo[job_entity.Cancelled]},
+		"Only pending or failed jobs can be cancelled")
+}
+
+// transition applies set to a job currently in one of from, telling a
+// missing job apart from one in another status.
+func (jr *JobRepository) transition(
+	ctx context.Context,
+	id string,
+	from []job_entity.JobStatus,
+	set bson.M,
+	invalidMessage string) (*job_entity.Job, *internal_error.InternalError) {
+	statuses := make(bson.A, 0, len(from))
+	for _, status := range from {
+		statuses = append(statuses, jobStatusToMongo[status])
+	}
+
+	var jobMongo JobEntityMongo
+	err := jr.Collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": bson.M{"$in": statuses}},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&jobMongo)
+	if err == mongo.ErrNoDocuments {
+		if _, err := jr.FindJobById(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, internal_error.NewBadRequestError(invalidMessage)
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to update job %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to update job")
+	}
+
+	return toJobEntity(jobMongo), nil
+}
+
+func toJobEntityMongo(job *job_entity.Job) JobEntityMongo {
+	return JobEntityMongo{
+		Id:             job.Id,
+		Type:           job.Type,
+		Payload:        job.Payload,
+		Status:         jobStatusToMongo[job.Status],
+		RunAt:          job.RunAt.UnixMilli(),
+		Cron:           job.Cron,
+		IdempotencyKey: job.IdempotencyKey,
+		Attempts:       job.Attempts,
+		MaxAttempts:    job.MaxAttempts,
+		LastError:      job.LastError,
+		ClaimedBy:      job.ClaimedBy,
+		Token:          job.Token,
+		LeaseExpiresAt: toMilli(job.LeaseExpiresAt),
+		CreatedAt:      job.CreatedAt.UnixMilli(),
+		UpdatedAt:      job.UpdatedAt.UnixMilli(),
+	}
+}
+
+func toJobEntity(jobMongo JobEntityMongo) *job_entity.Job {
+	return &job_entity.Job{
+		Id:             jobMongo.Id,
+		Type:           jobMongo.Type,
+		Payload:        jobMongo.Payload,
+		Status:         jobStatusFromMongo[jobMongo.Status],
+		RunAt:          time.UnixMilli(jobMongo.RunAt),
+		Cron:           jobMongo.Cron,
+		IdempotencyKey: jobMongo.IdempotencyKey,
+		Attempts:       jobMongo.Attempts,
+		MaxAttempts:    jobMongo.MaxAttempts,
+		LastError:      jobMongo.LastError,
+		ClaimedBy:      jobMongo.ClaimedBy,
+		Token:          jobMongo.Token,
+		LeaseExpiresAt: fromMilli(jobMongo.LeaseExpiresAt),
+		CreatedAt:      time.UnixMilli(jobMongo.CreatedAt),
+		UpdatedAt:      time.UnixMilli(jobMongo.UpdatedAt),
+	}
+}
+
+func toMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func fromMilli(milli int64) time.Time {
+	if milli == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(milli)
+}
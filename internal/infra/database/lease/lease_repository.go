@@ -0,0 +1,128 @@
+package lease
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LeaseEntityMongo struct {
+	Name      string `bson:"_id"`
+	HolderId  string `bson:"holder_id"`
+	Token     int64  `bson:"token"`
+	ExpiresAt int64  `bson:"expires_at"`
+}
+
+type LeaseRepository struct {
+	Collection *mongo.Collection
+	clock      clock.Clock
+}
+
+func NewLeaseRepository(database *mongo.Database, clock clock.Clock) *LeaseRepository {
+	return &LeaseRepository{
+		Collection: database.Collection("leases"),
+		clock:      clock,
+	}
+}
+
+// AcquireLease relies on single-document atomicity: renewal and takeover are
+// conditional updates, and the first acquisition is an insert that fails on
+// the duplicate _id when another instance got there first. Expiry times are
+// compared against each instance's clock, so the TTL must comfortably exceed
+// the clock skew between replicas.
+func (lr *LeaseRepository) AcquireLease(
+	ctx context.Context,
+	name, holderId string,
+	ttl time.Duration) (*lease_entity.Lease, bool, *internal_error.InternalError) {
+	now := lr.clock.Now()
+	expiresAt := now.Add(ttl).UnixMilli()
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	attempts := []struct {
+		filter bson.M
+		update bson.M
+	}{
+		{
+			filter: bson.M{"_id": name, "holder_id": holderId, "expires_at": bson.M{"$gt": now.UnixMilli()}},
+			update: bson.M{"$set": bson.M{"expires_at": expiresAt}},
+		},
+		{
+			filter: bson.M{"_id": name, "expires_at": bson.M{"$lte": now.UnixMilli()}},
+			update: bson.M{
+				"$set": bson.M{"holder_id": holderId, "expires_at": expiresAt},
+				"$inc": bson.M{"token": 1},
+			},
+		},
+	}
+
+	for _, attempt := range attempts {
+		var leaseMongo LeaseEntityMongo
+		err := lr.Collection.FindOneAndUpdate(ctx, attempt.filter, attempt.update, opts).Decode(&leaseMongo)
+		if err == nil {
+			return toLeaseEntity(leaseMongo), true, nil
+		} else if err != mongo.ErrNoDocuments {
+			logger.Error(fmt.Sprintf("Error trying to acquire lease %s", name), err)
+			return nil, false, internal_error.NewInternalServerError("Error trying to acquire lease")
+		}
+	}
+
+	leaseMongo := LeaseEntityMongo{Name: name, HolderId: holderId, Token: 1, ExpiresAt: expiresAt}
+	if _, err := lr.Collection.InsertOne(ctx, leaseMongo); err == nil {
+		return toLeaseEntity(leaseMongo), true, nil
+	} else if !mongo.IsDuplicateKeyError(err) {
+		logger.Error(fmt.Sprintf("Error trying to create lease %s", name), err)
+		return nil, false, internal_error.NewInternalServerError("Error trying to acquire lease")
+	}
+
+	lease, err := lr.FindLease(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return lease, false, nil
+}
+
+func (lr *LeaseRepository) ReleaseLease(
+	ctx context.Context, name, holderId string, token int64) *internal_error.InternalError {
+	_, err := lr.Collection.UpdateOne(ctx,
+		bson.M{"_id": name, "holder_id": holderId, "token": token},
+		bson.M{"$set": bson.M{"expires_at": int64(0)}})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to release lease %s", name), err)
+		return internal_error.NewInternalServerError("Error trying to release lease")
+	}
+
+	return nil
+}
+
+func (lr *LeaseRepository) FindLease(
+	ctx context.Context, name string) (*lease_entity.Lease, *internal_error.InternalError) {
+	var leaseMongo LeaseEntityMongo
+	if err := lr.Collection.FindOne(ctx, bson.M{"_id": name}).Decode(&leaseMongo); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, internal_error.NewNotFoundError(fmt.Sprintf("Lease %s not found", name))
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find lease %s", name), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find lease")
+	}
+
+	return toLeaseEntity(leaseMongo), nil
+}
+
+func toLeaseEntity(leaseMongo LeaseEntityMongo) *lease_entity.Lease {
+	return &lease_entity.Lease{
+		Name:      leaseMongo.Name,
+		HolderId:  leaseMongo.HolderId,
+		Token:     leaseMongo.Token,
+		ExpiresAt: time.UnixMilli(leaseMongo.ExpiresAt),
+	}
+}
@@ -0,0 +1,665 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Text search weights mirror the auction_text_search index of the Mongo backend.
+const (
+	productNameWeight = 10
+	categoryWeight    = 5
+	descriptionWeight = 1
+)
+
+type AuctionRepository struct {
+	clock    clock.Clock
+	mutex    sync.RWMutex
+	auctions map[string]*auction_entity.Auction
+	leases   *LeaseRepository
+}
+
+func NewAuctionRepository(clock clock.Clock) *AuctionRepository {
+	return &AuctionRepository{
+		clock:    clock,
+		auctions: map[string]*auction_entity.Auction{},
+	}
+}
+
+// CloseExpiredAuctions completes every active auction whose end time has
+// passed.
+func (ar *AuctionRepository) CloseExpiredAuctions() {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	now := ar.clock.Now()
+	for _, auction := range ar.auctions {
+		if auction.Status == auction_entity.Active && !auction.EndTime.After(now) {
+			auction.Status = auction_entity.Completed
+			logger.Info(fmt.Sprintf("Auction %s closed", auction.Id))
+		}
+	}
+}
+
+// UseLeases sets the lease repository fenced writes are checked against.
+// Without one, fenced writes are refused.
+func (ar *AuctionRepository) UseLeases(leases *LeaseRepository) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	ar.leases = leases
+}
+
+func (ar *AuctionRepository) CloseAuction(
+	ctx context.Context, id string, fence lease_entity.Fence) (bool, *internal_error.InternalError) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	auction, ok := ar.auctions[id]
+	if !ok || auction.Status != auction_entity.Active || auction.EndTime.After(ar.clock.Now()) {
+		return false, nil
+	}
+
+	if !fence.IsZero() && (ar.leases == nil || !ar.leases.holds(fence)) {
+		logger.Info(fmt.Sprintf("Lease %s no longer has token %d, auction %s not closed", fence.Lease, fence.Token, id))
+		return false, nil
+	}
+
+	auction.Status = auction_entity.Completed
+	return true, nil
+}
+
+func (ar *AuctionRepository) ExtendAuction(
+	ctx context.Context,
+	id string,
+	endTime time.Time) (*auction_entity.Auction, *internal_error.InternalError) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	auction, ok := ar.auctions[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	}
+
+	if auction.Status != auction_entity.Active || !auction.EndTime.After(ar.clock.Now()) {
+		return nil, internal_error.NewBadRequestError("Only active auctions can be extended")
+	}
+	if !endTime.After(auction.EndTime) {
+		return nil, internal_error.NewBadRequestError("End time must be after the current end time")
+	}
+
+	auction.EndTime = time.UnixMilli(endTime.UnixMilli())
+
+	return copyAuction(auction), nil
+}
+
+func (ar *AuctionRepository) ForceCloseAuction(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	auction, ok := ar.auctions[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	}
+
+	if auction.Status != auction_entity.Active {
+		return nil, internal_error.NewBadRequestError("Only active auctions can be closed")
+	}
+
+	auction.Status = auction_entity.Completed
+	auction.EndTime = time.UnixMilli(ar.clock.Now().UnixMilli())
+
+	return copyAuction(auction), nil
+}
+
+func (ar *AuctionRepository) UpdateBidStats(
+	ctx context.Context,
+	id string,
+	highestBid float64,
+	bidCount int64) *internal_error.InternalError {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	auction, ok := ar.auctions[id]
+	if !ok {
+		return internal_error.NewNotFoundError("Auction not found")
+	}
+
+	auction.HighestBid = highestBid
+	auction.BidCount = bidCount
+	return nil
+}
+
+func (ar *AuctionRepository) RenameCategory(
+	ctx context.Context, categoryId, name string) *internal_error.InternalError {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	for _, auction := range ar.auctions {
+		if auction.CategoryId == categoryId {
+			auction.Category = name
+		}
+	}
+	return nil
+}
+
+func (ar *AuctionRepository) FindAuctionEndTimes(
+	ctx context.Context) ([]auction_entity.AuctionEndTime, *internal_error.InternalError) {
+	ar.mutex.RLock()
+	defer ar.mutex.RUnlock()
+
+	var endTimes []auction_entity.AuctionEndTime
+	for _, auction := range ar.auctions {
+		if auction.Status == auction_entity.Active {
+			endTimes = append(endTimes, auction_entity.AuctionEndTime{
+				AuctionId: auction.Id,
+				EndTime:   auction.EndTime,
+			})
+		}
+	}
+
+	return endTimes, nil
+}
+
+func (ar *AuctionRepository) FindOverdueScheduledAuctions(
+	ctx context.Context, cutoff time.Time) ([]string, *internal_error.InternalError) {
+	ar.mutex.RLock()
+	defer ar.mutex.RUnlock()
+
+	var auctionIds []string
+	for _, auction := range ar.auctions {
+		if auction.Status == auction_entity.Scheduled && !auction.StartsAt.After(cutoff) {
+			auctionIds = append(auctionIds, auction.Id)
+		}
+	}
+
+	return auctionIds, nil
+}
+
+func (ar *AuctionRepository) CreateAuction(
+	ctx context.Context, auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	return ar.CreateAuctions(ctx, []*auction_entity.Auction{auctionEntity})
+}
+
+func (ar *AuctionRepository) CreateAuctions(
+	ctx context.Context, auctionEntities []*auction_entity.Auction) *internal_error.InternalError {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	ids := make(map[string]bool, len(auctionEntities))
+	for _, auctionEntity := range auctionEntities {
+		if _, ok := ar.auctions[auctionEntity.Id]; ok || ids[auctionEntity.Id] {
+			logger.Error("Error trying to insert auction",
+				fmt.Errorf("auction %s already exists", auctionEntity.Id))
+			return internal_error.NewInternalServerError("Error trying to insert auction")
+		}
+		ids[auctionEntity.Id] = true
+	}
+
+	for _, auctionEntity := range auctionEntities {
+		auction := copyAuction(auctionEntity)
+		auction.Timestamp = time.Unix(auction.Timestamp.Unix(), 0)
+		auction.StartsAt = time.UnixMilli(auction.StartsAt.UnixMilli())
+		auction.EndTime = time.UnixMilli(auction.EndTime.UnixMilli())
+		auction.HighestBid = 0
+		auction.BidCount = 0
+		auction.Images = nil
+		ar.auctions[auction.Id] = auction
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) FindAuctionById(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	ar.mutex.RLock()
+	defer ar.mutex.RUnlock()
+
+	auction, ok := ar.auctions[id]
+	if !ok {
+		logger.Info(fmt.Sprintf("Auction not found with id = %s", id))
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	}
+
+	return copyAuction(auction), nil
+}
+
+func (ar *AuctionRepository) FindAuctionState(
+	ctx context.Context, id string) (*auction_entity.AuctionState, *internal_error.InternalError) {
+	ar.mutex.RLock()
+	defer ar.mutex.RUnlock()
+
+	auction, ok := ar.auctions[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	}
+
+	return &auction_entity.AuctionState{
+		Status:   auction.Status,
+		StartsAt: auction.StartsAt,
+		EndTime:  auction.EndTime,
+	}, nil
+}
+
+func (ar *AuctionRepository) FindAuctions(
+	ctx context.Context,
+	auctionFilter auction_entity.AuctionFilter) (*auction_entity.AuctionPage, *internal_error.InternalError) {
+	var cursor *auction_entity.AuctionCursor
+	if auctionFilter.After != "" {
+		var err *internal_error.InternalError
+		if cursor, err = auction_entity.DecodeAuctionCursor(auctionFilter.After); err != nil {
+			return nil, err
+		}
+	}
+
+	includeTerms, excludeTerms := parseTextQuery(auctionFilter.Query)
+
+	type scoredAuction struct {
+		auction *auction_entity.Auction
+		value   float64
+	}
+
+	ar.mutex.RLock()
+	var matches []scoredAuction
+	for _, auction := range ar.auctions {
+		if !matchesAuctionFilter(auction, auctionFilter) {
+			continue
+		}
+
+		value := auction.SortValue(auctionFilter.Sort)
+		if auctionFilter.Query != "" {
+			score := textScore(auction, includeTerms, excludeTerms)
+			if score == 0 {
+				continue
+			}
+			if auctionFilter.Sort == auction_entity.SortRelevance {
+				value = score
+			}
+		}
+
+		matches = append(matches, scoredAuction{auction: copyAuction(auction), value: value})
+	}
+	ar.mutex.RUnlock()
+
+	descending := auctionFilter.Sort.Descending()
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].value != matches[j].value {
+			return (matches[i].value > matches[j].value) == descending
+		}
+		return matches[i].auction.Id < matches[j].auction.Id
+	})
+
+	auctionPage := &auction_entity.AuctionPage{
+		Auctions: []auction_entity.Auction{},
+		Total:    int64(len(matches)),
+	}
+
+	var lastMatch *scoredAuction
+	for i := range matches {
+		match := matches[i]
+		if cursor != nil && !afterCursor(match.value, match.auction.Id, cursor, descending) {
+			continue
+		}
+
+		if int64(len(auctionPage.Auctions)) == auctionFilter.Limit {
+			auctionPage.NextCursor = auction_entity.AuctionCursor{
+				Value: lastMatch.value,
+				Id:    lastMatch.auction.Id,
+			}.Encode()
+			break
+		}
+
+		auctionPage.Auctions = append(auctionPage.Auctions, *match.auction)
+		lastMatch = &matches[i]
+	}
+
+	return auctionPage, nil
+}
+
+func (ar *AuctionRepository) ExportAuctions(
+	ctx context.Context,
+	filter auction_entity.AuctionExportFilter,
+	fn func(auction *auction_entity.Auction) *internal_error.InternalError) *internal_error.InternalError {
+	for _, auction := range ar.exportedAuctions(filter) {
+		if err := fn(auction); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportedAuctions returns copies of the auctions matching filter, oldest
+// first.
+func (ar *AuctionRepository) exportedAuctions(
+	filter auction_entity.AuctionExportFilter) []*auction_entity.Auction {
+	ar.mutex.RLock()
+	var auctions []*auction_entity.Auction
+	for _, auction := range ar.auctions {
+		if matchesExportFilter(auction, filter) {
+			auctions = append(auctions, copyAuction(auction))
+		}
+	}
+	ar.mutex.RUnlock()
+
+	sort.Slice(auctions, func(i, j int) bool {
+		if !auctions[i].Timestamp.Equal(auctions[j].Timestamp) {
+			return auctions[i].Timestamp.Before(auctions[j].Timestamp)
+		}
+		return auctions[i].Id < auctions[j].Id
+	})
+
+	return auctions
+}
+
+func (ar *AuctionRepository) SuggestProductNames(
+	ctx context.Context,
+	prefix string,
+	limit int64) ([]string, *internal_error.InternalError) {
+	ar.mutex.RLock()
+	productNames := map[string]bool{}
+	for _, auction := range ar.auctions {
+		if strings.HasPrefix(strings.ToLower(auction.ProductName), strings.ToLower(prefix)) {
+			productNames[auction.ProductName] = true
+		}
+	}
+	ar.mutex.RUnlock()
+
+	suggestions := make([]string, 0, len(productNames))
+	for productName := range productNames {
+		suggestions = append(suggestions, productName)
+	}
+	sort.Strings(suggestions)
+
+	if int64(len(suggestions)) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions, nil
+}
+
+func (ar *AuctionRepository) AddAuctionImage(
+	ctx context.Context,
+	auctionId string,
+	image auction_entity.AuctionImage,
+	maxImages int) *internal_error.InternalError {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	auction, ok := ar.auctions[auctionId]
+	if !ok || auction.Status != auction_entity.Active && auction.Status != auction_entity.Scheduled ||
+		len(auction.Images) >= maxImages {
+		return internal_error.NewBadRequestError(fmt.Sprintf(
+			"Auction must be active or scheduled and have less than %d images", maxImages))
+	}
+
+	image.Timestamp = time.Unix(image.Timestamp.Unix(), 0)
+	auction.Images = append(auction.Images, image)
+
+	return nil
+}
+
+func (ar *AuctionRepository) RemoveAuctionImage(
+	ctx context.Context,
+	auctionId, imageId string) *internal_error.InternalError {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	if auction, ok := ar.auctions[auctionId]; ok {
+		for i, image := range auction.Images {
+			if image.Id == imageId {
+				auction.Images = append(auction.Images[:i:i], auction.Images[i+1:]...)
+				return nil
+			}
+		}
+	}
+
+	return internal_error.NewNotFoundError("Auction image not found")
+}
+
+func (ar *AuctionRepository) CancelAuction(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	auction, ok := ar.auctions[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	}
+
+	if auction.Status != auction_entity.Active && auction.Status != auction_entity.Scheduled {
+		return nil, internal_error.NewBadRequestError("Only active or scheduled auctions can be cancelled")
+	}
+
+	cancelledAuction := copyAuction(auction)
+	auction.Status = auction_entity.Cancelled
+	auction.Images = nil
+
+	logger.Info(fmt.Sprintf("Auction %s cancelled", id))
+
+	return cancelledAuction, nil
+}
+
+func (ar *AuctionRepository) StartAuction(
+	ctx context.Context, id string) (bool, *internal_error.InternalError) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	auction, ok := ar.auctions[id]
+	if !ok || auction.Status != auction_entity.Scheduled {
+		return false, nil
+	}
+
+	auction.Status = auction_entity.Active
+	return true, nil
+}
+
+// AcceptBid checks that the auction still accepts bids and records the bid
+// stats under the same lock the closure takes, reporting whether the bid was
+// accepted.
+func (ar *AuctionRepository) AcceptBid(
+	ctx context.Context, auctionId string, amount float64) (bool, *internal_error.InternalError) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	auction, ok := ar.auctions[auctionId]
+	if !ok {
+		return false, internal_error.NewNotFoundError("Auction not found")
+	}
+
+	if auction.Status != auction_entity.Active || !auction.EndTime.After(ar.clock.Now()) {
+		return false, nil
+	}
+
+	if amount > auction.HighestBid {
+		auction.HighestBid = amount
+	}
+	auction.BidCount++
+
+	return true, nil
+}
+
+func matchesAuctionFilter(auction *auction_entity.Auction, filter auction_entity.AuctionFilter) bool {
+	if filter.Status != nil && auction.Status != *filter.Status {
+		return false
+	}
+
+	if len(filter.CategoryIds) > 0 && !containsString(filter.CategoryIds, auction.CategoryId) {
+		return false
+	}
+
+	if filter.ProductName != "" &&
+		!strings.Contains(strings.ToLower(auction.ProductName), strings.ToLower(filter.ProductName)) {
+		return false
+	}
+
+	if filter.CreatedFrom != nil && auction.Timestamp.Unix() < filter.CreatedFrom.Unix() {
+		return false
+	}
+
+	if filter.CreatedTo != nil && auction.Timestamp.Unix() > filter.CreatedTo.Unix() {
+		return false
+	}
+
+	for _, attributeFilter := range filter.Attributes {
+		if !matchesAttributeFilter(auction.Attributes[attributeFilter.Key], attributeFilter) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesExportFilter(auction *auction_entity.Auction, filter auction_entity.AuctionExportFilter) bool {
+	if !matchesAuctionFilter(auction, auction_entity.AuctionFilter{
+		Status:      filter.Status,
+		CategoryIds: filter.CategoryIds,
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+	}) {
+		return false
+	}
+
+	if filter.EndedFrom != nil && auction.EndTime.UnixMilli() < filter.EndedFrom.UnixMilli() {
+		return false
+	}
+
+	if filter.EndedTo != nil && auction.EndTime.UnixMilli() > filter.EndedTo.UnixMilli() {
+		return false
+	}
+
+	return true
+}
+
+func matchesAttributeFilter(value interface{}, filter auction_entity.AttributeFilter) bool {
+	if value == nil {
+		return false
+	}
+
+	if filter.Operator == auction_entity.AttributeEq {
+		for _, expected := range filter.Values {
+			if value == expected {
+				return true
+			}
+		}
+		return false
+	}
+
+	number, ok := value.(float64)
+	if !ok || len(filter.Values) == 0 {
+		return false
+	}
+
+	bound, ok := filter.Values[0].(float64)
+	if !ok {
+		return false
+	}
+
+	switch filter.Operator {
+	case auction_entity.AttributeGt:
+		return number > bound
+	case auction_entity.AttributeGte:
+		return number >= bound
+	case auction_entity.AttributeLt:
+		return number < bound
+	case auction_entity.AttributeLte:
+		return number <= bound
+	}
+
+	return false
+}
+
+// parseTextQuery splits a search query into lowercase, accent-free terms.
+// Terms prefixed with "-" exclude matching auctions, like Mongo's $text.
+func parseTextQuery(query string) ([]string, []string) {
+	var includeTerms, excludeTerms []string
+	for _, field := range strings.Fields(query) {
+		exclude := strings.HasPrefix(field, "-")
+		for _, term := range textTokens(strings.TrimPrefix(field, "-")) {
+			if exclude {
+				excludeTerms = append(excludeTerms, term)
+			} else {
+				includeTerms = append(includeTerms, term)
+			}
+		}
+	}
+
+	return includeTerms, excludeTerms
+}
+
+func textScore(auction *auction_entity.Auction, includeTerms, excludeTerms []string) float64 {
+	fields := []struct {
+		tokens []string
+		weight float64
+	}{
+		{textTokens(auction.ProductName), productNameWeight},
+		{textTokens(auction.Category), categoryWeight},
+		{textTokens(auction.Description), descriptionWeight},
+	}
+
+	score := 0.0
+	for _, field := range fields {
+		for _, token := range field.tokens {
+			if containsString(excludeTerms, token) {
+				return 0
+			}
+			if containsString(includeTerms, token) {
+				score += field.weight
+			}
+		}
+	}
+
+	return score
+}
+
+func textTokens(text string) []string {
+	slug := category_entity.Slugify(text)
+	if slug == "" {
+		return nil
+	}
+
+	return strings.Split(slug, "-")
+}
+
+func afterCursor(value float64, id string, cursor *auction_entity.AuctionCursor, descending bool) bool {
+	if value == cursor.Value {
+		return id > cursor.Id
+	}
+
+	return (value < cursor.Value) == descending
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func copyAuction(auction *auction_entity.Auction) *auction_entity.Auction {
+	auctionCopy := *auction
+	auctionCopy.Images = append([]auction_entity.AuctionImage(nil), auction.Images...)
+
+	if auction.Attributes != nil {
+		auctionCopy.Attributes = make(map[string]interface{}, len(auction.Attributes))
+		for key, value := range auction.Attributes {
+			auctionCopy.Attributes[key] = value
+		}
+	}
+
+	return &auctionCopy
+}
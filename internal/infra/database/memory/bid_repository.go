@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+	"time"
+)
+
+type BidRepository struct {
+	AuctionRepository *AuctionRepository
+
+	mutex sync.RWMutex
+	bids  []bid_entity.Bid
+	ids   map[string]bool
+}
+
+func NewBidRepository(auctionRepository *AuctionRepository) *BidRepository {
+	return &BidRepository{
+		AuctionRepository: auctionRepository,
+		ids:               map[string]bool{},
+	}
+}
+
+func (bd *BidRepository) CreateBid(
+	ctx context.Context, bidEntities []bid_entity.Bid) ([]bid_entity.BidResult, *internal_error.InternalError) {
+	bd.mutex.Lock()
+	defer bd.mutex.Unlock()
+
+	results := make([]bid_entity.BidResult, len(bidEntities))
+	for i, bid := range bidEntities {
+		results[i].BidId = bid.Id
+
+		if bd.ids[bid.Id] {
+			logger.Info(fmt.Sprintf("Bid %s already stored, skipping", bid.Id))
+			results[i].Status = bid_entity.BidDuplicate
+			continue
+		}
+
+		accepted, err := bd.AuctionRepository.AcceptBid(ctx, bid.AuctionId, bid.Amount)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Erro ao buscar leilão ID %s", bid.AuctionId), err)
+			results[i].Status, results[i].Err = bid_entity.BidRejected, err
+			continue
+		}
+		if !accepted {
+			logger.Info(fmt.Sprintf("Auction %s is not active, bid rejected", bid.AuctionId))
+			results[i].Status = bid_entity.BidRejected
+			continue
+		}
+
+		bid.Timestamp = time.Unix(bid.Timestamp.Unix(), 0)
+		bd.bids = append(bd.bids, bid)
+		bd.ids[bid.Id] = true
+		results[i].Status = bid_entity.BidStored
+	}
+
+	return results, nil
+}
+
+func (bd *BidRepository) FindBidByAuctionId(
+	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
+	bd.mutex.RLock()
+	defer bd.mutex.RUnlock()
+
+	var bidEntities []bid_entity.Bid
+	for _, bid := range bd.bids {
+		if bid.AuctionId == auctionId {
+			bidEntities = append(bidEntities, bid)
+		}
+	}
+
+	return bidEntities, nil
+}
+
+func (bd *BidRepository) FindWinningBidByAuctionId(
+	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	bidEntities, _ := bd.FindBidByAuctionId(ctx, auctionId)
+	if len(bidEntities) == 0 {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("No bids found for auctionId %s", auctionId))
+	}
+
+	sortWinningFirst(bidEntities)
+
+	return &bidEntities[0], nil
+}
+
+func (bd *BidRepository) FindBidsByUserId(
+	ctx context.Context,
+	userId string,
+	auctionStatus *auction_entity.AuctionStatus,
+	page, limit int64) ([]bid_entity.Bid, int64, *internal_error.InternalError) {
+	bd.mutex.RLock()
+	var bidEntities []bid_entity.Bid
+	for _, bid := range bd.bids {
+		if bid.UserId == userId {
+			bidEntities = append(bidEntities, bid)
+		}
+	}
+	bd.mutex.RUnlock()
+
+	if auctionStatus != nil {
+		filtered := bidEntities[:0]
+		for _, bid := range bidEntities {
+			auction, err := bd.AuctionRepository.FindAuctionById(ctx, bid.AuctionId)
+			if err == nil && auction.Status == *auctionStatus {
+				filtered = append(filtered, bid)
+			}
+		}
+		bidEntities = filtered
+	}
+
+	return paginateBids(bidEntities, page, limit), int64(len(bidEntities)), nil
+}
+
+func (bd *BidRepository) FindWinningBidsByUserId(
+	ctx context.Context,
+	userId string,
+	page, limit int64) ([]bid_entity.Bid, int64, *internal_error.InternalError) {
+	bd.mutex.RLock()
+	auctionIds := map[string]bool{}
+	for _, bid := range bd.bids {
+		if bid.UserId == userId {
+			auctionIds[bid.AuctionId] = true
+		}
+	}
+	bd.mutex.RUnlock()
+
+	var winningBids []bid_entity.Bid
+	for auctionId := range auctionIds {
+		auction, err := bd.AuctionRepository.FindAuctionById(ctx, auctionId)
+		if err != nil || auction.Status != auction_entity.Completed {
+			continue
+		}
+
+		winningBid, err := bd.FindWinningBidByAuctionId(ctx, auctionId)
+		if err == nil && winningBid.UserId == userId {
+			winningBids = append(winningBids, *winningBid)
+		}
+	}
+
+	return paginateBids(winningBids, page, limit), int64(len(winningBids)), nil
+}
+
+// ExportBids calls fn for the bids of each auction matching filter, in the
+// auctions' export order and oldest bid first within an auction.
+func (bd *BidRepository) ExportBids(
+	ctx context.Context,
+	filter auction_entity.AuctionExportFilter,
+	fn func(bid *bid_entity.Bid) *internal_error.InternalError) *internal_error.InternalError {
+	auctions := bd.AuctionRepository.exportedAuctions(filter)
+
+	bd.mutex.RLock()
+	bidsByAuction := map[string][]bid_entity.Bid{}
+	for _, bid := range bd.bids {
+		bidsByAuction[bid.AuctionId] = append(bidsByAuction[bid.AuctionId], bid)
+	}
+	bd.mutex.RUnlock()
+
+	for _, auction := range auctions {
+		bidEntities := bidsByAuction[auction.Id]
+		sort.Slice(bidEntities, func(i, j int) bool {
+			if !bidEntities[i].Timestamp.Equal(bidEntities[j].Timestamp) {
+				return bidEntities[i].Timestamp.Before(bidEntities[j].Timestamp)
+			}
+			return bidEntities[i].Id < bidEntities[j].Id
+		})
+
+		for i := range bidEntities {
+			if err := fn(&bidEntities[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sortWinningFirst orders bids by amount descending, earliest bid first on ties.
+func sortWinningFirst(bidEntities []bid_entity.Bid) {
+	sort.SliceStable(bidEntities, func(i, j int) bool {
+		if bidEntities[i].Amount != bidEntities[j].Amount {
+			return bidEntities[i].Amount > bidEntities[j].Amount
+		}
+		return bidEntities[i].Timestamp.Before(bidEntities[j].Timestamp)
+	})
+}
+
+func paginateBids(bidEntities []bid_entity.Bid, page, limit int64) []bid_entity.Bid {
+	sort.SliceStable(bidEntities, func(i, j int) bool {
+		if !bidEntities[i].Timestamp.Equal(bidEntities[j].Timestamp) {
+			return bidEntities[i].Timestamp.After(bidEntities[j].Timestamp)
+		}
+		return bidEntities[i].Id < bidEntities[j].Id
+	})
+
+	start := (page - 1) * limit
+	if start >= int64(len(bidEntities)) {
+		return []bid_entity.Bid{}
+	}
+
+	end := start + limit
+	if end > int64(len(bidEntities)) {
+		end = int64(len(bidEntities))
+	}
+
+	return append([]bid_entity.Bid{}, bidEntities[start:end]...)
+}
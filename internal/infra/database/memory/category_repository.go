@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+	"time"
+)
+
+type CategoryRepository struct {
+	mutex      sync.RWMutex
+	categories map[string]category_entity.Category
+}
+
+func NewCategoryRepository() *CategoryRepository {
+	return &CategoryRepository{
+		categories: map[string]category_entity.Category{},
+	}
+}
+
+func (cr *CategoryRepository) CreateCategory(
+	ctx context.Context, category *category_entity.Category) *internal_error.InternalError {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+
+	if _, ok := cr.categories[category.Id]; ok || cr.slugInUse(category.Slug, category.Id) {
+		logger.Info(fmt.Sprintf("Category already exists with slug: %s", category.Slug))
+		return internal_error.NewConflictError("Category already exists with this name")
+	}
+
+	cr.categories[category.Id] = copyCategory(category)
+
+	return nil
+}
+
+func (cr *CategoryRepository) UpdateCategory(
+	ctx context.Context, category *category_entity.Category) *internal_error.InternalError {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+
+	storedCategory, ok := cr.categories[category.Id]
+	if !ok {
+		return internal_error.NewNotFoundError("Category not found")
+	}
+
+	if cr.slugInUse(category.Slug, category.Id) {
+		logger.Info(fmt.Sprintf("Category already exists with slug: %s", category.Slug))
+		return internal_error.NewConflictError("Category already exists with this name")
+	}
+
+	updatedCategory := copyCategory(category)
+	updatedCategory.Timestamp = storedCategory.Timestamp
+	cr.categories[category.Id] = updatedCategory
+
+	return nil
+}
+
+func (cr *CategoryRepository) DeleteCategory(
+	ctx context.Context, id string) *internal_error.InternalError {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+
+	if _, ok := cr.categories[id]; !ok {
+		return internal_error.NewNotFoundError("Category not found")
+	}
+
+	delete(cr.categories, id)
+
+	return nil
+}
+
+func (cr *CategoryRepository) FindCategoryById(
+	ctx context.Context, id string) (*category_entity.Category, *internal_error.InternalError) {
+	cr.mutex.RLock()
+	defer cr.mutex.RUnlock()
+
+	category, ok := cr.categories[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Category not found")
+	}
+
+	category = copyCategory(&category)
+	return &category, nil
+}
+
+func (cr *CategoryRepository) FindCategoryBySlug(
+	ctx context.Context, slug string) (*category_entity.Category, *internal_error.InternalError) {
+	cr.mutex.RLock()
+	defer cr.mutex.RUnlock()
+
+	for _, category := range cr.categories {
+		if category.Slug == slug {
+			category = copyCategory(&category)
+			return &category, nil
+		}
+	}
+
+	return nil, internal_error.NewNotFoundError("Category not found")
+}
+
+func (cr *CategoryRepository) FindCategories(
+	ctx context.Context) ([]category_entity.Category, *internal_error.InternalError) {
+	cr.mutex.RLock()
+	categories := make([]category_entity.Category, 0, len(cr.categories))
+	for _, category := range cr.categories {
+		categories = append(categories, copyCategory(&category))
+	}
+	cr.mutex.RUnlock()
+
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].Id < categories[j].Id
+	})
+
+	return categories, nil
+}
+
+func (cr *CategoryRepository) slugInUse(slug, exceptCategoryId string) bool {
+	for _, category := range cr.categories {
+		if category.Slug == slug && category.Id != exceptCategoryId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func copyCategory(category *category_entity.Category) category_entity.Category {
+	categoryCopy := *category
+	categoryCopy.Timestamp = time.Unix(category.Timestamp.Unix(), 0)
+	categoryCopy.Attributes = make([]category_entity.AttributeDefinition, 0, len(category.Attributes))
+	for _, definition := range category.Attributes {
+		definition.Options = append([]string(nil), definition.Options...)
+		categoryCopy.Attributes = append(categoryCopy.Attributes, definition)
+	}
+
+	return categoryCopy
+}
@@ -0,0 +1,205 @@
+package memory
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/job_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+	"time"
+)
+
+type JobRepository struct {
+	mutex sync.Mutex
+	jobs  map[string]*job_entity.Job
+}
+
+func NewJobRepository() *JobRepository {
+	return &JobRepository{
+		jobs: map[string]*job_entity.Job{},
+	}
+}
+
+func (jr *JobRepository) CreateJob(
+	ctx context.Context, job *job_entity.Job) (*job_entity.Job, bool, *internal_error.InternalError) {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+
+	if job.IdempotencyKey != "" {
+		for _, existing := range jr.jobs {
+			if existing.IdempotencyKey == job.IdempotencyKey {
+				return copyJob(existing), false, nil
+			}
+		}
+	}
+
+	stored := copyJob(job)
+	stored.RunAt = truncateMilli(stored.RunAt)
+	stored.CreatedAt = truncateMilli(stored.CreatedAt)
+	stored.UpdatedAt = truncateMilli(stored.UpdatedAt)
+	jr.jobs[job.Id] = stored
+
+	return copyJob(stored), true, nil
+}
+
+func (jr *JobRepository) ClaimJob(
+	ctx context.Context,
+	workerId string,
+	now time.Time,
+	leaseTTL time.Duration) (*job_entity.Job, *internal_error.InternalError) {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+
+	var next *job_entity.Job
+	for _, job := range jr.jobs {
+		due := job.Status == job_entity.Pending && !job.RunAt.After(now) ||
+			job.Status == job_entity.Running && !job.LeaseExpiresAt.After(now)
+		if !due {
+			continue
+		}
+
+		if next == nil || job.RunAt.Before(next.RunAt) ||
+			job.RunAt.Equal(next.RunAt) && job.Id < next.Id {
+			next = job
+		}
+	}
+
+	if next == nil {
+		return nil, nil
+	}
+
+	next.Status = job_entity.Running
+	next.ClaimedBy = workerId
+	next.Token++
+	next.Attempts++
+	next.LeaseExpiresAt = truncateMilli(now.Add(leaseTTL))
+	next.UpdatedAt = truncateMilli(now)
+
+	return copyJob(next), nil
+}
+
+func (jr *JobRepository) FinishJob(
+	ctx context.Context, job *job_entity.Job) (bool, *internal_error.InternalError) {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+
+	stored, ok := jr.jobs[job.Id]
+	if !ok || stored.Status != job_entity.Running ||
+		stored.ClaimedBy != job.ClaimedBy || stored.Token != job.Token {
+		return false, nil
+	}
+
+	stored.Status = job.Status
+	stored.RunAt = truncateMilli(job.RunAt)
+	stored.Attempts = job.Attempts
+	stored.LastError = job.LastError
+	stored.ClaimedBy = ""
+	stored.LeaseExpiresAt = time.Time{}
+	stored.UpdatedAt = truncateMilli(job.UpdatedAt)
+
+	return true, nil
+}
+
+func (jr *JobRepository) FindJobById(
+	ctx context.Context, id string) (*job_entity.Job, *internal_error.InternalError) {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+
+	job, ok := jr.jobs[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Job not found")
+	}
+
+	return copyJob(job), nil
+}
+
+func (jr *JobRepository) FindJobs(
+	ctx context.Context, jobFilter job_entity.JobFilter) ([]job_entity.Job, int64, *internal_error.InternalError) {
+	jr.mutex.Lock()
+	var jobs []job_entity.Job
+	for _, job := range jr.jobs {
+		if jobFilter.Status != nil && job.Status != *jobFilter.Status {
+			continue
+		}
+
+		if jobFilter.Type != "" && job.Type != jobFilter.Type {
+			continue
+		}
+
+		jobs = append(jobs, *copyJob(job))
+	}
+	jr.mutex.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].RunAt.Equal(jobs[j].RunAt) {
+			return jobs[i].RunAt.Before(jobs[j].RunAt)
+		}
+		return jobs[i].Id < jobs[j].Id
+	})
+
+	total := int64(len(jobs))
+	start := (jobFilter.Page - 1) * jobFilter.Limit
+	if start >= total {
+		return []job_entity.Job{}, total, nil
+	}
+
+	end := start + jobFilter.Limit
+	if end > total {
+		end = total
+	}
+
+	return jobs[start:end], total, nil
+}
+
+func (jr *JobRepository) RetryJob(
+	ctx context.Context, id string, runAt time.Time) (*job_entity.Job, *internal_error.InternalError) {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+
+	job, ok := jr.jobs[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Job not found")
+	}
+
+	if job.Status != job_entity.Failed && job.Status != job_entity.Cancelled {
+		return nil, internal_error.NewBadRequestError("Only failed or cancelled jobs can be retried")
+	}
+
+	job.Status = job_entity.Pending
+	job.RunAt = truncateMilli(runAt)
+	job.Attempts = 0
+
+	return copyJob(job), nil
+}
+
+func (jr *JobRepository) CancelJob(
+	ctx context.Context, id string) (*job_entity.Job, *internal_error.InternalError) {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+
+	job, ok := jr.jobs[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Job not found")
+	}
+
+	if job.Status != job_entity.Pending && job.Status != job_entity.Failed {
+		return nil, internal_error.NewBadRequestError("Only pending or failed jobs can be cancelled")
+	}
+
+	job.Status = job_entity.Cancelled
+
+	return copyJob(job), nil
+}
+
+func copyJob(job *job_entity.Job) *job_entity.Job {
+	jobCopy := *job
+	jobCopy.Payload = append([]byte(nil), job.Payload...)
+	return &jobCopy
+}
+
+func truncateMilli(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.UnixMilli(t.UnixMilli())
+}
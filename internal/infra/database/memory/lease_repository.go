@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sync"
+	"time"
+)
+
+type LeaseRepository struct {
+	clock  clock.Clock
+	mutex  sync.Mutex
+	leases map[string]lease_entity.Lease
+}
+
+func NewLeaseRepository(clock clock.Clock) *LeaseRepository {
+	return &LeaseRepository{
+		clock:  clock,
+		leases: map[string]lease_entity.Lease{},
+	}
+}
+
+func (lr *LeaseRepository) AcquireLease(
+	ctx context.Context,
+	name, holderId string,
+	ttl time.Duration) (*lease_entity.Lease, bool, *internal_error.InternalError) {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+
+	now := lr.clock.Now()
+	lease, ok := lr.leases[name]
+	switch {
+	case ok && lease.HolderId == holderId && lease.ExpiresAt.After(now):
+	case !ok || !lease.ExpiresAt.After(now):
+		lease.Name = name
+		lease.HolderId = holderId
+		lease.Token++
+	default:
+		return &lease, false, nil
+	}
+
+	lease.ExpiresAt = now.Add(ttl)
+	lr.leases[name] = lease
+
+	return &lease, true, nil
+}
+
+func (lr *LeaseRepository) ReleaseLease(
+	ctx context.Context, name, holderId string, token int64) *internal_error.InternalError {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+
+	if lease, ok := lr.leases[name]; ok && lease.HolderId == holderId && lease.Token == token {
+		lease.ExpiresAt = time.UnixMilli(0)
+		lr.leases[name] = lease
+	}
+
+	return nil
+}
+
+func (lr *LeaseRepository) FindLease(
+	ctx context.Context, name string) (*lease_entity.Lease, *internal_error.InternalError) {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+
+	lease, ok := lr.leases[name]
+	if !ok {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Lease %s not found", name))
+	}
+
+	return &lease, nil
+}
+
+// holds reports whether the lease of fence still has its token.
+func (lr *LeaseRepository) holds(fence lease_entity.Fence) bool {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+
+	lease, ok := lr.leases[fence.Lease]
+	return ok && lease.Token == fence.Token
+}
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type UserRepository struct {
+	mutex sync.RWMutex
+	users map[string]user_entity.User
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users: map[string]user_entity.User{},
+	}
+}
+
+func (ur *UserRepository) FindUserById(
+	ctx context.Context, userId string) (*user_entity.User, *internal_error.InternalError) {
+	ur.mutex.RLock()
+	defer ur.mutex.RUnlock()
+
+	user, ok := ur.users[userId]
+	if !ok {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("User not found with this id = %s", userId))
+	}
+
+	return &user, nil
+}
+
+func (ur *UserRepository) CreateUser(
+	ctx context.Context, user *user_entity.User) *internal_error.InternalError {
+	ur.mutex.Lock()
+	defer ur.mutex.Unlock()
+
+	if _, ok := ur.users[user.Id]; ok || ur.emailInUse(user.Email, user.Id) {
+		logger.Info(fmt.Sprintf("User already exists with id: %s or email: %s", user.Id, user.Email))
+		return internal_error.NewConflictError("User already exists with this email")
+	}
+
+	ur.users[user.Id] = *user
+
+	return nil
+}
+
+func (ur *UserRepository) UpdateUserProfile(
+	ctx context.Context,
+	userId string,
+	update user_entity.UserProfileUpdate) *internal_error.InternalError {
+	ur.mutex.Lock()
+	defer ur.mutex.Unlock()
+
+	user, ok := ur.users[userId]
+	if !ok || user.Status == user_entity.Inactive {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Active user not found with this id = %s", userId))
+	}
+
+	if update.Name != nil {
+		user.Name = *update.Name
+	}
+	if update.Email != nil {
+		if ur.emailInUse(*update.Email, userId) {
+			logger.Info(fmt.Sprintf("Email %s already in use, user %s not updated", *update.Email, userId))
+			return internal_error.NewConflictError("User already exists with this email")
+		}
+		user.Email = *update.Email
+	}
+
+	ur.users[userId] = user
+
+	return nil
+}
+
+func (ur *UserRepository) DeactivateUser(
+	ctx context.Context, userId string) *internal_error.InternalError {
+	ur.mutex.Lock()
+	defer ur.mutex.Unlock()
+
+	user, ok := ur.users[userId]
+	if !ok {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("User not found with this id = %s", userId))
+	}
+
+	user.Status = user_entity.Inactive
+	ur.users[userId] = user
+
+	return nil
+}
+
+func (ur *UserRepository) FindUsers(
+	ctx context.Context, userFilter user_entity.UserFilter) ([]user_entity.User, int64, *internal_error.InternalError) {
+	ur.mutex.RLock()
+	var users []user_entity.User
+	for _, user := range ur.users {
+		if userFilter.Name != "" &&
+			!strings.Contains(strings.ToLower(user.Name), strings.ToLower(userFilter.Name)) {
+			continue
+		}
+
+		if userFilter.Email != "" && user.Email != user_entity.NormalizeEmail(userFilter.Email) {
+			continue
+		}
+
+		if userFilter.Status != nil && user.Status != *userFilter.Status {
+			continue
+		}
+
+		users = append(users, user)
+	}
+	ur.mutex.RUnlock()
+
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Name != users[j].Name {
+			return users[i].Name < users[j].Name
+		}
+		return users[i].Id < users[j].Id
+	})
+
+	total := int64(len(users))
+	start := (userFilter.Page - 1) * userFilter.Limit
+	if start >= total {
+		return []user_entity.User{}, total, nil
+	}
+
+	end := start + userFilter.Limit
+	if end > total {
+		end = total
+	}
+
+	return users[start:end], total, nil
+}
+
+func (ur *UserRepository) emailInUse(email, exceptUserId string) bool {
+	if email == "" {
+		return false
+	}
+
+	for _, user := range ur.users {
+		if user.Email == email && user.Id != exceptUserId {
+			return true
+		}
+	}
+
+	return false
+}
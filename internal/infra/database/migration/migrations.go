@@ -0,0 +1,312 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Server error codes dropping an index that, or whose collection, does not
+// exist.
+const (
+	namespaceNotFoundCode = 26
+	indexNotFoundCode     = 27
+)
+
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create auction indexes",
+		Up:          createIndexes("auctions", auctionIndexes()),
+		Down:        dropIndexes("auctions", auctionIndexes()),
+	},
+	{
+		Version:     2,
+		Description: "create bid indexes",
+		Up:          createIndexes("bids", bidIndexes()),
+		Down:        dropIndexes("bids", bidIndexes()),
+	},
+	{
+		Version:     3,
+		Description: "create user indexes",
+		Up:          createIndexes("users", userIndexes()),
+		Down:        dropIndexes("users", userIndexes()),
+	},
+	{
+		Version:     4,
+		Description: "create category indexes",
+		Up:          createIndexes("categories", categoryIndexes()),
+		Down:        dropIndexes("categories", categoryIndexes()),
+	},
+	{
+		Version:     5,
+		Description: "create job indexes",
+		Up:          createIndexes("jobs", jobIndexes()),
+		Down:        dropIndexes("jobs", jobIndexes()),
+	},
+	{
+		Version:     6,
+		Description: "backfill auction end times",
+		Up:          backfillAuctionEndTimes,
+		Down:        keepDocuments,
+	},
+	{
+		Version:     7,
+		Description: "backfill auction start times",
+		Up:          backfillAuctionStartTimes,
+		Down:        keepDocuments,
+	},
+	{
+		Version:     8,
+		Description: "backfill auction bid stats",
+		Up:          backfillAuctionBidStats,
+		Down:        keepDocuments,
+	},
+	{
+		Version:     9,
+		Description: "default user status",
+		Up:          defaultUserStatus,
+		Down:        keepDocuments,
+	},
+}
+
+func auctionIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "product_name", Value: "text"},
+				{Key: "description", Value: "text"},
+				{Key: "category", Value: "text"},
+			},
+			Options: options.Index().
+				SetName("auction_text_search").
+				SetDefaultLanguage("none").
+				SetWeights(bson.M{"product_name": 10, "category": 5, "description": 1}),
+		},
+		{
+			Keys:    bson.D{{Key: "category_id", Value: 1}},
+			Options: options.Index().SetName("category_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "timestamp", Value: -1}},
+			Options: options.Index().SetName("status_timestamp"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "end_time", Value: 1}},
+			Options: options.Index().SetName("status_end_time"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "starts_at", Value: 1}},
+			Options: options.Index().SetName("status_starts_at"),
+		},
+	}
+}
+
+func bidIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "auction_id", Value: 1},
+				{Key: "amount", Value: -1},
+				{Key: "timestamp", Value: 1},
+			},
+			Options: options.Index().SetName("auction_id_amount"),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "timestamp", Value: -1}},
+			Options: options.Index().SetName("user_id_timestamp"),
+		},
+	}
+}
+
+func userIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().
+				SetName("email_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"email": bson.M{"$type": "string"}}),
+		},
+	}
+}
+
+func categoryIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().SetName("slug_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "parent_id", Value: 1}},
+			Options: options.Index().SetName("parent_id"),
+		},
+	}
+}
+
+func jobIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "run_at", Value: 1}},
+			Options: options.Index().SetName("status_run_at"),
+		},
+		{
+			Keys: bson.D{{Key: "idempotency_key", Value: 1}},
+			Options: options.Index().
+				SetName("idempotency_key_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"idempotency_key": bson.M{"$type": "string"}}),
+		},
+	}
+}
+
+// createIndexes creates the indexes of a collection. Indexes that already
+// exist with the same name and keys, as created by earlier releases, are left
+// as they are.
+func createIndexes(
+	collection string, indexes []mongo.IndexModel) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, database *mongo.Database) error {
+		_, err := database.Collection(collection).Indexes().CreateMany(ctx, indexes)
+		return err
+	}
+}
+
+func dropIndexes(
+	collection string, indexes []mongo.IndexModel) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, database *mongo.Database) error {
+		for _, index := range indexes {
+			_, err := database.Collection(collection).Indexes().DropOne(ctx, *index.Options.Name)
+			var commandErr mongo.CommandError
+			if errors.As(err, &commandErr) &&
+				(commandErr.Code == namespaceNotFoundCode || commandErr.Code == indexNotFoundCode) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// keepDocuments reverts a backfill by leaving the documents as they are: the
+// fields it filled in are valid for earlier releases too.
+func keepDocuments(context.Context, *mongo.Database) error {
+	return nil
+}
+
+// backfillAuctionEndTimes stores the end time of auctions created before it
+// was kept on the document, derived from their creation time and
+// AUCTION_DURATION.
+func backfillAuctionEndTimes(ctx context.Context, database *mongo.Database) error {
+	result, err := database.Collection("auctions").UpdateMany(ctx,
+		bson.M{"end_time": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"end_time": bson.M{"$add": bson.A{
+				bson.M{"$multiply": bson.A{"$timestamp", 1000}},
+				config.Current().AuctionDuration.Milliseconds(),
+			}},
+		}}}})
+	if err != nil {
+		return err
+	}
+
+	logBackfill("the end time of", "auctions", result.ModifiedCount)
+	return nil
+}
+
+// backfillAuctionStartTimes stores the start time of auctions created before
+// it was kept on the document, which opened as soon as they were created.
+func backfillAuctionStartTimes(ctx context.Context, database *mongo.Database) error {
+	result, err := database.Collection("auctions").UpdateMany(ctx,
+		bson.M{"starts_at": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"starts_at": bson.M{"$multiply": bson.A{"$timestamp", 1000}},
+		}}}})
+	if err != nil {
+		return err
+	}
+
+	logBackfill("the start time of", "auctions", result.ModifiedCount)
+	return nil
+}
+
+// backfillAuctionBidStats computes the highest bid and bid count of auctions
+// stored before they were kept on the document from their bids.
+func backfillAuctionBidStats(ctx context.Context, database *mongo.Database) error {
+	auctions := database.Collection("auctions")
+	missing := bson.M{"$or": bson.A{
+		bson.M{"highest_bid": bson.M{"$exists": false}},
+		bson.M{"bid_count": bson.M{"$exists": false}},
+	}}
+
+	cursor, err := auctions.Find(ctx, missing, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+
+	var legacy []struct {
+		Id string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &legacy); err != nil {
+		return err
+	}
+
+	for _, auction := range legacy {
+		cursor, err := database.Collection("bids").Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"auction_id": auction.Id}}},
+			{{Key: "$group", Value: bson.M{
+				"_id":         nil,
+				"highest_bid": bson.M{"$max": "$amount"},
+				"bid_count":   bson.M{"$sum": 1},
+			}}},
+		})
+		if err != nil {
+			return err
+		}
+
+		var stats []struct {
+			HighestBid float64 `bson:"highest_bid"`
+			BidCount   int64   `bson:"bid_count"`
+		}
+		if err := cursor.All(ctx, &stats); err != nil {
+			return err
+		}
+
+		update := bson.M{"highest_bid": 0.0, "bid_count": int64(0)}
+		if len(stats) > 0 {
+			update = bson.M{"highest_bid": stats[0].HighestBid, "bid_count": stats[0].BidCount}
+		}
+		if _, err := auctions.UpdateByID(ctx, auction.Id, bson.M{"$set": update}); err != nil {
+			return err
+		}
+	}
+
+	logBackfill("the bid stats of", "auctions", int64(len(legacy)))
+	return nil
+}
+
+// defaultUserStatus marks users stored before they had a status as active.
+func defaultUserStatus(ctx context.Context, database *mongo.Database) error {
+	result, err := database.Collection("users").UpdateMany(ctx,
+		bson.M{"status": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"status": "active"}})
+	if err != nil {
+		return err
+	}
+
+	logBackfill("the status of", "users", result.ModifiedCount)
+	return nil
+}
+
+func logBackfill(field, collection string, count int64) {
+	if count > 0 {
+		logger.Info(fmt.Sprintf("Backfilled %s %d %s", field, count, collection))
+	}
+}
@@ -0,0 +1,291 @@
+// Package migration applies versioned changes to the MongoDB collections,
+// such as their indexes and the shape of legacy documents. Applied versions
+// are recorded in the migrations collection, and a lease keeps instances
+// starting together from running them concurrently.
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/infra/database/lease"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	lockName          = "schema-migrations"
+	lockTTL           = time.Minute
+	lockRetryInterval = time.Second
+)
+
+var errLockLost = errors.New("lost the migration lock")
+
+// Migration changes the database from Version-1 to Version with Up, and back
+// with Down. Released migrations must not change; later changes go in new
+// migrations.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, database *mongo.Database) error
+	Down        func(ctx context.Context, database *mongo.Database) error
+}
+
+type Status struct {
+	Version     int        `json:"version"`
+	Description string     `json:"description"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+}
+
+type MigrationMongo struct {
+	Version     int    `bson:"_id"`
+	Description string `bson:"description"`
+	AppliedAt   int64  `bson:"applied_at"`
+}
+
+type Runner struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+	leases     *lease.LeaseRepository
+	migrations []Migration
+	holderId   string
+	clock      clock.Clock
+}
+
+// NewRunner returns a runner of the migrations shipped with the application.
+// holderId identifies the caller while it holds the migration lock.
+func NewRunner(database *mongo.Database, clock clock.Clock, holderId string) *Runner {
+	return newRunner(database, clock, holderId, migrations)
+}
+
+func newRunner(database *mongo.Database, clock clock.Clock, holderId string, migrations []Migration) *Runner {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	return &Runner{
+		database:   database,
+		collection: database.Collection("migrations"),
+		leases:     lease.NewLeaseRepository(database, clock),
+		migrations: sorted,
+		holderId:   holderId,
+		clock:      clock,
+	}
+}
+
+// Status lists every known migration and when it was applied, along with
+// versions applied by a newer release of the application.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(r.migrations))
+	for _, migration := range r.migrations {
+		status := Status{Version: migration.Version, Description: migration.Description}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := time.UnixMilli(record.AppliedAt)
+			status.AppliedAt = &appliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, record := range applied {
+		appliedAt := time.UnixMilli(record.AppliedAt)
+		statuses = append(statuses, Status{
+			Version:     record.Version,
+			Description: record.Description,
+			AppliedAt:   &appliedAt,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Up applies, in order, every migration not applied yet and returns them.
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := r.withLock(ctx, func(ctx context.Context) error {
+		applied, err := r.applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range r.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			if err := migration.Up(ctx, r.database); err != nil {
+				return fmt.Errorf("applying migration %d (%s): %w", migration.Version, migration.Description, err)
+			}
+
+			record := MigrationMongo{
+				Version:     migration.Version,
+				Description: migration.Description,
+				AppliedAt:   r.clock.Now().UnixMilli(),
+			}
+			if _, err := r.collection.InsertOne(ctx, record); err != nil {
+				return fmt.Errorf("recording migration %d: %w", migration.Version, err)
+			}
+
+			logger.Info(fmt.Sprintf("Applied migration %d (%s)", migration.Version, migration.Description))
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down reverts the last steps applied migrations, latest first, and returns
+// them.
+func (r *Runner) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := r.withLock(ctx, func(ctx context.Context) error {
+		applied, err := r.applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+		for _, version := range versions {
+			if len(done) == steps {
+				break
+			}
+
+			migration, ok := r.find(version)
+			if !ok {
+				return fmt.Errorf("migration %d was applied by a newer release and cannot be reverted", version)
+			}
+
+			if err := migration.Down(ctx, r.database); err != nil {
+				return fmt.Errorf("reverting migration %d (%s): %w", migration.Version, migration.Description, err)
+			}
+
+			if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": version}); err != nil {
+				return fmt.Errorf("recording the revert of migration %d: %w", version, err)
+			}
+
+			logger.Info(fmt.Sprintf("Reverted migration %d (%s)", migration.Version, migration.Description))
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+func (r *Runner) find(version int) (Migration, bool) {
+	for _, migration := range r.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+func (r *Runner) applied(ctx context.Context) (map[int]MigrationMongo, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("finding applied migrations: %w", err)
+	}
+
+	var records []MigrationMongo
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("decoding applied migrations: %w", err)
+	}
+
+	applied := make(map[int]MigrationMongo, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// withLock runs run while holding the migration lease, renewing it in the
+// background. Should a renewal fail, the context given to run is cancelled so
+// no migration keeps going without the lock.
+func (r *Runner) withLock(ctx context.Context, run func(ctx context.Context) error) error {
+	held, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.leases.ReleaseLease(context.Background(), lockName, r.holderId, held.Token)
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		r.keepLock(runCtx, held, cancel)
+	}()
+
+	err = run(runCtx)
+	if err != nil && errors.Is(context.Cause(runCtx), errLockLost) {
+		err = fmt.Errorf("%w: %v", errLockLost, err)
+	}
+	cancel(nil)
+	<-renewed
+
+	return err
+}
+
+// lock waits until this runner holds the migration lease, while another
+// instance finishes its migrations.
+func (r *Runner) lock(ctx context.Context) (*lease_entity.Lease, error) {
+	for {
+		held, isHolder, err := r.leases.AcquireLease(ctx, lockName, r.holderId, lockTTL)
+		if err != nil {
+			return nil, err
+		}
+		if isHolder {
+			return held, nil
+		}
+
+		logger.Info(fmt.Sprintf("Waiting for %s to finish migrating", held.HolderId))
+
+		timer := r.clock.NewTimer(lockRetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+func (r *Runner) keepLock(ctx context.Context, held *lease_entity.Lease, cancel context.CancelCauseFunc) {
+	timer := r.clock.NewTimer(lockTTL / 3)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+		}
+
+		renewed, isHolder, err := r.leases.AcquireLease(ctx, lockName, r.holderId, lockTTL)
+		if err != nil || !isHolder || renewed.Token != held.Token {
+			cancel(errLockLost)
+			return
+		}
+		timer.Reset(lockTTL / 3)
+	}
+}
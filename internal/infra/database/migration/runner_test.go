@@ -0,0 +1,139 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/clock"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestRunnerUpDownAndLock(t *testing.T) {
+	mongoURL := os.Getenv("MONGODB_URL")
+	if mongoURL == "" {
+		t.Skip("MONGODB_URL is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURL))
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database(fmt.Sprintf("auctions_migration_%d", time.Now().UnixNano()))
+	defer database.Drop(ctx)
+
+	var mutex sync.Mutex
+	var calls []string
+	step := func(name string) func(context.Context, *mongo.Database) error {
+		return func(context.Context, *mongo.Database) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	steps := []Migration{
+		{Version: 2, Description: "second", Up: step("up 2"), Down: step("down 2")},
+		{Version: 1, Description: "first", Up: step("up 1"), Down: step("down 1")},
+	}
+
+	// Instances starting together apply every migration once.
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		runner := newRunner(database, clock.Real, fmt.Sprintf("instance-%d", i), steps)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := runner.Up(ctx); err != nil {
+				t.Errorf("Up: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if fmt.Sprint(calls) != "[up 1 up 2]" {
+		t.Fatalf("expected each migration applied once in order, got %v", calls)
+	}
+
+	runner := newRunner(database, clock.Real, "instance-0", steps)
+	statuses, err := runner.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].AppliedAt == nil || statuses[1].AppliedAt == nil {
+		t.Fatalf("expected both migrations applied, got %+v", statuses)
+	}
+
+	reverted, err := runner.Down(ctx, 1)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Fatalf("expected the latest migration reverted, got %+v", reverted)
+	}
+
+	statuses, _ = runner.Status(ctx)
+	if statuses[0].AppliedAt == nil || statuses[1].AppliedAt != nil {
+		t.Errorf("expected only the first migration applied, got %+v", statuses)
+	}
+
+	// A release that knows fewer migrations refuses to revert newer ones.
+	older := newRunner(database, clock.Real, "instance-0", nil)
+	if _, err := older.Down(ctx, 1); err == nil {
+		t.Errorf("expected an unknown migration not to be reverted")
+	}
+}
+
+func TestShippedMigrations(t *testing.T) {
+	mongoURL := os.Getenv("MONGODB_URL")
+	if mongoURL == "" {
+		t.Skip("MONGODB_URL is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURL))
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database(fmt.Sprintf("auctions_migration_%d", time.Now().UnixNano()))
+	defer database.Drop(ctx)
+
+	if _, err := database.Collection("auctions").InsertOne(ctx, map[string]interface{}{
+		"_id": "legacy", "timestamp": time.Now().Unix(), "status": "active"}); err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+
+	runner := NewRunner(database, clock.Real, "instance-0")
+	if _, err := runner.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	var legacy struct {
+		StartsAt   int64   `bson:"starts_at"`
+		EndTime    int64   `bson:"end_time"`
+		HighestBid float64 `bson:"highest_bid"`
+		BidCount   int64   `bson:"bid_count"`
+	}
+	if err := database.Collection("auctions").FindOne(ctx, map[string]string{"_id": "legacy"}).Decode(&legacy); err != nil {
+		t.Fatalf("FindOne: %v", err)
+	}
+	if legacy.StartsAt == 0 || legacy.EndTime <= legacy.StartsAt {
+		t.Errorf("expected the legacy auction backfilled, got %+v", legacy)
+	}
+
+	if _, err := runner.Down(ctx, len(migrations)); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if _, err := runner.Up(ctx); err != nil {
+		t.Fatalf("Up after Down: %v", err)
+	}
+}
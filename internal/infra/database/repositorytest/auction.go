@@ -0,0 +1,596 @@
+package repositorytest
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testAuctionCreateAndFind(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	auction := newAuction(t, "Vintage Camera", 0)
+	auction.Category = "Cameras"
+	auction.Attributes = map[string]interface{}{"brand": "Acme", "year": float64(1980)}
+	mustCreateAuction(t, repositories, auction)
+
+	found, err := repositories.Auctions.FindAuctionById(ctx, auction.Id)
+	if err != nil {
+		t.Fatalf("FindAuctionById: %v", err)
+	}
+
+	if found.ProductName != auction.ProductName || found.CategoryId != auction.CategoryId ||
+		found.Category != "Cameras" || found.Status != auction_entity.Active {
+		t.Errorf("unexpected auction %+v", found)
+	}
+	if found.Timestamp.Unix() != auction.Timestamp.Unix() {
+		t.Errorf("expected timestamp %v, got %v", auction.Timestamp, found.Timestamp)
+	}
+	if found.Attributes["brand"] != "Acme" || found.Attributes["year"] != float64(1980) {
+		t.Errorf("unexpected attributes %v", found.Attributes)
+	}
+
+	_, err = repositories.Auctions.FindAuctionById(ctx, uuid.New().String())
+	expectErr(t, err, "not_found")
+}
+
+func testAuctionCreateBatch(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	batch := []*auction_entity.Auction{
+		newAuction(t, "Vintage Radio", 0),
+		newAuction(t, "Vintage Clock", 0),
+		newAuction(t, "Vintage Lamp", 0),
+	}
+	batch[1].Attributes = map[string]interface{}{"brand": "Acme"}
+	if err := repositories.Auctions.CreateAuctions(ctx, batch); err != nil {
+		t.Fatalf("CreateAuctions: %v", err)
+	}
+
+	for _, auction := range batch {
+		found, err := repositories.Auctions.FindAuctionById(ctx, auction.Id)
+		if err != nil {
+			t.Fatalf("FindAuctionById %s: %v", auction.ProductName, err)
+		}
+		if found.ProductName != auction.ProductName || found.Status != auction_entity.Active {
+			t.Errorf("unexpected auction %+v", found)
+		}
+	}
+
+	fresh := newAuction(t, "Vintage Phone", 0)
+	if err := repositories.Auctions.CreateAuctions(ctx, []*auction_entity.Auction{fresh, batch[0]}); err == nil {
+		t.Fatalf("expected a batch repeating a stored auction to fail")
+	}
+
+	_, err := repositories.Auctions.FindAuctionById(ctx, fresh.Id)
+	expectErr(t, err, "not_found")
+	if _, err := repositories.Auctions.FindAuctionById(ctx, batch[0].Id); err != nil {
+		t.Errorf("expected the stored auction to be kept, got %v", err)
+	}
+}
+
+func testAuctionFilters(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	phone := newAuction(t, "Smartphone X", 3*time.Hour)
+	phone.Attributes = map[string]interface{}{"brand": "Acme", "year": float64(2021)}
+	mustCreateAuction(t, repositories, phone)
+
+	oldPhone := newAuction(t, "Smartphone Classic", 2*time.Hour)
+	oldPhone.CategoryId = phone.CategoryId
+	oldPhone.Attributes = map[string]interface{}{"brand": "Acme", "year": float64(2015)}
+	mustCreateAuction(t, repositories, oldPhone)
+
+	lamp := newAuction(t, "Desk Lamp", time.Hour)
+	mustCreateAuction(t, repositories, lamp)
+
+	if _, err := repositories.Auctions.CancelAuction(ctx, lamp.Id); err != nil {
+		t.Fatalf("CancelAuction: %v", err)
+	}
+
+	active := auction_entity.Active
+	createdFrom := time.Now().Add(-150 * time.Minute)
+
+	cases := map[string]struct {
+		filter auction_entity.AuctionFilter
+		want   []string
+	}{
+		"status": {
+			filter: auction_entity.AuctionFilter{Status: &active},
+			want:   []string{oldPhone.Id, phone.Id},
+		},
+		"category": {
+			filter: auction_entity.AuctionFilter{CategoryIds: []string{phone.CategoryId}},
+			want:   []string{oldPhone.Id, phone.Id},
+		},
+		"product name": {
+			filter: auction_entity.AuctionFilter{ProductName: "smartphone"},
+			want:   []string{oldPhone.Id, phone.Id},
+		},
+		"created from": {
+			filter: auction_entity.AuctionFilter{CreatedFrom: &createdFrom},
+			want:   []string{lamp.Id, oldPhone.Id},
+		},
+		"attribute equality": {
+			filter: auction_entity.AuctionFilter{Attributes: []auction_entity.AttributeFilter{
+				{Key: "brand", Operator: auction_entity.AttributeEq, Values: []interface{}{"Acme"}},
+			}},
+			want: []string{oldPhone.Id, phone.Id},
+		},
+		"attribute range": {
+			filter: auction_entity.AuctionFilter{Attributes: []auction_entity.AttributeFilter{
+				{Key: "year", Operator: auction_entity.AttributeGte, Values: []interface{}{float64(2019)}},
+			}},
+			want: []string{phone.Id},
+		},
+	}
+
+	for name, testCase := range cases {
+		testCase.filter.Sort = auction_entity.SortNewest
+		testCase.filter.Limit = 10
+
+		page, err := repositories.Auctions.FindAuctions(ctx, testCase.filter)
+		if err != nil {
+			t.Fatalf("%s: FindAuctions: %v", name, err)
+		}
+
+		assertAuctionIds(t, name, page.Auctions, testCase.want)
+		if page.Total != int64(len(testCase.want)) {
+			t.Errorf("%s: expected total %d, got %d", name, len(testCase.want), page.Total)
+		}
+	}
+}
+
+func testAuctionCursorPagination(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	var want []string
+	for i := 0; i < 5; i++ {
+		auction := mustCreateAuction(t, repositories, newAuction(t, "Item", time.Duration(i)*time.Minute))
+		want = append(want, auction.Id)
+	}
+
+	var got []auction_entity.Auction
+	filter := auction_entity.AuctionFilter{Sort: auction_entity.SortNewest, Limit: 2}
+	for pages := 0; pages < 5; pages++ {
+		page, err := repositories.Auctions.FindAuctions(ctx, filter)
+		if err != nil {
+			t.Fatalf("FindAuctions: %v", err)
+		}
+		if page.Total != 5 {
+			t.Errorf("expected total 5, got %d", page.Total)
+		}
+
+		got = append(got, page.Auctions...)
+		if page.NextCursor == "" {
+			break
+		}
+		filter.After = page.NextCursor
+	}
+
+	assertAuctionIdsInOrder(t, "pages", got, want)
+
+	filter.After = "not-a-cursor"
+	_, err := repositories.Auctions.FindAuctions(ctx, filter)
+	expectErr(t, err, "bad_request")
+}
+
+func testAuctionTextSearch(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	camera := mustCreateAuction(t, repositories, newAuction(t, "Vintage Camera", 0))
+	lens := newAuction(t, "Zoom Lens", 0)
+	lens.Description = "Fits most vintage camera bodies"
+	mustCreateAuction(t, repositories, lens)
+	mustCreateAuction(t, repositories, newAuction(t, "Desk Lamp", 0))
+
+	page, err := repositories.Auctions.FindAuctions(ctx, auction_entity.AuctionFilter{
+		Query: "camera",
+		Sort:  auction_entity.SortRelevance,
+		Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("FindAuctions: %v", err)
+	}
+
+	assertAuctionIdsInOrder(t, "relevance", page.Auctions, []string{camera.Id, lens.Id})
+}
+
+func testAuctionRenameCategory(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	camera := newAuction(t, "Vintage Camera", 0)
+	camera.Category = "Cameras"
+	mustCreateAuction(t, repositories, camera)
+
+	lens := newAuction(t, "Zoom Lens", 0)
+	lens.CategoryId = camera.CategoryId
+	lens.Category = "Cameras"
+	mustCreateAuction(t, repositories, lens)
+
+	lamp := newAuction(t, "Desk Lamp", 0)
+	lamp.Category = "Lighting"
+	mustCreateAuction(t, repositories, lamp)
+
+	if err := repositories.Auctions.RenameCategory(ctx, camera.CategoryId, "Photography"); err != nil {
+		t.Fatalf("RenameCategory: %v", err)
+	}
+
+	for _, auction := range []*auction_entity.Auction{camera, lens} {
+		found, err := repositories.Auctions.FindAuctionById(ctx, auction.Id)
+		if err != nil {
+			t.Fatalf("FindAuctionById: %v", err)
+		}
+		if found.Category != "Photography" {
+			t.Errorf("expected %s to be in Photography, got %q", auction.ProductName, found.Category)
+		}
+	}
+
+	found, err := repositories.Auctions.FindAuctionById(ctx, lamp.Id)
+	if err != nil {
+		t.Fatalf("FindAuctionById: %v", err)
+	}
+	if found.Category != "Lighting" {
+		t.Errorf("expected the lamp to keep its category, got %q", found.Category)
+	}
+
+	page, err := repositories.Auctions.FindAuctions(ctx, auction_entity.AuctionFilter{
+		Query: "photography",
+		Sort:  auction_entity.SortNewest,
+		Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("FindAuctions: %v", err)
+	}
+	assertAuctionIds(t, "renamed category search", page.Auctions, []string{camera.Id, lens.Id})
+}
+
+func testAuctionSuggestProductNames(t *testing.T, repositories Repositories) {
+	for _, productName := range []string{"Smartphone X", "Smartphone X", "smart watch", "Laptop"} {
+		mustCreateAuction(t, repositories, newAuction(t, productName, 0))
+	}
+
+	suggestions, err := repositories.Auctions.SuggestProductNames(context.Background(), "SMART", 10)
+	if err != nil {
+		t.Fatalf("SuggestProductNames: %v", err)
+	}
+
+	if len(suggestions) != 2 || suggestions[0] != "Smartphone X" || suggestions[1] != "smart watch" {
+		t.Errorf("unexpected suggestions %v", suggestions)
+	}
+}
+
+func testAuctionImages(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	auction := mustCreateAuction(t, repositories, newAuction(t, "Painting", 0))
+
+	for i := 0; i < 2; i++ {
+		image := auction_entity.AuctionImage{Id: uuid.New().String(), Key: "key", Timestamp: time.Now()}
+		if err := repositories.Auctions.AddAuctionImage(ctx, auction.Id, image, 2); err != nil {
+			t.Fatalf("AddAuctionImage: %v", err)
+		}
+	}
+
+	err := repositories.Auctions.AddAuctionImage(ctx, auction.Id, auction_entity.AuctionImage{Id: "extra"}, 2)
+	expectErr(t, err, "bad_request")
+
+	found, _ := repositories.Auctions.FindAuctionById(ctx, auction.Id)
+	if len(found.Images) != 2 {
+		t.Fatalf("expected 2 images, got %d", len(found.Images))
+	}
+
+	if err := repositories.Auctions.RemoveAuctionImage(ctx, auction.Id, found.Images[0].Id); err != nil {
+		t.Fatalf("RemoveAuctionImage: %v", err)
+	}
+
+	err = repositories.Auctions.RemoveAuctionImage(ctx, auction.Id, found.Images[0].Id)
+	expectErr(t, err, "not_found")
+}
+
+func testAuctionCancel(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	auction := mustCreateAuction(t, repositories, newAuction(t, "Guitar", 0))
+
+	image := auction_entity.AuctionImage{Id: uuid.New().String(), Key: "key", Timestamp: time.Now()}
+	if err := repositories.Auctions.AddAuctionImage(ctx, auction.Id, image, 10); err != nil {
+		t.Fatalf("AddAuctionImage: %v", err)
+	}
+
+	cancelled, err := repositories.Auctions.CancelAuction(ctx, auction.Id)
+	if err != nil {
+		t.Fatalf("CancelAuction: %v", err)
+	}
+	if len(cancelled.Images) != 1 {
+		t.Errorf("expected the cancelled auction to report its images, got %d", len(cancelled.Images))
+	}
+
+	found, _ := repositories.Auctions.FindAuctionById(ctx, auction.Id)
+	if found.Status != auction_entity.Cancelled || len(found.Images) != 0 {
+		t.Errorf("expected cancelled auction without images, got %+v", found)
+	}
+
+	_, err = repositories.Auctions.CancelAuction(ctx, auction.Id)
+	expectErr(t, err, "bad_request")
+
+	_, err = repositories.Auctions.CancelAuction(ctx, uuid.New().String())
+	expectErr(t, err, "not_found")
+}
+
+// testAuctionClosure pins that closure is driven by the end time of each
+// auction, not by its creation time.
+func testAuctionClosure(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	expired := mustCreateAuction(t, repositories, newAuction(t, "Old Item", 24*time.Hour))
+	fresh := mustCreateAuction(t, repositories, newAuction(t, "New Item", 0))
+
+	repositories.CloseExpiredAuctions()
+
+	found, _ := repositories.Auctions.FindAuctionById(ctx, expired.Id)
+	if found.Status != auction_entity.Completed {
+		t.Errorf("expected expired auction to be closed, got status %d", found.Status)
+	}
+
+	found, _ = repositories.Auctions.FindAuctionById(ctx, fresh.Id)
+	if found.Status != auction_entity.Active {
+		t.Errorf("expected fresh auction to stay active, got status %d", found.Status)
+	}
+}
+
+func testAuctionExtendAndClose(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	open := mustCreateAuction(t, repositories, newAuction(t, "Open", 0))
+	endTime := open.EndTime.Add(time.Hour)
+
+	extended, err := repositories.Auctions.ExtendAuction(ctx, open.Id, endTime)
+	if err != nil {
+		t.Fatalf("ExtendAuction: %v", err)
+	}
+	if extended.EndTime.UnixMilli() != endTime.UnixMilli() {
+		t.Errorf("expected end time %v, got %v", endTime, extended.EndTime)
+	}
+
+	found, _ := repositories.Auctions.FindAuctionById(ctx, open.Id)
+	if found.EndTime.UnixMilli() != endTime.UnixMilli() {
+		t.Errorf("expected stored end time %v, got %v", endTime, found.EndTime)
+	}
+
+	_, err = repositories.Auctions.ExtendAuction(ctx, open.Id, open.EndTime)
+	expectErr(t, err, "bad_request")
+
+	_, err = repositories.Auctions.ExtendAuction(ctx, uuid.New().String(), endTime)
+	expectErr(t, err, "not_found")
+
+	if closed, err := repositories.Auctions.CloseAuction(ctx, open.Id, lease_entity.Fence{}); err != nil || closed {
+		t.Errorf("expected an auction before its end time to stay open, got %v (%v)", closed, err)
+	}
+
+	ending := newAuction(t, "Ending", 0)
+	ending.EndTime = repositories.Clock.Now().Add(100 * time.Millisecond)
+	mustCreateAuction(t, repositories, ending)
+	repositories.Clock.Advance(150 * time.Millisecond)
+
+	_, err = repositories.Auctions.ExtendAuction(ctx, ending.Id, repositories.Clock.Now().Add(time.Hour))
+	expectErr(t, err, "bad_request")
+
+	if closed, err := repositories.Auctions.CloseAuction(ctx, ending.Id, lease_entity.Fence{}); err != nil || !closed {
+		t.Fatalf("expected the ended auction to close, got %v (%v)", closed, err)
+	}
+	if closed, _ := repositories.Auctions.CloseAuction(ctx, ending.Id, lease_entity.Fence{}); closed {
+		t.Errorf("expected an auction to close only once")
+	}
+
+	found, _ = repositories.Auctions.FindAuctionById(ctx, ending.Id)
+	if found.Status != auction_entity.Completed {
+		t.Errorf("expected ended auction to be closed, got status %d", found.Status)
+	}
+
+	endTimes, err := repositories.Auctions.FindAuctionEndTimes(ctx)
+	if err != nil {
+		t.Fatalf("FindAuctionEndTimes: %v", err)
+	}
+	if len(endTimes) != 1 || endTimes[0].AuctionId != open.Id ||
+		endTimes[0].EndTime.UnixMilli() != endTime.UnixMilli() {
+		t.Errorf("expected only the open auction ending at %v, got %+v", endTime, endTimes)
+	}
+}
+
+func testAuctionFencedClosure(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	const name = "auction-closure"
+
+	if _, held, err := repositories.Leases.AcquireLease(ctx, name, "first", time.Minute); err != nil || !held {
+		t.Fatalf("expected first to acquire the lease, got %v %v", held, err)
+	}
+	if err := repositories.Leases.ReleaseLease(ctx, name, "first", 1); err != nil {
+		t.Fatalf("ReleaseLease: %v", err)
+	}
+	if _, held, err := repositories.Leases.AcquireLease(ctx, name, "second", time.Minute); err != nil || !held {
+		t.Fatalf("expected second to take over the lease, got %v %v", held, err)
+	}
+
+	ended := mustCreateAuction(t, repositories, newAuction(t, "Ended", 24*time.Hour))
+
+	// first was deposed when second took the lease with token 2.
+	for _, fence := range []lease_entity.Fence{{Lease: name, Token: 1}, {Lease: "unknown", Token: 2}} {
+		if closed, err := repositories.Auctions.CloseAuction(ctx, ended.Id, fence); err != nil || closed {
+			t.Errorf("expected a closure under %+v to be refused, got %v (%v)", fence, closed, err)
+		}
+	}
+	found, _ := repositories.Auctions.FindAuctionById(ctx, ended.Id)
+	if found.Status != auction_entity.Active {
+		t.Fatalf("expected a refused closure to leave the auction active, got status %d", found.Status)
+	}
+
+	closed, err := repositories.Auctions.CloseAuction(ctx, ended.Id, lease_entity.Fence{Lease: name, Token: 2})
+	if err != nil || !closed {
+		t.Fatalf("expected the current leader to close the auction, got %v (%v)", closed, err)
+	}
+}
+
+func testAuctionForceClose(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	auction := mustCreateAuction(t, repositories, newAuction(t, "Lamp", 0))
+
+	closed, err := repositories.Auctions.ForceCloseAuction(ctx, auction.Id)
+	if err != nil {
+		t.Fatalf("ForceCloseAuction: %v", err)
+	}
+	if closed.Status != auction_entity.Completed || closed.EndTime.After(repositories.Clock.Now()) {
+		t.Errorf("expected auction closed now, got %+v", closed)
+	}
+
+	found, _ := repositories.Auctions.FindAuctionById(ctx, auction.Id)
+	if found.Status != auction_entity.Completed || found.EndTime.UnixMilli() != closed.EndTime.UnixMilli() {
+		t.Errorf("expected stored auction closed at %v, got %+v", closed.EndTime, found)
+	}
+
+	_, err = repositories.Auctions.ForceCloseAuction(ctx, auction.Id)
+	expectErr(t, err, "bad_request")
+
+	_, err = repositories.Auctions.ForceCloseAuction(ctx, uuid.New().String())
+	expectErr(t, err, "not_found")
+
+	if err := repositories.Auctions.UpdateBidStats(ctx, auction.Id, 120, 3); err != nil {
+		t.Fatalf("UpdateBidStats: %v", err)
+	}
+	found, _ = repositories.Auctions.FindAuctionById(ctx, auction.Id)
+	if found.HighestBid != 120 || found.BidCount != 3 {
+		t.Errorf("expected highest bid 120 over 3 bids, got %v over %d", found.HighestBid, found.BidCount)
+	}
+
+	err = repositories.Auctions.UpdateBidStats(ctx, uuid.New().String(), 1, 1)
+	expectErr(t, err, "not_found")
+}
+
+func testAuctionSchedule(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	userId := uuid.New().String()
+
+	newScheduled := func(productName string, startsIn time.Duration) *auction_entity.Auction {
+		auction := newAuction(t, productName, 0)
+		auction.Status = auction_entity.Scheduled
+		auction.StartsAt = time.Now().Add(startsIn)
+		auction.EndTime = auction.StartsAt.Add(10 * time.Minute)
+		return mustCreateAuction(t, repositories, auction)
+	}
+
+	later := newScheduled("Later", 2*time.Hour)
+	sooner := newScheduled("Sooner", time.Hour)
+	mustCreateAuction(t, repositories, newAuction(t, "Open", 0))
+
+	state, err := repositories.Auctions.FindAuctionState(ctx, sooner.Id)
+	if err != nil {
+		t.Fatalf("FindAuctionState: %v", err)
+	}
+	if state.Status != auction_entity.Scheduled || state.StartsAt.UnixMilli() != sooner.StartsAt.UnixMilli() {
+		t.Errorf("expected scheduled state starting at %v, got %+v", sooner.StartsAt, state)
+	}
+
+	_, err = repositories.Auctions.FindAuctionState(ctx, uuid.New().String())
+	expectErr(t, err, "not_found")
+
+	scheduled := auction_entity.Scheduled
+	page, err := repositories.Auctions.FindAuctions(ctx, auction_entity.AuctionFilter{
+		Status: &scheduled,
+		Sort:   auction_entity.SortStartingSoonest,
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("FindAuctions: %v", err)
+	}
+	assertAuctionIdsInOrder(t, "upcoming", page.Auctions, []string{sooner.Id, later.Id})
+
+	// Only the scheduled auctions starting by the cutoff are overdue; the
+	// active one never is, whatever its start time.
+	for _, expected := range []struct {
+		cutoff time.Time
+		ids    []string
+	}{
+		{time.Now(), nil},
+		{sooner.StartsAt, []string{sooner.Id}},
+		{later.StartsAt.Add(time.Minute), []string{sooner.Id, later.Id}},
+	} {
+		overdue, err := repositories.Auctions.FindOverdueScheduledAuctions(ctx, expected.cutoff)
+		if err != nil {
+			t.Fatalf("FindOverdueScheduledAuctions: %v", err)
+		}
+		sort.Strings(overdue)
+		want := append([]string(nil), expected.ids...)
+		sort.Strings(want)
+		if !reflect.DeepEqual(overdue, want) {
+			t.Errorf("expected %v overdue by %v, got %v", want, expected.cutoff, overdue)
+		}
+	}
+
+	results := mustCreateBids(t, repositories, newBid(userId, sooner.Id, 10, 0))
+	if results[0].Status != bid_entity.BidRejected {
+		t.Errorf("expected bid on a scheduled auction to be rejected, got status %d", results[0].Status)
+	}
+
+	if started, err := repositories.Auctions.StartAuction(ctx, sooner.Id); err != nil || !started {
+		t.Fatalf("expected the scheduled auction to start, got %v (%v)", started, err)
+	}
+	if started, _ := repositories.Auctions.StartAuction(ctx, sooner.Id); started {
+		t.Errorf("expected an auction to start only once")
+	}
+
+	state, _ = repositories.Auctions.FindAuctionState(ctx, sooner.Id)
+	if state.Status != auction_entity.Active {
+		t.Errorf("expected started auction to be active, got status %d", state.Status)
+	}
+
+	results = mustCreateBids(t, repositories, newBid(userId, sooner.Id, 10, 0))
+	if results[0].Status != bid_entity.BidStored {
+		t.Errorf("expected bid on a started auction to be stored, got status %d", results[0].Status)
+	}
+
+	if _, err := repositories.Auctions.CancelAuction(ctx, later.Id); err != nil {
+		t.Fatalf("CancelAuction of a scheduled auction: %v", err)
+	}
+	if started, _ := repositories.Auctions.StartAuction(ctx, later.Id); started {
+		t.Errorf("expected a cancelled auction not to start")
+	}
+}
+
+func assertAuctionIds(t *testing.T, name string, auctions []auction_entity.Auction, want []string) {
+	t.Helper()
+
+	got := map[string]bool{}
+	for _, auction := range auctions {
+		got[auction.Id] = true
+	}
+
+	if len(auctions) != len(want) || len(got) != len(want) {
+		t.Errorf("%s: expected %d auctions, got %d", name, len(want), len(auctions))
+		return
+	}
+
+	for _, id := range want {
+		if !got[id] {
+			t.Errorf("%s: missing auction %s", name, id)
+		}
+	}
+}
+
+func assertAuctionIdsInOrder(t *testing.T, name string, auctions []auction_entity.Auction, want []string) {
+	t.Helper()
+
+	if len(auctions) != len(want) {
+		t.Fatalf("%s: expected %d auctions, got %d", name, len(want), len(auctions))
+	}
+
+	for i, id := range want {
+		if auctions[i].Id != id {
+			t.Errorf("%s: expected auction %s at position %d, got %s", name, id, i, auctions[i].Id)
+		}
+	}
+}
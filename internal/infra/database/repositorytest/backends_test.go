@@ -0,0 +1,151 @@
+package repositorytest_test
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/infra/database/auction"
+	"fullcycle-auction_go/internal/infra/database/bid"
+	"fullcycle-auction_go/internal/infra/database/category"
+	"fullcycle-auction_go/internal/infra/database/job"
+	"fullcycle-auction_go/internal/infra/database/lease"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"fullcycle-auction_go/internal/infra/database/migration"
+	"fullcycle-auction_go/internal/infra/database/repositorytest"
+	"fullcycle-auction_go/internal/infra/database/sqldb"
+	"fullcycle-auction_go/internal/infra/database/user"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		fake := clock.NewFake(time.Now())
+		auctionRepository := memory.NewAuctionRepository(fake)
+		leaseRepository := memory.NewLeaseRepository(fake)
+		auctionRepository.UseLeases(leaseRepository)
+
+		return repositorytest.Repositories{
+			Auctions:             auctionRepository,
+			Bids:                 memory.NewBidRepository(auctionRepository),
+			Users:                memory.NewUserRepository(),
+			Categories:           memory.NewCategoryRepository(),
+			Leases:               leaseRepository,
+			Jobs:                 memory.NewJobRepository(),
+			Clock:                fake,
+			CloseExpiredAuctions: auctionRepository.CloseExpiredAuctions,
+		}
+	})
+}
+
+func TestMongoRepositories(t *testing.T) {
+	mongoURL := os.Getenv("MONGODB_URL")
+	if mongoURL == "" {
+		t.Skip("MONGODB_URL is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURL))
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		database := client.Database(fmt.Sprintf("auctions_contract_%d", time.Now().UnixNano()))
+		t.Cleanup(func() { database.Drop(context.Background()) })
+
+		if _, err := migration.NewRunner(database, clock.Real, "contract").Up(ctx); err != nil {
+			t.Fatalf("Failed to migrate MongoDB: %v", err)
+		}
+
+		fake := clock.NewFake(time.Now())
+		auctionRepository := auction.NewAuctionRepository(database, fake)
+
+		return repositorytest.Repositories{
+			Auctions:             auctionRepository,
+			Bids:                 bid.NewBidRepository(database, auctionRepository, fake),
+			Users:                user.NewUserRepository(database),
+			Categories:           category.NewCategoryRepository(database),
+			Leases:               lease.NewLeaseRepository(database, fake),
+			Jobs:                 job.NewJobRepository(database),
+			Clock:                fake,
+			CloseExpiredAuctions: auctionRepository.CloseExpiredAuctions,
+		}
+	})
+}
+
+func TestSQLiteRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		database, err := sqldb.Open(context.Background(), sqldb.SQLite,
+			filepath.Join(t.TempDir(), "auction.db"))
+		if err != nil {
+			t.Fatalf("Failed to open SQLite database: %v", err)
+		}
+		t.Cleanup(func() { database.Close() })
+
+		return sqlRepositories(database)
+	})
+}
+
+func TestPostgresRepositories(t *testing.T) {
+	postgresDSN := os.Getenv("POSTGRES_DSN")
+	if postgresDSN == "" {
+		t.Skip("POSTGRES_DSN is not set")
+	}
+
+	ctx := context.Background()
+	admin, err := sqldb.Open(ctx, sqldb.Postgres, postgresDSN)
+	if err != nil {
+		t.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer admin.Close()
+
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		schema := fmt.Sprintf("auctions_contract_%d", time.Now().UnixNano())
+		if _, err := admin.DB.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+			t.Fatalf("Failed to create schema: %v", err)
+		}
+		t.Cleanup(func() { admin.DB.ExecContext(context.Background(), "DROP SCHEMA "+schema+" CASCADE") })
+
+		database, err := sqldb.Open(ctx, sqldb.Postgres, withSearchPath(postgresDSN, schema))
+		if err != nil {
+			t.Fatalf("Failed to open PostgreSQL schema: %v", err)
+		}
+		t.Cleanup(func() { database.Close() })
+
+		return sqlRepositories(database)
+	})
+}
+
+// withSearchPath points a PostgreSQL DSN, in URL or key/value form, at schema.
+func withSearchPath(dsn, schema string) string {
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema
+	} else if strings.Contains(dsn, "?") {
+		return dsn + "&search_path=" + schema
+	}
+	return dsn + "?search_path=" + schema
+}
+
+func sqlRepositories(database *sqldb.Database) repositorytest.Repositories {
+	fake := clock.NewFake(time.Now())
+	auctionRepository := sqldb.NewAuctionRepository(database, fake)
+
+	return repositorytest.Repositories{
+		Auctions:             auctionRepository,
+		Bids:                 sqldb.NewBidRepository(database, fake),
+		Users:                sqldb.NewUserRepository(database),
+		Categories:           sqldb.NewCategoryRepository(database),
+		Leases:               sqldb.NewLeaseRepository(database, fake),
+		Jobs:                 sqldb.NewJobRepository(database),
+		Clock:                fake,
+		CloseExpiredAuctions: auctionRepository.CloseExpiredAuctions,
+	}
+}
@@ -0,0 +1,278 @@
+package repositorytest
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testBidWinningOrder(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	auction := mustCreateAuction(t, repositories, newAuction(t, "Watch", 0))
+
+	_, err := repositories.Bids.FindWinningBidByAuctionId(ctx, auction.Id)
+	expectErr(t, err, "not_found")
+
+	firstUser, secondUser := uuid.New().String(), uuid.New().String()
+	earliestHighest := newBid(firstUser, auction.Id, 200, 3*time.Second)
+	mustCreateBids(t, repositories,
+		newBid(secondUser, auction.Id, 100, 4*time.Second),
+		earliestHighest,
+		newBid(secondUser, auction.Id, 200, time.Second),
+		newBid(secondUser, auction.Id, 150, 0),
+	)
+
+	winningBid, err := repositories.Bids.FindWinningBidByAuctionId(ctx, auction.Id)
+	if err != nil {
+		t.Fatalf("FindWinningBidByAuctionId: %v", err)
+	}
+	if winningBid.Id != earliestHighest.Id {
+		t.Errorf("expected earliest highest bid %s to win, got %+v", earliestHighest.Id, winningBid)
+	}
+
+	bids, err := repositories.Bids.FindBidByAuctionId(ctx, auction.Id)
+	if err != nil {
+		t.Fatalf("FindBidByAuctionId: %v", err)
+	}
+	if len(bids) != 4 {
+		t.Errorf("expected 4 bids, got %d", len(bids))
+	}
+
+	found, _ := repositories.Auctions.FindAuctionById(ctx, auction.Id)
+	if found.HighestBid != 200 || found.BidCount != 4 {
+		t.Errorf("expected highest bid 200 and 4 bids, got %v and %d", found.HighestBid, found.BidCount)
+	}
+}
+
+// testBidRejectedWhenNotActive pins that a bid is refused once the auction's
+// end time has passed, even before the closure has marked it completed.
+func testBidRejectedWhenNotActive(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	userId := uuid.New().String()
+
+	cancelled := mustCreateAuction(t, repositories, newAuction(t, "Cancelled", 0))
+	if _, err := repositories.Auctions.CancelAuction(ctx, cancelled.Id); err != nil {
+		t.Fatalf("CancelAuction: %v", err)
+	}
+
+	expired := mustCreateAuction(t, repositories, newAuction(t, "Expired", 24*time.Hour))
+
+	closed := mustCreateAuction(t, repositories, newAuction(t, "Closed", 24*time.Hour))
+	repositories.CloseExpiredAuctions()
+
+	mustCreateBids(t, repositories,
+		newBid(userId, cancelled.Id, 10, 0),
+		newBid(userId, expired.Id, 10, 0),
+		newBid(userId, closed.Id, 10, 0),
+	)
+
+	for _, auction := range []*auction_entity.Auction{cancelled, expired, closed} {
+		bids, err := repositories.Bids.FindBidByAuctionId(ctx, auction.Id)
+		if err != nil {
+			t.Fatalf("FindBidByAuctionId: %v", err)
+		}
+		if len(bids) != 0 {
+			t.Errorf("expected bid on %s auction to be rejected", auction.ProductName)
+		}
+	}
+}
+
+func testBidsByUser(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	userId, rivalId := uuid.New().String(), uuid.New().String()
+
+	endTime := repositories.Clock.Now().Add(300 * time.Millisecond)
+	won, lost := newAuction(t, "Won", time.Minute), newAuction(t, "Lost", time.Minute)
+	won.EndTime, lost.EndTime = endTime, endTime
+	mustCreateAuction(t, repositories, won)
+	mustCreateAuction(t, repositories, lost)
+	open := mustCreateAuction(t, repositories, newAuction(t, "Open", 0))
+
+	mustCreateBids(t, repositories,
+		newBid(userId, won.Id, 50, 5*time.Second),
+		newBid(rivalId, won.Id, 40, 4*time.Second),
+		newBid(userId, lost.Id, 50, 3*time.Second),
+		newBid(rivalId, lost.Id, 60, 2*time.Second),
+		newBid(userId, open.Id, 70, time.Second),
+	)
+
+	repositories.Clock.Set(endTime)
+	repositories.CloseExpiredAuctions()
+
+	bids, total, err := repositories.Bids.FindBidsByUserId(ctx, userId, nil, 1, 2)
+	if err != nil {
+		t.Fatalf("FindBidsByUserId: %v", err)
+	}
+	if total != 3 || len(bids) != 2 || bids[0].AuctionId != open.Id || bids[1].AuctionId != lost.Id {
+		t.Errorf("expected newest bids first with total 3, got %d %+v", total, bids)
+	}
+
+	completed := auction_entity.Completed
+	bids, total, err = repositories.Bids.FindBidsByUserId(ctx, userId, &completed, 1, 10)
+	if err != nil {
+		t.Fatalf("FindBidsByUserId: %v", err)
+	}
+	if total != 2 || len(bids) != 2 {
+		t.Errorf("expected 2 bids on completed auctions, got %d", total)
+	}
+
+	bids, total, err = repositories.Bids.FindWinningBidsByUserId(ctx, userId, 1, 10)
+	if err != nil {
+		t.Fatalf("FindWinningBidsByUserId: %v", err)
+	}
+	if total != 1 || len(bids) != 1 || bids[0].AuctionId != won.Id {
+		t.Errorf("expected only auction %s to be won, got %+v", won.Id, bids)
+	}
+}
+
+// testBidRaceWithClosure places bids from several goroutines while the
+// auction ends and is closed, and checks that no bid started after its end
+// time was stored and that the auction stats match the stored bids.
+func testBidRaceWithClosure(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	auction := newAuction(t, "Race", 5*time.Minute)
+	auction.EndTime = repositories.Clock.Now().Add(500 * time.Millisecond)
+	mustCreateAuction(t, repositories, auction)
+
+	const bidders, bidsAfterClosure = 8, 5
+
+	var lateBidsMutex sync.Mutex
+	lateBids := map[string]bool{}
+
+	// The auction ends once every bidder placed a bid, while they go on.
+	var wg, firstBids sync.WaitGroup
+	firstBids.Add(bidders)
+	for bidder := 0; bidder < bidders; bidder++ {
+		wg.Add(1)
+		go func(bidder int) {
+			defer wg.Done()
+
+			userId := uuid.New().String()
+			for i, placedLate := 0, 0; placedLate < bidsAfterClosure; i++ {
+				bid := newBid(userId, auction.Id, float64(i*bidders+bidder+1), 0)
+				late := repositories.Clock.Now().After(auction.EndTime)
+
+				_, err := repositories.Bids.CreateBid(ctx, []bid_entity.Bid{bid})
+				if i == 0 {
+					firstBids.Done()
+				}
+				if err != nil {
+					t.Errorf("CreateBid: %v", err)
+					return
+				}
+
+				if late {
+					lateBidsMutex.Lock()
+					lateBids[bid.Id] = true
+					lateBidsMutex.Unlock()
+					placedLate++
+				}
+			}
+		}(bidder)
+	}
+
+	firstBids.Wait()
+	repositories.Clock.Set(auction.EndTime.Add(time.Millisecond))
+	repositories.CloseExpiredAuctions()
+
+	wg.Wait()
+
+	bids, err := repositories.Bids.FindBidByAuctionId(ctx, auction.Id)
+	if err != nil {
+		t.Fatalf("FindBidByAuctionId: %v", err)
+	}
+
+	highestBid := 0.0
+	for _, bid := range bids {
+		if lateBids[bid.Id] {
+			t.Errorf("bid %s placed after the auction closed was accepted", bid.Id)
+		}
+		if bid.Amount > highestBid {
+			highestBid = bid.Amount
+		}
+	}
+
+	found, err := repositories.Auctions.FindAuctionById(ctx, auction.Id)
+	if err != nil {
+		t.Fatalf("FindAuctionById: %v", err)
+	}
+	if found.Status != auction_entity.Completed {
+		t.Errorf("expected auction to be closed, got status %v", found.Status)
+	}
+	if found.BidCount != int64(len(bids)) || found.HighestBid != highestBid {
+		t.Errorf("expected stats %d bids and highest %v, got %d and %v",
+			len(bids), highestBid, found.BidCount, found.HighestBid)
+	}
+}
+
+func testBidIdempotent(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	auction := mustCreateAuction(t, repositories, newAuction(t, "Replayed", 0))
+
+	bid := newBid(uuid.New().String(), auction.Id, 100, 0)
+	mustCreateBids(t, repositories, bid)
+	mustCreateBids(t, repositories, bid, newBid(uuid.New().String(), auction.Id, 50, 0))
+
+	bids, err := repositories.Bids.FindBidByAuctionId(ctx, auction.Id)
+	if err != nil {
+		t.Fatalf("FindBidByAuctionId: %v", err)
+	}
+	if len(bids) != 2 {
+		t.Errorf("expected the repeated bid to be stored once, got %d bids", len(bids))
+	}
+
+	found, _ := repositories.Auctions.FindAuctionById(ctx, auction.Id)
+	if found.BidCount != 2 || found.HighestBid != 100 {
+		t.Errorf("expected 2 bids and highest 100, got %d and %v", found.BidCount, found.HighestBid)
+	}
+}
+
+func testBidResults(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	userId := uuid.New().String()
+
+	open := mustCreateAuction(t, repositories, newAuction(t, "Open", 0))
+	other := mustCreateAuction(t, repositories, newAuction(t, "Other", 0))
+	closed := mustCreateAuction(t, repositories, newAuction(t, "Closed", 24*time.Hour))
+	repositories.CloseExpiredAuctions()
+
+	stored := newBid(userId, open.Id, 10, 0)
+	mustCreateBids(t, repositories, stored)
+
+	bids := []bid_entity.Bid{
+		newBid(userId, open.Id, 20, 0),
+		stored,
+		newBid(userId, closed.Id, 30, 0),
+		newBid(userId, other.Id, 40, 0),
+		newBid(userId, uuid.New().String(), 50, 0),
+		newBid(userId, open.Id, 15, 0),
+	}
+	results := mustCreateBids(t, repositories, bids...)
+
+	expected := []bid_entity.BidResultStatus{
+		bid_entity.BidStored,
+		bid_entity.BidDuplicate,
+		bid_entity.BidRejected,
+		bid_entity.BidStored,
+		bid_entity.BidRejected,
+		bid_entity.BidStored,
+	}
+	for i, result := range results {
+		if result.BidId != bids[i].Id || result.Status != expected[i] {
+			t.Errorf("bid %d: expected status %v for %s, got %+v", i, expected[i], bids[i].Id, result)
+		}
+	}
+	if results[4].Err == nil || results[4].Err.Err != "not_found" {
+		t.Errorf("expected a not_found error for the unknown auction, got %+v", results[4].Err)
+	}
+
+	found, _ := repositories.Auctions.FindAuctionById(ctx, open.Id)
+	if found.BidCount != 3 || found.HighestBid != 20 {
+		t.Errorf("expected 3 bids and highest 20, got %d and %v", found.BidCount, found.HighestBid)
+	}
+}
@@ -0,0 +1,69 @@
+package repositorytest
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"testing"
+	"time"
+)
+
+func testCategoryLifecycle(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	electronics, err := category_entity.CreateCategory("Electronics", "", nil, time.Now())
+	if err != nil {
+		t.Fatalf("CreateCategory entity: %v", err)
+	}
+	if err := repositories.Categories.CreateCategory(ctx, electronics); err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+
+	phones, _ := category_entity.CreateCategory("Phones", electronics.Id, []category_entity.AttributeDefinition{
+		{Key: "brand", Type: category_entity.AttributeString, Required: true, Options: []string{"Acme"}},
+	}, time.Now())
+	if err := repositories.Categories.CreateCategory(ctx, phones); err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+
+	duplicate, _ := category_entity.CreateCategory("phones", "", nil, time.Now())
+	expectErr(t, repositories.Categories.CreateCategory(ctx, duplicate), "conflict")
+
+	found, err := repositories.Categories.FindCategoryBySlug(ctx, "phones")
+	if err != nil {
+		t.Fatalf("FindCategoryBySlug: %v", err)
+	}
+	if found.Id != phones.Id || found.ParentId != electronics.Id ||
+		len(found.Attributes) != 1 || found.Attributes[0].Options[0] != "Acme" {
+		t.Errorf("unexpected category %+v", found)
+	}
+
+	phones.Rename("Electronics")
+	expectErr(t, repositories.Categories.UpdateCategory(ctx, phones), "conflict")
+
+	phones.Rename("Mobile Phones")
+	phones.ParentId = ""
+	if err := repositories.Categories.UpdateCategory(ctx, phones); err != nil {
+		t.Fatalf("UpdateCategory: %v", err)
+	}
+
+	found, _ = repositories.Categories.FindCategoryById(ctx, phones.Id)
+	if found.Slug != "mobile-phones" || found.ParentId != "" {
+		t.Errorf("unexpected updated category %+v", found)
+	}
+
+	categories, err := repositories.Categories.FindCategories(ctx)
+	if err != nil {
+		t.Fatalf("FindCategories: %v", err)
+	}
+	if len(categories) != 2 || categories[0].Id != electronics.Id {
+		t.Errorf("expected categories sorted by name, got %+v", categories)
+	}
+
+	if err := repositories.Categories.DeleteCategory(ctx, electronics.Id); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+	expectErr(t, repositories.Categories.DeleteCategory(ctx, electronics.Id), "not_found")
+
+	_, err = repositories.Categories.FindCategoryById(ctx, electronics.Id)
+	expectErr(t, err, "not_found")
+}
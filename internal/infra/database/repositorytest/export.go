@@ -0,0 +1,134 @@
+package repositorytest
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testAuctionExport(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	oldest := mustCreateAuction(t, repositories, newAuction(t, "Typewriter", 3*time.Hour))
+	middle := newAuction(t, "Gramophone", 2*time.Hour)
+	middle.CategoryId = oldest.CategoryId
+	mustCreateAuction(t, repositories, middle)
+	newest := mustCreateAuction(t, repositories, newAuction(t, "Radio", time.Hour))
+
+	if _, err := repositories.Auctions.CancelAuction(ctx, middle.Id); err != nil {
+		t.Fatalf("CancelAuction: %v", err)
+	}
+
+	cancelled := auction_entity.Cancelled
+	createdFrom := time.Now().Add(-150 * time.Minute)
+	endedTo := time.Now().Add(-150 * time.Minute)
+
+	cases := map[string]struct {
+		filter auction_entity.AuctionExportFilter
+		want   []string
+	}{
+		"everything": {
+			want: []string{oldest.Id, middle.Id, newest.Id},
+		},
+		"status": {
+			filter: auction_entity.AuctionExportFilter{Status: &cancelled},
+			want:   []string{middle.Id},
+		},
+		"category": {
+			filter: auction_entity.AuctionExportFilter{CategoryIds: []string{oldest.CategoryId}},
+			want:   []string{oldest.Id, middle.Id},
+		},
+		"created from": {
+			filter: auction_entity.AuctionExportFilter{CreatedFrom: &createdFrom},
+			want:   []string{middle.Id, newest.Id},
+		},
+		"ended to": {
+			filter: auction_entity.AuctionExportFilter{EndedTo: &endedTo},
+			want:   []string{oldest.Id},
+		},
+	}
+
+	for name, testCase := range cases {
+		var exported []auction_entity.Auction
+		err := repositories.Auctions.ExportAuctions(ctx, testCase.filter,
+			func(auction *auction_entity.Auction) *internal_error.InternalError {
+				exported = append(exported, *auction)
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("%s: ExportAuctions: %v", name, err)
+		}
+
+		assertAuctionIdsInOrder(t, name, exported, testCase.want)
+	}
+
+	calls := 0
+	stop := internal_error.NewInternalServerError("stop")
+	err := repositories.Auctions.ExportAuctions(ctx, auction_entity.AuctionExportFilter{},
+		func(*auction_entity.Auction) *internal_error.InternalError {
+			calls++
+			return stop
+		})
+	if err != stop || calls != 1 {
+		t.Errorf("expected the export to stop at the first error, got %v after %d calls", err, calls)
+	}
+}
+
+func testBidExport(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	userId := uuid.New().String()
+
+	// Auctions still open so they accept the bids.
+	var auctions []*auction_entity.Auction
+	for i, productName := range []string{"Painting", "Sculpture", "Vase"} {
+		auction := newAuction(t, productName, time.Duration(2-i)*time.Hour)
+		auction.EndTime = time.Now().Add(10 * time.Minute)
+		auctions = append(auctions, mustCreateAuction(t, repositories, auction))
+	}
+	older, newer := auctions[0], auctions[1]
+
+	newerFirst := newBid(userId, newer.Id, 10, 3*time.Second)
+	olderSecond := newBid(userId, older.Id, 20, time.Second)
+	olderFirst := newBid(userId, older.Id, 10, 2*time.Second)
+	newerSecond := newBid(userId, newer.Id, 30, 0)
+	mustCreateBids(t, repositories, newerFirst, olderSecond, olderFirst, newerSecond)
+
+	cases := map[string]struct {
+		filter auction_entity.AuctionExportFilter
+		want   []string
+	}{
+		"everything": {
+			want: []string{olderFirst.Id, olderSecond.Id, newerFirst.Id, newerSecond.Id},
+		},
+		"category": {
+			filter: auction_entity.AuctionExportFilter{CategoryIds: []string{newer.CategoryId}},
+			want:   []string{newerFirst.Id, newerSecond.Id},
+		},
+	}
+
+	for name, testCase := range cases {
+		var exported []string
+		err := repositories.Bids.ExportBids(ctx, testCase.filter,
+			func(bid *bid_entity.Bid) *internal_error.InternalError {
+				exported = append(exported, bid.Id)
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("%s: ExportBids: %v", name, err)
+		}
+
+		if len(exported) != len(testCase.want) {
+			t.Fatalf("%s: expected %d bids, got %d", name, len(testCase.want), len(exported))
+		}
+		for i, id := range testCase.want {
+			if exported[i] != id {
+				t.Errorf("%s: expected bid %s at position %d, got %s", name, id, i, exported[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package repositorytest
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/job_entity"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func mustCreateJob(t *testing.T, repositories Repositories, spec job_entity.JobSpec, now time.Time) *job_entity.Job {
+	t.Helper()
+
+	job, err := job_entity.CreateJob(spec, now)
+	if err != nil {
+		t.Fatalf("CreateJob entity: %v", err)
+	}
+
+	created, ok, err := repositories.Jobs.CreateJob(context.Background(), job)
+	if err != nil || !ok {
+		t.Fatalf("CreateJob: %v (created %v)", err, ok)
+	}
+
+	return created
+}
+
+func testJobClaims(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	now := time.UnixMilli(time.Now().UnixMilli())
+	const leaseTTL = time.Minute
+
+	later := mustCreateJob(t, repositories, job_entity.JobSpec{
+		Type: "reminder", RunAt: now.Add(time.Hour)}, now)
+	first := mustCreateJob(t, repositories, job_entity.JobSpec{
+		Type: "closure", Payload: []byte(`{"auction_id":"a"}`), RunAt: now.Add(-time.Second),
+		IdempotencyKey: "closure:a"}, now)
+
+	duplicate, _ := job_entity.CreateJob(job_entity.JobSpec{Type: "closure", IdempotencyKey: "closure:a"}, now)
+	existing, created, err := repositories.Jobs.CreateJob(ctx, duplicate)
+	if err != nil || created || existing.Id != first.Id {
+		t.Fatalf("expected the idempotency key to return job %s, got %+v %v %v", first.Id, existing, created, err)
+	}
+
+	claimed, err := repositories.Jobs.ClaimJob(ctx, "worker-1", now, leaseTTL)
+	if err != nil || claimed == nil || claimed.Id != first.Id {
+		t.Fatalf("expected worker-1 to claim the due job, got %+v %v", claimed, err)
+	}
+	if claimed.Status != job_entity.Running || claimed.Attempts != 1 || claimed.Token != 1 ||
+		claimed.ClaimedBy != "worker-1" || string(claimed.Payload) != `{"auction_id":"a"}` {
+		t.Errorf("unexpected claimed job %+v", claimed)
+	}
+
+	if next, err := repositories.Jobs.ClaimJob(ctx, "worker-2", now, leaseTTL); err != nil || next != nil {
+		t.Fatalf("expected no other job due, got %+v %v", next, err)
+	}
+
+	// Once the lease expires another worker takes the job over.
+	takenOver, err := repositories.Jobs.ClaimJob(ctx, "worker-2", now.Add(leaseTTL), leaseTTL)
+	if err != nil || takenOver == nil || takenOver.Id != first.Id || takenOver.Attempts != 2 || takenOver.Token != 2 {
+		t.Fatalf("expected worker-2 to take the job over, got %+v %v", takenOver, err)
+	}
+
+	claimed.Status = job_entity.Succeeded
+	if finished, err := repositories.Jobs.FinishJob(ctx, claimed); err != nil || finished {
+		t.Errorf("expected worker-1 not to finish a job taken over, got %v %v", finished, err)
+	}
+
+	takenOver.Status = job_entity.Failed
+	takenOver.LastError = "boom"
+	takenOver.UpdatedAt = now.Add(leaseTTL)
+	if finished, err := repositories.Jobs.FinishJob(ctx, takenOver); err != nil || !finished {
+		t.Fatalf("expected worker-2 to finish the job, got %v %v", finished, err)
+	}
+
+	found, err := repositories.Jobs.FindJobById(ctx, first.Id)
+	if err != nil || found.Status != job_entity.Failed || found.LastError != "boom" || found.ClaimedBy != "" {
+		t.Errorf("expected the job to be stored as failed, got %+v %v", found, err)
+	}
+
+	claimed, err = repositories.Jobs.ClaimJob(ctx, "worker-1", now.Add(time.Hour), leaseTTL)
+	if err != nil || claimed == nil || claimed.Id != later.Id {
+		t.Fatalf("expected the later job to be claimed once due, got %+v %v", claimed, err)
+	}
+}
+
+func testJobAdmin(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	now := time.UnixMilli(time.Now().UnixMilli())
+
+	for i := 0; i < 3; i++ {
+		mustCreateJob(t, repositories, job_entity.JobSpec{
+			Type: "reminder", RunAt: now.Add(time.Duration(i) * time.Minute)}, now)
+	}
+	recurring := mustCreateJob(t, repositories, job_entity.JobSpec{Type: "sweep", Cron: "*/5 * * * *"}, now)
+	if !recurring.RunAt.After(now) || recurring.Cron != "*/5 * * * *" {
+		t.Errorf("expected the recurring job to run at its next cron time, got %+v", recurring)
+	}
+
+	jobs, total, err := repositories.Jobs.FindJobs(ctx, job_entity.JobFilter{Type: "reminder", Page: 2, Limit: 2})
+	if err != nil || total != 3 || len(jobs) != 1 {
+		t.Fatalf("expected the last of 3 reminders on page 2, got %d of %d %v", len(jobs), total, err)
+	}
+
+	cancelled, err := repositories.Jobs.CancelJob(ctx, recurring.Id)
+	if err != nil || cancelled.Status != job_entity.Cancelled {
+		t.Fatalf("CancelJob: %+v %v", cancelled, err)
+	}
+
+	_, err = repositories.Jobs.CancelJob(ctx, recurring.Id)
+	expectErr(t, err, "bad_request")
+
+	status := job_entity.Cancelled
+	jobs, total, err = repositories.Jobs.FindJobs(ctx, job_entity.JobFilter{Status: &status, Page: 1, Limit: 10})
+	if err != nil || total != 1 || jobs[0].Id != recurring.Id {
+		t.Errorf("expected only the cancelled job, got %+v %v", jobs, err)
+	}
+
+	retried, err := repositories.Jobs.RetryJob(ctx, recurring.Id, now)
+	if err != nil || retried.Status != job_entity.Pending || retried.Attempts != 0 || !retried.RunAt.Equal(now) {
+		t.Fatalf("RetryJob: %+v %v", retried, err)
+	}
+
+	_, err = repositories.Jobs.RetryJob(ctx, recurring.Id, now)
+	expectErr(t, err, "bad_request")
+
+	_, err = repositories.Jobs.RetryJob(ctx, uuid.New().String(), now)
+	expectErr(t, err, "not_found")
+
+	_, err = repositories.Jobs.CancelJob(ctx, uuid.New().String())
+	expectErr(t, err, "not_found")
+}
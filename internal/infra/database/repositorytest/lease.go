@@ -0,0 +1,59 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func testLeaseElection(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	const name = "closure"
+
+	_, err := repositories.Leases.FindLease(ctx, name)
+	expectErr(t, err, "not_found")
+
+	lease, held, err := repositories.Leases.AcquireLease(ctx, name, "first", time.Minute)
+	if err != nil || !held || lease.HolderId != "first" || lease.Token != 1 {
+		t.Fatalf("expected first to acquire the lease with token 1, got %+v %v %v", lease, held, err)
+	}
+
+	lease, held, err = repositories.Leases.AcquireLease(ctx, name, "second", time.Minute)
+	if err != nil || held || lease.HolderId != "first" {
+		t.Fatalf("expected second to see first holding the lease, got %+v %v %v", lease, held, err)
+	}
+
+	lease, held, err = repositories.Leases.AcquireLease(ctx, name, "first", time.Minute)
+	if err != nil || !held || lease.Token != 1 {
+		t.Errorf("expected first to renew keeping token 1, got %+v %v %v", lease, held, err)
+	}
+
+	if err := repositories.Leases.ReleaseLease(ctx, name, "first", 1); err != nil {
+		t.Fatalf("ReleaseLease: %v", err)
+	}
+
+	lease, held, err = repositories.Leases.AcquireLease(ctx, name, "second", 50*time.Millisecond)
+	if err != nil || !held || lease.HolderId != "second" || lease.Token != 2 {
+		t.Fatalf("expected second to take the released lease with token 2, got %+v %v %v", lease, held, err)
+	}
+
+	// A stale release from the previous holder must not free the lease.
+	if err := repositories.Leases.ReleaseLease(ctx, name, "first", 1); err != nil {
+		t.Fatalf("ReleaseLease: %v", err)
+	}
+	if _, held, _ := repositories.Leases.AcquireLease(ctx, name, "first", time.Minute); held {
+		t.Error("expected a stale release to leave the lease with second")
+	}
+
+	repositories.Clock.Advance(100 * time.Millisecond)
+
+	lease, held, err = repositories.Leases.AcquireLease(ctx, name, "first", time.Minute)
+	if err != nil || !held || lease.HolderId != "first" || lease.Token != 3 {
+		t.Errorf("expected first to take over the expired lease with token 3, got %+v %v %v", lease, held, err)
+	}
+
+	found, err := repositories.Leases.FindLease(ctx, name)
+	if err != nil || found.HolderId != "first" || found.Token != 3 {
+		t.Errorf("expected FindLease to return first with token 3, got %+v %v", found, err)
+	}
+}
@@ -0,0 +1,141 @@
+// Package repositorytest holds the contract every storage backend must
+// satisfy. Backends run it from their own tests through Run.
+package repositorytest
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/entity/job_entity"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repositories struct {
+	Auctions   auction_entity.AuctionRepositoryInterface
+	Bids       bid_entity.BidEntityRepository
+	Users      user_entity.UserRepositoryInterface
+	Categories category_entity.CategoryRepositoryInterface
+	Leases     lease_entity.LeaseRepositoryInterface
+	Jobs       job_entity.JobRepositoryInterface
+
+	// Clock is the clock the repositories were built with, advanced by the
+	// tests that wait for auctions to end or leases to expire.
+	Clock *clock.Fake
+
+	CloseExpiredAuctions func()
+}
+
+// Run executes the contract suite. newRepositories must return repositories
+// backed by empty storage on every call, built with a fake clock set to the
+// current time.
+func Run(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	t.Setenv("AUCTION_DURATION", "10m")
+
+	tests := map[string]func(t *testing.T, repositories Repositories){
+		"AuctionCreateAndFind":       testAuctionCreateAndFind,
+		"AuctionCreateBatch":         testAuctionCreateBatch,
+		"AuctionFilters":             testAuctionFilters,
+		"AuctionCursorPagination":    testAuctionCursorPagination,
+		"AuctionTextSearch":          testAuctionTextSearch,
+		"AuctionRenameCategory":      testAuctionRenameCategory,
+		"AuctionSuggestProductNames": testAuctionSuggestProductNames,
+		"AuctionImages":              testAuctionImages,
+		"AuctionCancel":              testAuctionCancel,
+		"AuctionClosure":             testAuctionClosure,
+		"AuctionExtendAndClose":      testAuctionExtendAndClose,
+		"AuctionFencedClosure":       testAuctionFencedClosure,
+		"AuctionForceClose":          testAuctionForceClose,
+		"AuctionSchedule":            testAuctionSchedule,
+		"AuctionExport":              testAuctionExport,
+		"BidWinningOrder":            testBidWinningOrder,
+		"BidRejectedWhenNotActive":   testBidRejectedWhenNotActive,
+		"BidRaceWithClosure":         testBidRaceWithClosure,
+		"BidIdempotent":              testBidIdempotent,
+		"BidResults":                 testBidResults,
+		"BidsByUser":                 testBidsByUser,
+		"BidExport":                  testBidExport,
+		"UserConflicts":              testUserConflicts,
+		"UserDeactivation":           testUserDeactivation,
+		"UserFind":                   testUserFind,
+		"UserWithoutEmail":           testUserWithoutEmail,
+		"CategoryLifecycle":          testCategoryLifecycle,
+		"LeaseElection":              testLeaseElection,
+		"JobClaims":                  testJobClaims,
+		"JobAdmin":                   testJobAdmin,
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			test(t, newRepositories(t))
+		})
+	}
+}
+
+func newAuction(t *testing.T, productName string, age time.Duration) *auction_entity.Auction {
+	t.Helper()
+
+	auction, err := auction_entity.CreateAuction(
+		productName, uuid.New().String(), "A description long enough", auction_entity.New, time.Now().Add(-age))
+	if err != nil {
+		t.Fatalf("CreateAuction entity: %v", err)
+	}
+	auction.EndTime = auction.Timestamp.Add(10 * time.Minute)
+
+	return auction
+}
+
+func mustCreateAuction(
+	t *testing.T, repositories Repositories, auction *auction_entity.Auction) *auction_entity.Auction {
+	t.Helper()
+
+	if err := repositories.Auctions.CreateAuction(context.Background(), auction); err != nil {
+		t.Fatalf("CreateAuction: %v", err)
+	}
+
+	return auction
+}
+
+func mustCreateBids(
+	t *testing.T, repositories Repositories, bids ...bid_entity.Bid) []bid_entity.BidResult {
+	t.Helper()
+
+	results, err := repositories.Bids.CreateBid(context.Background(), bids)
+	if err != nil {
+		t.Fatalf("CreateBid: %v", err)
+	}
+	if len(results) != len(bids) {
+		t.Fatalf("expected %d bid results, got %d", len(bids), len(results))
+	}
+
+	return results
+}
+
+func newBid(userId, auctionId string, amount float64, age time.Duration) bid_entity.Bid {
+	return bid_entity.Bid{
+		Id:        uuid.New().String(),
+		UserId:    userId,
+		AuctionId: auctionId,
+		Amount:    amount,
+		Timestamp: time.Now().Add(-age),
+	}
+}
+
+func expectErr(t *testing.T, err *internal_error.InternalError, kind string) {
+	t.Helper()
+
+	if err == nil {
+		t.Fatalf("expected %s error, got nil", kind)
+	}
+	if err.Err != kind {
+		t.Fatalf("expected %s error, got %s: %s", kind, err.Err, err.Message)
+	}
+}
@@ -0,0 +1,154 @@
+package repositorytest
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func mustCreateUser(t *testing.T, repositories Repositories, name, email string) *user_entity.User {
+	t.Helper()
+
+	user, err := user_entity.CreateUser(name, email)
+	if err != nil {
+		t.Fatalf("CreateUser entity: %v", err)
+	}
+
+	if err := repositories.Users.CreateUser(context.Background(), user); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	return user
+}
+
+func testUserConflicts(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	alice := mustCreateUser(t, repositories, "Alice", "alice@example.com")
+	bob := mustCreateUser(t, repositories, "Bob", "bob@example.com")
+
+	duplicate, _ := user_entity.CreateUser("Other Alice", "ALICE@example.com")
+	expectErr(t, repositories.Users.CreateUser(ctx, duplicate), "conflict")
+
+	expectErr(t, repositories.Users.UpdateUserProfile(ctx, bob.Id, user_entity.UserProfileUpdate{
+		Email: &alice.Email}), "conflict")
+
+	name := "Alice Smith"
+	if err := repositories.Users.UpdateUserProfile(ctx, alice.Id, user_entity.UserProfileUpdate{
+		Name: &name}); err != nil {
+		t.Fatalf("UpdateUserProfile: %v", err)
+	}
+
+	found, err := repositories.Users.FindUserById(ctx, alice.Id)
+	if err != nil {
+		t.Fatalf("FindUserById: %v", err)
+	}
+	if found.Name != "Alice Smith" || found.Email != "alice@example.com" {
+		t.Errorf("unexpected user %+v", found)
+	}
+
+	missing := uuid.New().String()
+	expectErr(t, repositories.Users.UpdateUserProfile(ctx, missing, user_entity.UserProfileUpdate{
+		Name: &name}), "not_found")
+	expectErr(t, repositories.Users.DeactivateUser(ctx, missing), "not_found")
+
+	_, err = repositories.Users.FindUserById(ctx, missing)
+	expectErr(t, err, "not_found")
+}
+
+func testUserDeactivation(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	erin := mustCreateUser(t, repositories, "Erin", "erin@example.com")
+	if err := repositories.Users.DeactivateUser(ctx, erin.Id); err != nil {
+		t.Fatalf("DeactivateUser: %v", err)
+	}
+	if err := repositories.Users.DeactivateUser(ctx, erin.Id); err != nil {
+		t.Fatalf("DeactivateUser twice: %v", err)
+	}
+
+	// A profile update read before the deactivation must neither apply nor
+	// bring the user back.
+	name := "Erin Late"
+	expectErr(t, repositories.Users.UpdateUserProfile(ctx, erin.Id, user_entity.UserProfileUpdate{
+		Name: &name}), "not_found")
+	expectErr(t, repositories.Users.UpdateUserProfile(ctx, erin.Id, user_entity.UserProfileUpdate{}), "not_found")
+
+	found, err := repositories.Users.FindUserById(ctx, erin.Id)
+	if err != nil {
+		t.Fatalf("FindUserById: %v", err)
+	}
+	if found.Name != "Erin" || found.Email != "erin@example.com" || found.Status != user_entity.Inactive {
+		t.Errorf("unexpected user %+v", found)
+	}
+}
+
+func testUserWithoutEmail(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	// Users created before emails were required have none; several of them
+	// must be updatable without conflicting on the unique email index.
+	var legacy []*user_entity.User
+	for _, name := range []string{"Legacy One", "Legacy Two"} {
+		user := &user_entity.User{Id: uuid.New().String(), Name: name, Status: user_entity.Active}
+		if err := repositories.Users.CreateUser(ctx, user); err != nil {
+			t.Fatalf("CreateUser %s: %v", name, err)
+		}
+		legacy = append(legacy, user)
+	}
+
+	for _, user := range legacy {
+		name := user.Name + " Renamed"
+		if err := repositories.Users.UpdateUserProfile(ctx, user.Id, user_entity.UserProfileUpdate{
+			Name: &name}); err != nil {
+			t.Fatalf("UpdateUserProfile %s: %v", user.Name, err)
+		}
+		if err := repositories.Users.DeactivateUser(ctx, user.Id); err != nil {
+			t.Fatalf("DeactivateUser %s: %v", user.Name, err)
+		}
+
+		found, err := repositories.Users.FindUserById(ctx, user.Id)
+		if err != nil {
+			t.Fatalf("FindUserById: %v", err)
+		}
+		if found.Name != user.Name+" Renamed" || found.Email != "" || found.Status != user_entity.Inactive {
+			t.Errorf("unexpected user %+v", found)
+		}
+	}
+}
+
+func testUserFind(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+
+	mustCreateUser(t, repositories, "Carol", "carol@example.com")
+	caroline := mustCreateUser(t, repositories, "Caroline", "caroline@example.com")
+	mustCreateUser(t, repositories, "Dave", "dave@example.com")
+
+	if err := repositories.Users.DeactivateUser(ctx, caroline.Id); err != nil {
+		t.Fatalf("DeactivateUser: %v", err)
+	}
+
+	users, total, err := repositories.Users.FindUsers(ctx, user_entity.UserFilter{
+		Name: "CAROL", Page: 1, Limit: 1})
+	if err != nil {
+		t.Fatalf("FindUsers: %v", err)
+	}
+	if total != 2 || len(users) != 1 || users[0].Name != "Carol" {
+		t.Errorf("expected Carol first of 2 matches, got %d %+v", total, users)
+	}
+
+	active := user_entity.Active
+	_, total, _ = repositories.Users.FindUsers(ctx, user_entity.UserFilter{
+		Status: &active, Page: 1, Limit: 10})
+	if total != 2 {
+		t.Errorf("expected 2 active users, got %d", total)
+	}
+
+	users, _, _ = repositories.Users.FindUsers(ctx, user_entity.UserFilter{
+		Email: " Dave@Example.com", Page: 1, Limit: 10})
+	if len(users) != 1 || users[0].Name != "Dave" {
+		t.Errorf("expected to find Dave by email, got %+v", users)
+	}
+}
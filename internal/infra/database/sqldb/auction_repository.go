@@ -0,0 +1,1021 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+	"time"
+)
+
+// Text search weights mirror the auction_text_search index of the Mongo backend.
+const (
+	productNameWeight = 10
+	categoryWeight    = 5
+	descriptionWeight = 1
+)
+
+const auctionColumns = "id, product_name, category_id, category, description, condition, " +
+	"status, timestamp, starts_at, end_time, highest_bid, bid_count, attributes"
+
+var (
+	auctionStatusToSQL = map[auction_entity.AuctionStatus]string{
+		auction_entity.Active:    "active",
+		auction_entity.Completed: "closed",
+		auction_entity.Cancelled: "cancelled",
+		auction_entity.Scheduled: "scheduled",
+	}
+	auctionStatusFromSQL = map[string]auction_entity.AuctionStatus{
+		"active":    auction_entity.Active,
+		"closed":    auction_entity.Completed,
+		"cancelled": auction_entity.Cancelled,
+		"scheduled": auction_entity.Scheduled,
+	}
+)
+
+type AuctionRepository struct {
+	Database *Database
+	clock    clock.Clock
+}
+
+func NewAuctionRepository(database *Database, clock clock.Clock) *AuctionRepository {
+	return &AuctionRepository{Database: database, clock: clock}
+}
+
+// backfillAuctionEndTimes stores the end time of auctions created before it
+// was kept on the row, derived from their creation time and AUCTION_DURATION.
+func backfillAuctionEndTimes(ctx context.Context, d *Database, tx *sql.Tx) error {
+	result, err := tx.ExecContext(ctx, d.rebind(
+		"UPDATE auctions SET end_time = timestamp * 1000 + ? WHERE end_time = 0"),
+		config.Current().AuctionDuration.Milliseconds())
+	if err != nil {
+		return err
+	}
+
+	if backfilled, _ := result.RowsAffected(); backfilled > 0 {
+		logger.Info(fmt.Sprintf("Backfilled the end time of %d auctions", backfilled))
+	}
+	return nil
+}
+
+// CloseExpiredAuctions completes every active auction whose end time has
+// passed.
+func (ar *AuctionRepository) CloseExpiredAuctions() {
+	result, err := ar.Database.DB.ExecContext(context.Background(), ar.Database.rebind(
+		"UPDATE auctions SET status = ? WHERE status = ? AND end_time <= ?"),
+		auctionStatusToSQL[auction_entity.Completed], auctionStatusToSQL[auction_entity.Active],
+		ar.clock.Now().UnixMilli())
+	if err != nil {
+		logger.Error("Error closing expired auctions", err)
+		return
+	}
+
+	if closed, _ := result.RowsAffected(); closed > 0 {
+		logger.Info(fmt.Sprintf("Closed %d expired auctions", closed))
+	}
+}
+
+// CloseAuction completes an auction if it is still active and its end time
+// has passed, reporting whether it was closed. A fence is checked in the same
+// statement, so the lease cannot change hands between the check and the
+// update.
+func (ar *AuctionRepository) CloseAuction(
+	ctx context.Context, id string, fence lease_entity.Fence) (bool, *internal_error.InternalError) {
+	query := "UPDATE auctions SET status = ? WHERE id = ? AND status = ? AND end_time <= ?"
+	args := []interface{}{auctionStatusToSQL[auction_entity.Completed], id,
+		auctionStatusToSQL[auction_entity.Active], ar.clock.Now().UnixMilli()}
+	if !fence.IsZero() {
+		query += " AND EXISTS (SELECT 1 FROM leases WHERE name = ? AND token = ?)"
+		args = append(args, fence.Lease, fence.Token)
+	}
+
+	result, err := ar.Database.DB.ExecContext(ctx, ar.Database.rebind(query), args...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to close auction %s", id), err)
+		return false, internal_error.NewInternalServerError("Error trying to close auction")
+	}
+
+	closed, _ := result.RowsAffected()
+	return closed > 0, nil
+}
+
+// StartAuction opens a scheduled auction for bids, reporting whether it did.
+func (ar *AuctionRepository) StartAuction(
+	ctx context.Context, id string) (bool, *internal_error.InternalError) {
+	result, err := ar.Database.DB.ExecContext(ctx, ar.Database.rebind(
+		"UPDATE auctions SET status = ? WHERE id = ? AND status = ?"),
+		auctionStatusToSQL[auction_entity.Active], id, auctionStatusToSQL[auction_entity.Scheduled])
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to start auction %s", id), err)
+		return false, internal_error.NewInternalServerError("Error trying to start auction")
+	}
+
+	started, _ := result.RowsAffected()
+	return started > 0, nil
+}
+
+// ExtendAuction moves the end time of an active auction forward, under the
+// same row lock bids take so none is accepted past the previous end time.
+func (ar *AuctionRepository) ExtendAuction(
+	ctx context.Context,
+	id string,
+	endTime time.Time) (*auction_entity.Auction, *internal_error.InternalError) {
+	var extendedAuction *auction_entity.Auction
+
+	err := ar.Database.withTx(ctx, func(tx *sql.Tx) error {
+		auction, err := scanAuction(tx.QueryRowContext(ctx, ar.Database.rebind(
+			"SELECT "+auctionColumns+" FROM auctions WHERE id = ?"+ar.Database.forUpdate()), id))
+		if err != nil {
+			return err
+		}
+		if auction.Status != auction_entity.Active || !auction.EndTime.After(ar.clock.Now()) {
+			return errAuctionNotAccepting
+		}
+		if !endTime.After(auction.EndTime) {
+			return errEndTimeNotAfter
+		}
+
+		if _, err := tx.ExecContext(ctx, ar.Database.rebind(
+			"UPDATE auctions SET end_time = ? WHERE id = ?"), endTime.UnixMilli(), id); err != nil {
+			return err
+		}
+
+		auction.EndTime = time.UnixMilli(endTime.UnixMilli())
+		auctions := []auction_entity.Auction{*auction}
+		if err := ar.Database.loadImages(ctx, tx, auctions); err != nil {
+			return err
+		}
+		extendedAuction = &auctions[0]
+		return nil
+	})
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	} else if errors.Is(err, errAuctionNotAccepting) {
+		return nil, internal_error.NewBadRequestError("Only active auctions can be extended")
+	} else if errors.Is(err, errEndTimeNotAfter) {
+		return nil, internal_error.NewBadRequestError("End time must be after the current end time")
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to extend auction %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to extend auction")
+	}
+
+	return extendedAuction, nil
+}
+
+// ForceCloseAuction completes an active auction right away, under the same
+// row lock bids and the other status changes take.
+func (ar *AuctionRepository) ForceCloseAuction(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	var closedAuction *auction_entity.Auction
+
+	err := ar.Database.withTx(ctx, func(tx *sql.Tx) error {
+		auction, err := scanAuction(tx.QueryRowContext(ctx, ar.Database.rebind(
+			"SELECT "+auctionColumns+" FROM auctions WHERE id = ?"+ar.Database.forUpdate()), id))
+		if err != nil {
+			return err
+		}
+		if auction.Status != auction_entity.Active {
+			return errAuctionNotAccepting
+		}
+
+		endTime := ar.clock.Now().UnixMilli()
+		if _, err := tx.ExecContext(ctx, ar.Database.rebind(
+			"UPDATE auctions SET status = ?, end_time = ? WHERE id = ?"),
+			auctionStatusToSQL[auction_entity.Completed], endTime, id); err != nil {
+			return err
+		}
+
+		auction.Status = auction_entity.Completed
+		auction.EndTime = time.UnixMilli(endTime)
+		auctions := []auction_entity.Auction{*auction}
+		if err := ar.Database.loadImages(ctx, tx, auctions); err != nil {
+			return err
+		}
+		closedAuction = &auctions[0]
+		return nil
+	})
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	} else if errors.Is(err, errAuctionNotAccepting) {
+		return nil, internal_error.NewBadRequestError("Only active auctions can be closed")
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to close auction %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to close auction")
+	}
+
+	logger.Info(fmt.Sprintf("Auction %s closed", id))
+
+	return closedAuction, nil
+}
+
+func (ar *AuctionRepository) UpdateBidStats(
+	ctx context.Context,
+	id string,
+	highestBid float64,
+	bidCount int64) *internal_error.InternalError {
+	result, err := ar.Database.DB.ExecContext(ctx, ar.Database.rebind(
+		"UPDATE auctions SET highest_bid = ?, bid_count = ? WHERE id = ?"), highestBid, bidCount, id)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to update bid stats of auction %s", id), err)
+		return internal_error.NewInternalServerError("Error trying to update auction bid stats")
+	}
+
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return internal_error.NewNotFoundError("Auction not found")
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) RenameCategory(
+	ctx context.Context, categoryId, name string) *internal_error.InternalError {
+	_, err := ar.Database.DB.ExecContext(ctx, ar.Database.rebind(
+		"UPDATE auctions SET category = ?, category_tokens = ? WHERE category_id = ?"),
+		name, searchTokens(name), categoryId)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to rename category %s of auctions", categoryId), err)
+		return internal_error.NewInternalServerError("Error trying to rename the category of auctions")
+	}
+
+	return nil
+}
+
+// FindAuctionEndTimes lists the end time of every active auction.
+func (ar *AuctionRepository) FindAuctionEndTimes(
+	ctx context.Context) ([]auction_entity.AuctionEndTime, *internal_error.InternalError) {
+	rows, err := ar.Database.DB.QueryContext(ctx, ar.Database.rebind(
+		"SELECT id, end_time FROM auctions WHERE status = ?"), auctionStatusToSQL[auction_entity.Active])
+	if err != nil {
+		logger.Error("Error trying to find auction end times", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction end times")
+	}
+	defer rows.Close()
+
+	var endTimes []auction_entity.AuctionEndTime
+	for rows.Next() {
+		var auctionId string
+		var endTime int64
+		if err := rows.Scan(&auctionId, &endTime); err != nil {
+			logger.Error("Error trying to scan auction end time", err)
+			return nil, internal_error.NewInternalServerError("Error trying to find auction end times")
+		}
+
+		endTimes = append(endTimes, auction_entity.AuctionEndTime{
+			AuctionId: auctionId,
+			EndTime:   time.UnixMilli(endTime),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Error trying to find auction end times", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction end times")
+	}
+
+	return endTimes, nil
+}
+
+// FindOverdueScheduledAuctions lists the ids of the scheduled auctions whose
+// start time is not after cutoff, through the status and starts_at index.
+func (ar *AuctionRepository) FindOverdueScheduledAuctions(
+	ctx context.Context, cutoff time.Time) ([]string, *internal_error.InternalError) {
+	rows, err := ar.Database.DB.QueryContext(ctx, ar.Database.rebind(
+		"SELECT id FROM auctions WHERE status = ? AND starts_at <= ?"),
+		auctionStatusToSQL[auction_entity.Scheduled], cutoff.UnixMilli())
+	if err != nil {
+		logger.Error("Error trying to find overdue scheduled auctions", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find overdue scheduled auctions")
+	}
+	defer rows.Close()
+
+	var auctionIds []string
+	for rows.Next() {
+		var auctionId string
+		if err := rows.Scan(&auctionId); err != nil {
+			logger.Error("Error trying to scan overdue scheduled auction", err)
+			return nil, internal_error.NewInternalServerError("Error trying to find overdue scheduled auctions")
+		}
+
+		auctionIds = append(auctionIds, auctionId)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Error trying to find overdue scheduled auctions", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find overdue scheduled auctions")
+	}
+
+	return auctionIds, nil
+}
+
+func (ar *AuctionRepository) CreateAuction(
+	ctx context.Context, auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	if err := ar.Database.insertAuction(ctx, ar.Database.DB, auctionEntity); err != nil {
+		logger.Error("Error trying to insert auction", err)
+		return internal_error.NewInternalServerError("Error trying to insert auction")
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) CreateAuctions(
+	ctx context.Context, auctionEntities []*auction_entity.Auction) *internal_error.InternalError {
+	err := ar.Database.withTx(ctx, func(tx *sql.Tx) error {
+		for _, auctionEntity := range auctionEntities {
+			if err := ar.Database.insertAuction(ctx, tx, auctionEntity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Error trying to insert auctions", err)
+		return internal_error.NewInternalServerError("Error trying to insert auctions")
+	}
+
+	return nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func (d *Database) insertAuction(ctx context.Context, e execer, auctionEntity *auction_entity.Auction) error {
+	attributes, err := marshalAttributes(auctionEntity.Attributes)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.ExecContext(ctx, d.rebind(
+		"INSERT INTO auctions ("+auctionColumns+", product_name_tokens, category_tokens, description_tokens) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, 0, ?, ?, ?, ?)"),
+		auctionEntity.Id,
+		auctionEntity.ProductName,
+		auctionEntity.CategoryId,
+		auctionEntity.Category,
+		auctionEntity.Description,
+		auctionEntity.Condition,
+		auctionStatusToSQL[auctionEntity.Status],
+		auctionEntity.Timestamp.Unix(),
+		auctionEntity.StartsAt.UnixMilli(),
+		auctionEntity.EndTime.UnixMilli(),
+		attributes,
+		searchTokens(auctionEntity.ProductName),
+		searchTokens(auctionEntity.Category),
+		searchTokens(auctionEntity.Description))
+	return err
+}
+
+func (ar *AuctionRepository) FindAuctionById(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	row := ar.Database.DB.QueryRowContext(ctx, ar.Database.rebind(
+		"SELECT "+auctionColumns+" FROM auctions WHERE id = ?"), id)
+
+	auction, err := scanAuction(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Info(fmt.Sprintf("Auction not found with id = %s", id))
+			return nil, internal_error.NewNotFoundError("Auction not found")
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find auction by id = %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction by id")
+	}
+
+	auctions := []auction_entity.Auction{*auction}
+	if err := ar.loadImages(ctx, auctions); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find images of auction %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction by id")
+	}
+
+	return &auctions[0], nil
+}
+
+func (ar *AuctionRepository) FindAuctionState(
+	ctx context.Context, id string) (*auction_entity.AuctionState, *internal_error.InternalError) {
+	var status string
+	var startsAt, endTime int64
+	err := ar.Database.DB.QueryRowContext(ctx, ar.Database.rebind(
+		"SELECT status, starts_at, end_time FROM auctions WHERE id = ?"), id).Scan(&status, &startsAt, &endTime)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Info(fmt.Sprintf("Auction not found with id = %s", id))
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find status of auction %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction by id")
+	}
+
+	return &auction_entity.AuctionState{
+		Status:   auctionStatusFromSQL[status],
+		StartsAt: time.UnixMilli(startsAt),
+		EndTime:  time.UnixMilli(endTime),
+	}, nil
+}
+
+func (ar *AuctionRepository) FindAuctions(
+	ctx context.Context,
+	auctionFilter auction_entity.AuctionFilter) (*auction_entity.AuctionPage, *internal_error.InternalError) {
+	matched, args := ar.matchedAuctionsQuery(auctionFilter)
+
+	logger.Info(fmt.Sprintf("Finding auctions with filter = %+v", auctionFilter))
+
+	var total int64
+	if err := ar.Database.DB.QueryRowContext(ctx, ar.Database.rebind(
+		"SELECT COUNT(*) FROM ("+matched+") matched"), args...).Scan(&total); err != nil {
+		logger.Error("Error counting auctions", err)
+		return nil, internal_error.NewInternalServerError("Error finding auctions")
+	}
+
+	sortDirection, cursorOperator := "ASC", ">"
+	if auctionFilter.Sort.Descending() {
+		sortDirection, cursorOperator = "DESC", "<"
+	}
+
+	query := "SELECT " + auctionColumns + ", sort_value FROM (" + matched + ") matched"
+	if auctionFilter.After != "" {
+		cursor, err := auction_entity.DecodeAuctionCursor(auctionFilter.After)
+		if err != nil {
+			return nil, err
+		}
+
+		query += " WHERE (sort_value " + cursorOperator + " ? OR (sort_value = ? AND id > ?))"
+		args = append(args, cursor.Value, cursor.Value, cursor.Id)
+	}
+
+	query += " ORDER BY sort_value " + sortDirection + ", id ASC LIMIT ?"
+	args = append(args, auctionFilter.Limit+1)
+
+	rows, err := ar.Database.DB.QueryContext(ctx, ar.Database.rebind(query), args...)
+	if err != nil {
+		logger.Error("Error finding auctions", err)
+		return nil, internal_error.NewInternalServerError("Error finding auctions")
+	}
+	defer rows.Close()
+
+	auctionPage := &auction_entity.AuctionPage{
+		Auctions: []auction_entity.Auction{},
+		Total:    total,
+	}
+
+	var sortValues []float64
+	for rows.Next() {
+		var sortValue float64
+		auction, err := scanAuction(rows, &sortValue)
+		if err != nil {
+			logger.Error("Error decoding auctions", err)
+			return nil, internal_error.NewInternalServerError("Error decoding auctions")
+		}
+
+		auctionPage.Auctions = append(auctionPage.Auctions, *auction)
+		sortValues = append(sortValues, sortValue)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Error finding auctions", err)
+		return nil, internal_error.NewInternalServerError("Error finding auctions")
+	}
+
+	if int64(len(auctionPage.Auctions)) > auctionFilter.Limit {
+		last := auctionFilter.Limit - 1
+		auctionPage.Auctions = auctionPage.Auctions[:auctionFilter.Limit]
+		auctionPage.NextCursor = auction_entity.AuctionCursor{
+			Value: sortValues[last],
+			Id:    auctionPage.Auctions[last].Id,
+		}.Encode()
+	}
+
+	if err := ar.loadImages(ctx, auctionPage.Auctions); err != nil {
+		logger.Error("Error finding auction images", err)
+		return nil, internal_error.NewInternalServerError("Error finding auctions")
+	}
+
+	return auctionPage, nil
+}
+
+// matchedAuctionsQuery builds the query selecting every auction matching the
+// filter along with its sort_value, before cursor pagination.
+func (ar *AuctionRepository) matchedAuctionsQuery(
+	auctionFilter auction_entity.AuctionFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	includeTerms, excludeTerms := parseTextQuery(auctionFilter.Query)
+
+	sortValue := "timestamp"
+	switch auctionFilter.Sort {
+	case auction_entity.SortHighestBid:
+		sortValue = "highest_bid"
+	case auction_entity.SortMostBids:
+		sortValue = "bid_count"
+	case auction_entity.SortEndingSoonest:
+		sortValue = "end_time"
+	case auction_entity.SortStartingSoonest:
+		sortValue = "starts_at"
+	}
+
+	if auctionFilter.Query != "" {
+		var scores []string
+		var scoreArgs []interface{}
+		for _, term := range includeTerms {
+			pattern := "% " + likePattern(term) + " %"
+			scores = append(scores,
+				fmt.Sprintf(`CASE WHEN product_name_tokens LIKE ? ESCAPE '\' THEN %d ELSE 0 END`, productNameWeight),
+				fmt.Sprintf(`CASE WHEN category_tokens LIKE ? ESCAPE '\' THEN %d ELSE 0 END`, categoryWeight),
+				fmt.Sprintf(`CASE WHEN description_tokens LIKE ? ESCAPE '\' THEN %d ELSE 0 END`, descriptionWeight))
+			scoreArgs = append(scoreArgs, pattern, pattern, pattern)
+		}
+
+		score := "0"
+		if len(scores) > 0 {
+			score = strings.Join(scores, " + ")
+		}
+
+		conditions = append(conditions, "("+score+") > 0")
+		args = append(args, scoreArgs...)
+
+		if auctionFilter.Sort == auction_entity.SortRelevance {
+			sortValue = "(" + score + ")"
+			args = append(scoreArgs, args...)
+		}
+
+		for _, term := range excludeTerms {
+			pattern := "% " + likePattern(term) + " %"
+			conditions = append(conditions, `NOT (product_name_tokens LIKE ? ESCAPE '\' OR `+
+				`category_tokens LIKE ? ESCAPE '\' OR description_tokens LIKE ? ESCAPE '\')`)
+			args = append(args, pattern, pattern, pattern)
+		}
+	}
+
+	if auctionFilter.Status != nil {
+		conditions = append(conditions, "status = ?")
+		args = append(args, auctionStatusToSQL[*auctionFilter.Status])
+	}
+
+	if len(auctionFilter.CategoryIds) > 0 {
+		conditions = append(conditions, "category_id IN ("+placeholders(len(auctionFilter.CategoryIds))+")")
+		for _, categoryId := range auctionFilter.CategoryIds {
+			args = append(args, categoryId)
+		}
+	}
+
+	if auctionFilter.ProductName != "" {
+		conditions = append(conditions, `LOWER(product_name) LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likePattern(auctionFilter.ProductName)+"%")
+	}
+
+	if auctionFilter.CreatedFrom != nil {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, auctionFilter.CreatedFrom.Unix())
+	}
+
+	if auctionFilter.CreatedTo != nil {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, auctionFilter.CreatedTo.Unix())
+	}
+
+	for _, attributeFilter := range auctionFilter.Attributes {
+		condition, conditionArgs := ar.Database.attributeCondition(attributeFilter)
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+
+	query := "SELECT " + auctionColumns + ", " + sortValue + " AS sort_value FROM auctions"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	return query, args
+}
+
+func (ar *AuctionRepository) ExportAuctions(
+	ctx context.Context,
+	exportFilter auction_entity.AuctionExportFilter,
+	fn func(auction *auction_entity.Auction) *internal_error.InternalError) *internal_error.InternalError {
+	logger.Info(fmt.Sprintf("Exporting auctions with filter = %+v", exportFilter))
+
+	where, args := exportConditions(exportFilter, "")
+	rows, err := ar.Database.DB.QueryContext(ctx, ar.Database.rebind(
+		"SELECT "+auctionColumns+" FROM auctions"+where+" ORDER BY timestamp, id"), args...)
+	if err != nil {
+		logger.Error("Error exporting auctions", err)
+		return internal_error.NewInternalServerError("Error exporting auctions")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		auction, err := scanAuction(rows)
+		if err != nil {
+			logger.Error("Error decoding exported auction", err)
+			return internal_error.NewInternalServerError("Error exporting auctions")
+		}
+
+		if err := fn(auction); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error("Error exporting auctions", err)
+		return internal_error.NewInternalServerError("Error exporting auctions")
+	}
+
+	return nil
+}
+
+// exportConditions builds the WHERE clause selecting the auctions of an
+// export, with their columns qualified by prefix.
+func exportConditions(
+	exportFilter auction_entity.AuctionExportFilter, prefix string) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if exportFilter.Status != nil {
+		conditions = append(conditions, prefix+"status = ?")
+		args = append(args, auctionStatusToSQL[*exportFilter.Status])
+	}
+
+	if len(exportFilter.CategoryIds) > 0 {
+		conditions = append(conditions,
+			prefix+"category_id IN ("+placeholders(len(exportFilter.CategoryIds))+")")
+		for _, categoryId := range exportFilter.CategoryIds {
+			args = append(args, categoryId)
+		}
+	}
+
+	if exportFilter.CreatedFrom != nil {
+		conditions = append(conditions, prefix+"timestamp >= ?")
+		args = append(args, exportFilter.CreatedFrom.Unix())
+	}
+
+	if exportFilter.CreatedTo != nil {
+		conditions = append(conditions, prefix+"timestamp <= ?")
+		args = append(args, exportFilter.CreatedTo.Unix())
+	}
+
+	if exportFilter.EndedFrom != nil {
+		conditions = append(conditions, prefix+"end_time >= ?")
+		args = append(args, exportFilter.EndedFrom.UnixMilli())
+	}
+
+	if exportFilter.EndedTo != nil {
+		conditions = append(conditions, prefix+"end_time <= ?")
+		args = append(args, exportFilter.EndedTo.UnixMilli())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// attributeCondition matches a JSON attribute with the same type rules as
+// the other backends: equality is type-aware and ranges only match numbers.
+func (d *Database) attributeCondition(filter auction_entity.AttributeFilter) (string, []interface{}) {
+	if d.driver == Postgres {
+		if filter.Operator == auction_entity.AttributeEq {
+			var conditions []string
+			var args []interface{}
+			for _, value := range filter.Values {
+				encoded, _ := json.Marshal(value)
+				conditions = append(conditions, "attributes -> ? = ?::jsonb")
+				args = append(args, filter.Key, string(encoded))
+			}
+			return "(" + orFalse(conditions) + ")", args
+		}
+
+		return "CASE WHEN jsonb_typeof(attributes -> ?) = 'number' " +
+				"THEN (attributes ->> ?)::float8 END " + sqlOperator(filter.Operator) + " ?",
+			[]interface{}{filter.Key, filter.Key, firstValue(filter)}
+	}
+
+	attributePath := "$." + filter.Key
+	if filter.Operator == auction_entity.AttributeEq {
+		var conditions []string
+		var args []interface{}
+		for _, value := range filter.Values {
+			switch typed := value.(type) {
+			case bool:
+				conditions = append(conditions, "json_type(attributes, ?) = ?")
+				args = append(args, attributePath, fmt.Sprint(typed))
+			case float64:
+				conditions = append(conditions, "(json_type(attributes, ?) IN ('integer', 'real') "+
+					"AND json_extract(attributes, ?) = ?)")
+				args = append(args, attributePath, attributePath, typed)
+			default:
+				conditions = append(conditions, "(json_type(attributes, ?) = 'text' "+
+					"AND json_extract(attributes, ?) = ?)")
+				args = append(args, attributePath, attributePath, fmt.Sprint(typed))
+			}
+		}
+		return "(" + orFalse(conditions) + ")", args
+	}
+
+	return "(json_type(attributes, ?) IN ('integer', 'real') AND json_extract(attributes, ?) " +
+			sqlOperator(filter.Operator) + " ?)",
+		[]interface{}{attributePath, attributePath, firstValue(filter)}
+}
+
+func (ar *AuctionRepository) SuggestProductNames(
+	ctx context.Context,
+	prefix string,
+	limit int64) ([]string, *internal_error.InternalError) {
+	rows, err := ar.Database.DB.QueryContext(ctx, ar.Database.rebind(
+		`SELECT DISTINCT product_name FROM auctions WHERE LOWER(product_name) LIKE ? ESCAPE '\' `+
+			"ORDER BY "+ar.Database.collate("product_name")+" LIMIT ?"),
+		likePattern(prefix)+"%", limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error suggesting product names for prefix %s", prefix), err)
+		return nil, internal_error.NewInternalServerError("Error suggesting product names")
+	}
+	defer rows.Close()
+
+	suggestions := []string{}
+	for rows.Next() {
+		var productName string
+		if err := rows.Scan(&productName); err != nil {
+			logger.Error(fmt.Sprintf("Error decoding product name suggestions for prefix %s", prefix), err)
+			return nil, internal_error.NewInternalServerError("Error suggesting product names")
+		}
+		suggestions = append(suggestions, productName)
+	}
+
+	return suggestions, nil
+}
+
+func (ar *AuctionRepository) AddAuctionImage(
+	ctx context.Context,
+	auctionId string,
+	image auction_entity.AuctionImage,
+	maxImages int) *internal_error.InternalError {
+	err := ar.Database.withTx(ctx, func(tx *sql.Tx) error {
+		var status string
+		err := tx.QueryRowContext(ctx, ar.Database.rebind(
+			"SELECT status FROM auctions WHERE id = ?"+ar.Database.forUpdate()), auctionId).Scan(&status)
+		if errors.Is(err, sql.ErrNoRows) || err == nil && !isOpenStatus(auctionStatusFromSQL[status]) {
+			return errAuctionNotAccepting
+		} else if err != nil {
+			return err
+		}
+
+		var count, lastPosition int64
+		if err := tx.QueryRowContext(ctx, ar.Database.rebind(
+			"SELECT COUNT(*), COALESCE(MAX(position), -1) FROM auction_images WHERE auction_id = ?"),
+			auctionId).Scan(&count, &lastPosition); err != nil {
+			return err
+		}
+		if count >= int64(maxImages) {
+			return errAuctionNotAccepting
+		}
+
+		_, err = tx.ExecContext(ctx, ar.Database.rebind(
+			"INSERT INTO auction_images (id, auction_id, position, storage_key, thumbnail_key, "+
+				"content_type, size, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"),
+			image.Id, auctionId, lastPosition+1, image.Key, image.ThumbnailKey,
+			image.ContentType, image.Size, image.Timestamp.Unix())
+		return err
+	})
+
+	if errors.Is(err, errAuctionNotAccepting) {
+		return internal_error.NewBadRequestError(fmt.Sprintf(
+			"Auction must be active or scheduled and have less than %d images", maxImages))
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to add image to auction %s", auctionId), err)
+		return internal_error.NewInternalServerError("Error trying to add auction image")
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) RemoveAuctionImage(
+	ctx context.Context,
+	auctionId, imageId string) *internal_error.InternalError {
+	result, err := ar.Database.DB.ExecContext(ctx, ar.Database.rebind(
+		"DELETE FROM auction_images WHERE auction_id = ? AND id = ?"), auctionId, imageId)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to remove image %s from auction %s", imageId, auctionId), err)
+		return internal_error.NewInternalServerError("Error trying to remove auction image")
+	}
+
+	if removed, _ := result.RowsAffected(); removed == 0 {
+		return internal_error.NewNotFoundError("Auction image not found")
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) CancelAuction(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	var cancelledAuction *auction_entity.Auction
+	err := ar.Database.withTx(ctx, func(tx *sql.Tx) error {
+		auction, err := scanAuction(tx.QueryRowContext(ctx, ar.Database.rebind(
+			"SELECT "+auctionColumns+" FROM auctions WHERE id = ?"+ar.Database.forUpdate()), id))
+		if err != nil {
+			return err
+		}
+		if !isOpenStatus(auction.Status) {
+			return errAuctionNotAccepting
+		}
+
+		auctions := []auction_entity.Auction{*auction}
+		if err := ar.Database.loadImages(ctx, tx, auctions); err != nil {
+			return err
+		}
+		cancelledAuction = &auctions[0]
+
+		if _, err := tx.ExecContext(ctx, ar.Database.rebind(
+			"UPDATE auctions SET status = ? WHERE id = ?"),
+			auctionStatusToSQL[auction_entity.Cancelled], id); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, ar.Database.rebind(
+			"DELETE FROM auction_images WHERE auction_id = ?"), id)
+		return err
+	})
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	} else if errors.Is(err, errAuctionNotAccepting) {
+		return nil, internal_error.NewBadRequestError("Only active or scheduled auctions can be cancelled")
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to cancel auction %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to cancel auction")
+	}
+
+	logger.Info(fmt.Sprintf("Auction %s cancelled", id))
+
+	return cancelledAuction, nil
+}
+
+func (ar *AuctionRepository) loadImages(ctx context.Context, auctions []auction_entity.Auction) error {
+	return ar.Database.loadImages(ctx, ar.Database.DB, auctions)
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func (d *Database) loadImages(ctx context.Context, q queryer, auctions []auction_entity.Auction) error {
+	if len(auctions) == 0 {
+		return nil
+	}
+
+	positions := map[string]int{}
+	args := make([]interface{}, 0, len(auctions))
+	for i := range auctions {
+		auctions[i].Images = []auction_entity.AuctionImage{}
+		positions[auctions[i].Id] = i
+		args = append(args, auctions[i].Id)
+	}
+
+	rows, err := q.QueryContext(ctx, d.rebind(
+		"SELECT auction_id, id, storage_key, thumbnail_key, content_type, size, timestamp "+
+			"FROM auction_images WHERE auction_id IN ("+placeholders(len(args))+") "+
+			"ORDER BY auction_id, position"), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var auctionId string
+		var image auction_entity.AuctionImage
+		var timestamp int64
+		if err := rows.Scan(&auctionId, &image.Id, &image.Key, &image.ThumbnailKey,
+			&image.ContentType, &image.Size, &timestamp); err != nil {
+			return err
+		}
+
+		image.Timestamp = time.Unix(timestamp, 0)
+		auction := &auctions[positions[auctionId]]
+		auction.Images = append(auction.Images, image)
+	}
+
+	return rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAuction(row scanner, extra ...interface{}) (*auction_entity.Auction, error) {
+	var auction auction_entity.Auction
+	var status, attributes string
+	var timestamp, startsAt, endTime int64
+
+	dest := append([]interface{}{
+		&auction.Id,
+		&auction.ProductName,
+		&auction.CategoryId,
+		&auction.Category,
+		&auction.Description,
+		&auction.Condition,
+		&status,
+		&timestamp,
+		&startsAt,
+		&endTime,
+		&auction.HighestBid,
+		&auction.BidCount,
+		&attributes,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	auction.Status = auctionStatusFromSQL[status]
+	auction.Timestamp = time.Unix(timestamp, 0)
+	auction.StartsAt = time.UnixMilli(startsAt)
+	auction.EndTime = time.UnixMilli(endTime)
+
+	if err := json.Unmarshal([]byte(attributes), &auction.Attributes); err != nil {
+		return nil, err
+	}
+	if len(auction.Attributes) == 0 {
+		auction.Attributes = nil
+	}
+
+	return &auction, nil
+}
+
+// isOpenStatus reports whether an auction with status is not finished yet.
+func isOpenStatus(status auction_entity.AuctionStatus) bool {
+	return status == auction_entity.Active || status == auction_entity.Scheduled
+}
+
+func marshalAttributes(attributes map[string]interface{}) (string, error) {
+	if attributes == nil {
+		return "{}", nil
+	}
+
+	encoded, err := json.Marshal(attributes)
+	return string(encoded), err
+}
+
+// parseTextQuery splits a search query into lowercase, accent-free terms.
+// Terms prefixed with "-" exclude matching auctions, like Mongo's $text.
+func parseTextQuery(query string) ([]string, []string) {
+	var includeTerms, excludeTerms []string
+	for _, field := range strings.Fields(query) {
+		exclude := strings.HasPrefix(field, "-")
+		for _, term := range textTokens(strings.TrimPrefix(field, "-")) {
+			if exclude {
+				excludeTerms = append(excludeTerms, term)
+			} else {
+				includeTerms = append(includeTerms, term)
+			}
+		}
+	}
+
+	return includeTerms, excludeTerms
+}
+
+// searchTokens stores the text tokens of a field between spaces so a term
+// can be matched as a whole word with LIKE '% term %'.
+func searchTokens(text string) string {
+	return " " + strings.Join(textTokens(text), " ") + " "
+}
+
+func textTokens(text string) []string {
+	slug := category_entity.Slugify(text)
+	if slug == "" {
+		return nil
+	}
+
+	return strings.Split(slug, "-")
+}
+
+func sqlOperator(operator auction_entity.AttributeOperator) string {
+	switch operator {
+	case auction_entity.AttributeGt:
+		return ">"
+	case auction_entity.AttributeGte:
+		return ">="
+	case auction_entity.AttributeLt:
+		return "<"
+	case auction_entity.AttributeLte:
+		return "<="
+	}
+
+	return "="
+}
+
+func firstValue(filter auction_entity.AttributeFilter) interface{} {
+	if len(filter.Values) == 0 {
+		return nil
+	}
+	return filter.Values[0]
+}
+
+func orFalse(conditions []string) string {
+	if len(conditions) == 0 {
+		return "1 = 0"
+	}
+	return strings.Join(conditions, " OR ")
+}
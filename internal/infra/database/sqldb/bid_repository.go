@@ -0,0 +1,305 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+const bidColumns = "id, user_id, auction_id, amount, timestamp"
+
+type BidRepository struct {
+	Database *Database
+	clock    clock.Clock
+}
+
+func NewBidRepository(database *Database, clock clock.Clock) *BidRepository {
+	return &BidRepository{Database: database, clock: clock}
+}
+
+// CreateBid stores the bids of each auction in one transaction holding the
+// auction row lock, so concurrent bids cannot land after the auction stops
+// being active. Bids are idempotent by id, so replaying a journaled bid
+// stores it once.
+func (bd *BidRepository) CreateBid(
+	ctx context.Context, bidEntities []bid_entity.Bid) ([]bid_entity.BidResult, *internal_error.InternalError) {
+	results := make([]bid_entity.BidResult, len(bidEntities))
+	for i, bid := range bidEntities {
+		results[i].BidId = bid.Id
+	}
+
+	for _, group := range groupBidsByAuction(bidEntities) {
+		auctionId := bidEntities[group[0]].AuctionId
+
+		err := bd.Database.withTx(ctx, func(tx *sql.Tx) error {
+			return bd.createAuctionBids(ctx, tx, bidEntities, group, results)
+		})
+
+		if errors.Is(err, errAuctionNotAccepting) {
+			logger.Info(fmt.Sprintf("Auction %s is not active, bids rejected", auctionId))
+			setBidResults(results, group, bid_entity.BidRejected, nil)
+		} else if errors.Is(err, sql.ErrNoRows) {
+			logger.Info(fmt.Sprintf("Auction not found with id = %s", auctionId))
+			setBidResults(results, group, bid_entity.BidRejected,
+				internal_error.NewNotFoundError("Auction not found"))
+		} else if err != nil {
+			logger.Error(fmt.Sprintf("Error inserting bids of auction %s", auctionId), err)
+			setBidResults(results, group, bid_entity.BidFailed,
+				internal_error.NewInternalServerError("Erro ao inserir bid no banco de dados"))
+		}
+	}
+
+	return results, nil
+}
+
+// createAuctionBids inserts the bids at indexes of a single auction and
+// updates its stats once for all of them.
+func (bd *BidRepository) createAuctionBids(
+	ctx context.Context,
+	tx *sql.Tx,
+	bidEntities []bid_entity.Bid,
+	indexes []int,
+	results []bid_entity.BidResult) error {
+	auctionId := bidEntities[indexes[0]].AuctionId
+
+	var status string
+	var endTime int64
+	if err := tx.QueryRowContext(ctx, bd.Database.rebind(
+		"SELECT status, end_time FROM auctions WHERE id = ?"+bd.Database.forUpdate()),
+		auctionId).Scan(&status, &endTime); err != nil {
+		return err
+	}
+
+	if status != auctionStatusToSQL[auction_entity.Active] || endTime <= bd.clock.Now().UnixMilli() {
+		return errAuctionNotAccepting
+	}
+
+	inserted, highestBid := 0, 0.0
+	for _, i := range indexes {
+		bid := bidEntities[i]
+		result, err := tx.ExecContext(ctx, bd.Database.rebind(
+			"INSERT INTO bids ("+bidColumns+") VALUES (?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING"),
+			bid.Id, bid.UserId, bid.AuctionId, bid.Amount, bid.Timestamp.Unix())
+		if err != nil {
+			return err
+		}
+
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			logger.Info(fmt.Sprintf("Bid %s already stored, skipping", bid.Id))
+			results[i].Status = bid_entity.BidDuplicate
+			continue
+		}
+
+		results[i].Status = bid_entity.BidStored
+		inserted++
+		if bid.Amount > highestBid {
+			highestBid = bid.Amount
+		}
+	}
+
+	if inserted == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, bd.Database.rebind(
+		"UPDATE auctions SET bid_count = bid_count + ?, "+
+			"highest_bid = CASE WHEN highest_bid < ? THEN ? ELSE highest_bid END WHERE id = ?"),
+		inserted, highestBid, highestBid, auctionId)
+	return err
+}
+
+// groupBidsByAuction returns the indexes of the bids of each auction, in the
+// order the auctions first appear.
+func groupBidsByAuction(bidEntities []bid_entity.Bid) [][]int {
+	var groups [][]int
+	groupOf := map[string]int{}
+	for i, bid := range bidEntities {
+		group, ok := groupOf[bid.AuctionId]
+		if !ok {
+			group = len(groups)
+			groupOf[bid.AuctionId] = group
+			groups = append(groups, nil)
+		}
+		groups[group] = append(groups[group], i)
+	}
+
+	return groups
+}
+
+func setBidResults(
+	results []bid_entity.BidResult,
+	indexes []int,
+	status bid_entity.BidResultStatus,
+	err *internal_error.InternalError) {
+	for _, i := range indexes {
+		results[i].Status, results[i].Err = status, err
+	}
+}
+
+func (bd *BidRepository) FindBidByAuctionId(
+	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
+	bidEntities, err := bd.queryBids(ctx,
+		"SELECT "+bidColumns+" FROM bids WHERE auction_id = ? ORDER BY timestamp, id", auctionId)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
+		return nil, internal_error.NewInternalServerError(
+			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId))
+	}
+
+	return bidEntities, nil
+}
+
+func (bd *BidRepository) FindWinningBidByAuctionId(
+	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	bidEntities, err := bd.queryBids(ctx,
+		"SELECT "+bidColumns+" FROM bids WHERE auction_id = ? "+
+			"ORDER BY amount DESC, timestamp ASC, id ASC LIMIT 1", auctionId)
+	if err != nil {
+		logger.Error("Error trying to find the auction winner", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find the auction winner")
+	}
+
+	if len(bidEntities) == 0 {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("No bids found for auctionId %s", auctionId))
+	}
+
+	return &bidEntities[0], nil
+}
+
+func (bd *BidRepository) FindBidsByUserId(
+	ctx context.Context,
+	userId string,
+	auctionStatus *auction_entity.AuctionStatus,
+	page, limit int64) ([]bid_entity.Bid, int64, *internal_error.InternalError) {
+	from := " FROM bids b JOIN auctions a ON a.id = b.auction_id WHERE b.user_id = ?"
+	args := []interface{}{userId}
+	if auctionStatus != nil {
+		from += " AND a.status = ?"
+		args = append(args, auctionStatusToSQL[*auctionStatus])
+	}
+
+	bidEntities, total, err := bd.queryBidPage(ctx,
+		"SELECT b.id, b.user_id, b.auction_id, b.amount, b.timestamp"+from,
+		"SELECT COUNT(*)"+from,
+		args, page, limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find bids by userId %s", userId), err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find user bids")
+	}
+
+	return bidEntities, total, nil
+}
+
+func (bd *BidRepository) FindWinningBidsByUserId(
+	ctx context.Context,
+	userId string,
+	page, limit int64) ([]bid_entity.Bid, int64, *internal_error.InternalError) {
+	from := " FROM bids b JOIN auctions a ON a.id = b.auction_id " +
+		"WHERE b.user_id = ? AND a.status = ? AND b.id = (" +
+		"SELECT w.id FROM bids w WHERE w.auction_id = b.auction_id " +
+		"ORDER BY w.amount DESC, w.timestamp ASC, w.id ASC LIMIT 1)"
+	args := []interface{}{userId, auctionStatusToSQL[auction_entity.Completed]}
+
+	bidEntities, total, err := bd.queryBidPage(ctx,
+		"SELECT b.id, b.user_id, b.auction_id, b.amount, b.timestamp"+from,
+		"SELECT COUNT(*)"+from,
+		args, page, limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find won auctions of user %s", userId), err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find won auctions")
+	}
+
+	return bidEntities, total, nil
+}
+
+func (bd *BidRepository) ExportBids(
+	ctx context.Context,
+	exportFilter auction_entity.AuctionExportFilter,
+	fn func(bid *bid_entity.Bid) *internal_error.InternalError) *internal_error.InternalError {
+	logger.Info(fmt.Sprintf("Exporting bids with filter = %+v", exportFilter))
+
+	where, args := exportConditions(exportFilter, "a.")
+	rows, err := bd.Database.DB.QueryContext(ctx, bd.Database.rebind(
+		"SELECT b.id, b.user_id, b.auction_id, b.amount, b.timestamp "+
+			"FROM bids b JOIN auctions a ON a.id = b.auction_id"+where+
+			" ORDER BY a.timestamp, a.id, b.timestamp, b.id"), args...)
+	if err != nil {
+		logger.Error("Error exporting bids", err)
+		return internal_error.NewInternalServerError("Error exporting bids")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bid bid_entity.Bid
+		var timestamp int64
+		if err := rows.Scan(&bid.Id, &bid.UserId, &bid.AuctionId, &bid.Amount, &timestamp); err != nil {
+			logger.Error("Error decoding exported bid", err)
+			return internal_error.NewInternalServerError("Error exporting bids")
+		}
+		bid.Timestamp = time.Unix(timestamp, 0)
+
+		if err := fn(&bid); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error("Error exporting bids", err)
+		return internal_error.NewInternalServerError("Error exporting bids")
+	}
+
+	return nil
+}
+
+// queryBidPage returns one page of bids, newest first, along with the total
+// number of bids matched by the count query.
+func (bd *BidRepository) queryBidPage(
+	ctx context.Context,
+	query, countQuery string,
+	args []interface{},
+	page, limit int64) ([]bid_entity.Bid, int64, error) {
+	var total int64
+	if err := bd.Database.DB.QueryRowContext(ctx, bd.Database.rebind(countQuery), args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	bidEntities, err := bd.queryBids(ctx,
+		query+" ORDER BY b.timestamp DESC, b.id ASC LIMIT ? OFFSET ?",
+		append(args, limit, (page-1)*limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return bidEntities, total, nil
+}
+
+func (bd *BidRepository) queryBids(
+	ctx context.Context, query string, args ...interface{}) ([]bid_entity.Bid, error) {
+	rows, err := bd.Database.DB.QueryContext(ctx, bd.Database.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bidEntities := []bid_entity.Bid{}
+	for rows.Next() {
+		var bid bid_entity.Bid
+		var timestamp int64
+		if err := rows.Scan(&bid.Id, &bid.UserId, &bid.AuctionId, &bid.Amount, &timestamp); err != nil {
+			return nil, err
+		}
+
+		bid.Timestamp = time.Unix(timestamp, 0)
+		bidEntities = append(bidEntities, bid)
+	}
+
+	return bidEntities, rows.Err()
+}
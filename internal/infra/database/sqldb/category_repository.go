@@ -0,0 +1,197 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+const categoryColumns = "id, name, slug, parent_id, attributes, timestamp"
+
+type attributeDefinitionSQL struct {
+	Key      string   `json:"key"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"`
+}
+
+type CategoryRepository struct {
+	Database *Database
+}
+
+func NewCategoryRepository(database *Database) *CategoryRepository {
+	return &CategoryRepository{Database: database}
+}
+
+func (cr *CategoryRepository) CreateCategory(
+	ctx context.Context, category *category_entity.Category) *internal_error.InternalError {
+	attributes, err := marshalAttributeDefinitions(category.Attributes)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error encoding attributes of category %s", category.Id), err)
+		return internal_error.NewInternalServerError("Error trying to create category")
+	}
+
+	_, err = cr.Database.DB.ExecContext(ctx, cr.Database.rebind(
+		"INSERT INTO categories ("+categoryColumns+") VALUES (?, ?, ?, ?, ?, ?)"),
+		category.Id, category.Name, category.Slug, nullableString(category.ParentId),
+		attributes, category.Timestamp.Unix())
+	if err != nil {
+		if isUniqueViolation(err) {
+			logger.Info(fmt.Sprintf("Category already exists with slug: %s", category.Slug))
+			return internal_error.NewConflictError("Category already exists with this name")
+		}
+
+		logger.Error(fmt.Sprintf("Error creating category with id: %s", category.Id), err)
+		return internal_error.NewInternalServerError("Error trying to create category")
+	}
+
+	return nil
+}
+
+func (cr *CategoryRepository) UpdateCategory(
+	ctx context.Context, category *category_entity.Category) *internal_error.InternalError {
+	attributes, err := marshalAttributeDefinitions(category.Attributes)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error encoding attributes of category %s", category.Id), err)
+		return internal_error.NewInternalServerError("Error trying to update category")
+	}
+
+	result, err := cr.Database.DB.ExecContext(ctx, cr.Database.rebind(
+		"UPDATE categories SET name = ?, slug = ?, parent_id = ?, attributes = ? WHERE id = ?"),
+		category.Name, category.Slug, nullableString(category.ParentId), attributes, category.Id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			logger.Info(fmt.Sprintf("Category already exists with slug: %s", category.Slug))
+			return internal_error.NewConflictError("Category already exists with this name")
+		}
+
+		logger.Error(fmt.Sprintf("Error updating category with id: %s", category.Id), err)
+		return internal_error.NewInternalServerError("Error trying to update category")
+	}
+
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return internal_error.NewNotFoundError("Category not found")
+	}
+
+	return nil
+}
+
+func (cr *CategoryRepository) DeleteCategory(
+	ctx context.Context, id string) *internal_error.InternalError {
+	result, err := cr.Database.DB.ExecContext(ctx, cr.Database.rebind(
+		"DELETE FROM categories WHERE id = ?"), id)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error deleting category with id: %s", id), err)
+		return internal_error.NewInternalServerError("Error trying to delete category")
+	}
+
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		return internal_error.NewNotFoundError("Category not found")
+	}
+
+	return nil
+}
+
+func (cr *CategoryRepository) FindCategoryById(
+	ctx context.Context, id string) (*category_entity.Category, *internal_error.InternalError) {
+	return cr.findCategory(ctx, "id", id)
+}
+
+func (cr *CategoryRepository) FindCategoryBySlug(
+	ctx context.Context, slug string) (*category_entity.Category, *internal_error.InternalError) {
+	return cr.findCategory(ctx, "slug", slug)
+}
+
+func (cr *CategoryRepository) FindCategories(
+	ctx context.Context) ([]category_entity.Category, *internal_error.InternalError) {
+	rows, err := cr.Database.DB.QueryContext(ctx,
+		"SELECT "+categoryColumns+" FROM categories ORDER BY "+cr.Database.collate("name")+", id")
+	if err != nil {
+		logger.Error("Error trying to find categories", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find categories")
+	}
+	defer rows.Close()
+
+	categories := []category_entity.Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			logger.Error("Error trying to decode categories", err)
+			return nil, internal_error.NewInternalServerError("Error trying to find categories")
+		}
+		categories = append(categories, *category)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Error trying to find categories", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find categories")
+	}
+
+	return categories, nil
+}
+
+func (cr *CategoryRepository) findCategory(
+	ctx context.Context, column, value string) (*category_entity.Category, *internal_error.InternalError) {
+	category, err := scanCategory(cr.Database.DB.QueryRowContext(ctx, cr.Database.rebind(
+		"SELECT "+categoryColumns+" FROM categories WHERE "+column+" = ?"), value))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal_error.NewNotFoundError("Category not found")
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find category by %s = %s", column, value), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find category")
+	}
+
+	return category, nil
+}
+
+func scanCategory(row scanner) (*category_entity.Category, error) {
+	var category category_entity.Category
+	var parentId sql.NullString
+	var attributes string
+	var timestamp int64
+	if err := row.Scan(&category.Id, &category.Name, &category.Slug,
+		&parentId, &attributes, &timestamp); err != nil {
+		return nil, err
+	}
+
+	var definitions []attributeDefinitionSQL
+	if err := json.Unmarshal([]byte(attributes), &definitions); err != nil {
+		return nil, err
+	}
+
+	category.ParentId = parentId.String
+	category.Timestamp = time.Unix(timestamp, 0)
+	category.Attributes = make([]category_entity.AttributeDefinition, 0, len(definitions))
+	for _, definition := range definitions {
+		category.Attributes = append(category.Attributes, category_entity.AttributeDefinition{
+			Key:      definition.Key,
+			Type:     category_entity.AttributeType(definition.Type),
+			Required: definition.Required,
+			Options:  definition.Options,
+		})
+	}
+
+	return &category, nil
+}
+
+func marshalAttributeDefinitions(definitions []category_entity.AttributeDefinition) (string, error) {
+	definitionsSQL := make([]attributeDefinitionSQL, 0, len(definitions))
+	for _, definition := range definitions {
+		definitionsSQL = append(definitionsSQL, attributeDefinitionSQL{
+			Key:      definition.Key,
+			Type:     string(definition.Type),
+			Required: definition.Required,
+			Options:  definition.Options,
+		})
+	}
+
+	encoded, err := json.Marshal(definitionsSQL)
+	return string(encoded), err
+}
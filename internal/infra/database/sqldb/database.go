@@ -0,0 +1,326 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	SQLite   = "sqlite"
+	Postgres = "postgres"
+)
+
+// postgresMigrationLock is the pg_advisory_lock key held while migrating so
+// that several instances starting together apply each migration once.
+const postgresMigrationLock = 7_420_031
+
+//go:embed migrations
+var migrations embed.FS
+
+// errAuctionNotAccepting aborts a transaction touching an auction that no
+// longer accepts the change, so the caller can answer with a client error.
+var (
+	errAuctionNotAccepting = errors.New("auction is not accepting changes")
+	errEndTimeNotAfter     = errors.New("end time is not after the current one")
+	errJobStatus           = errors.New("job is not in a status allowing the change")
+)
+
+type Database struct {
+	DB     *sql.DB
+	driver string
+}
+
+// Open connects to a SQLite or PostgreSQL database and applies any pending
+// schema migration.
+func Open(ctx context.Context, driver, dsn string) (*Database, error) {
+	var driverName string
+	switch driver {
+	case SQLite:
+		driverName = "sqlite"
+	case Postgres:
+		driverName = "postgres"
+	default:
+		return nil, fmt.Errorf("unsupported sql driver %q", driver)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		logger.Error("Error trying to open sql database", err)
+		return nil, err
+	}
+
+	if driver == SQLite {
+		// SQLite serializes writers anyway; a single connection keeps bid
+		// placement transactions from failing with SQLITE_BUSY.
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		logger.Error("Error trying to ping sql database", err)
+		db.Close()
+		return nil, err
+	}
+
+	database := &Database{DB: db, driver: driver}
+	if driver == SQLite {
+		if _, err := db.ExecContext(ctx, "PRAGMA foreign_keys = ON"); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	if err := database.Migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return database, nil
+}
+
+func (d *Database) Close() error {
+	return d.DB.Close()
+}
+
+// codeMigration is a migration that needs more than SQL, such as the
+// configuration. Its version is numbered along with the SQL files.
+type codeMigration struct {
+	name  string
+	apply func(ctx context.Context, d *Database, tx *sql.Tx) error
+}
+
+var codeMigrations = map[int64]codeMigration{
+	6: {name: "backfill_auction_end_times", apply: backfillAuctionEndTimes},
+}
+
+// Migrate applies, in order, every embedded migration of the driver and every
+// code migration that is not yet recorded in schema_migrations.
+func (d *Database) Migrate(ctx context.Context) error {
+	conn, err := d.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if d.driver == Postgres {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", postgresMigrationLock); err != nil {
+			return err
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", postgresMigrationLock)
+	}
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		applied_at BIGINT NOT NULL
+	)`); err != nil {
+		logger.Error("Error trying to create schema_migrations table", err)
+		return err
+	}
+
+	applied := map[int64]bool{}
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+
+	pending, err := d.pendingMigrations(applied)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range pending {
+		if err := d.applyMigration(ctx, conn, migration.version, migration.apply); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to apply migration %s", migration.name), err)
+			return err
+		}
+
+		logger.Info(fmt.Sprintf("Applied migration %s", migration.name))
+	}
+
+	return nil
+}
+
+type pendingMigration struct {
+	version int64
+	name    string
+	apply   func(ctx context.Context, d *Database, tx *sql.Tx) error
+}
+
+// pendingMigrations returns the SQL files of the driver and the code
+// migrations not in applied, sorted by version.
+func (d *Database) pendingMigrations(applied map[int64]bool) ([]pendingMigration, error) {
+	directory := path.Join("migrations", d.driver)
+	entries, err := fs.ReadDir(migrations, directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []pendingMigration
+	for _, entry := range entries {
+		version, err := strconv.ParseInt(strings.SplitN(entry.Name(), "_", 2)[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		if _, ok := codeMigrations[version]; ok {
+			return nil, fmt.Errorf("migration file %s reuses the version of a code migration", entry.Name())
+		}
+		if applied[version] {
+			continue
+		}
+
+		statements, err := migrations.ReadFile(path.Join(directory, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		pending = append(pending, pendingMigration{
+			version: version,
+			name:    entry.Name(),
+			apply: func(ctx context.Context, d *Database, tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, string(statements))
+				return err
+			},
+		})
+	}
+
+	for version, migration := range codeMigrations {
+		if !applied[version] {
+			pending = append(pending, pendingMigration{
+				version: version,
+				name:    fmt.Sprintf("%04d_%s", version, migration.name),
+				apply:   migration.apply,
+			})
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool { return pending[i].version < pending[j].version })
+	return pending, nil
+}
+
+func (d *Database) applyMigration(
+	ctx context.Context,
+	conn *sql.Conn,
+	version int64,
+	apply func(ctx context.Context, d *Database, tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := apply(ctx, d, tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, d.rebind(
+		"INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)"),
+		version, time.Now().Unix()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// withTx runs fn inside a transaction, committing only when it succeeds.
+func (d *Database) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// rebind rewrites the ? placeholders used by the repositories into the
+// $n form expected by PostgreSQL.
+func (d *Database) rebind(query string) string {
+	if d.driver != Postgres {
+		return query
+	}
+
+	var builder strings.Builder
+	position := 0
+	for _, char := range query {
+		if char == '?' {
+			position++
+			builder.WriteString("$" + strconv.Itoa(position))
+			continue
+		}
+		builder.WriteRune(char)
+	}
+
+	return builder.String()
+}
+
+// collate forces byte-wise ordering of text columns so results are sorted
+// the same way as in the other backends regardless of the database locale.
+func (d *Database) collate(column string) string {
+	if d.driver == Postgres {
+		return column + ` COLLATE "C"`
+	}
+	return column
+}
+
+func (d *Database) forUpdate() string {
+	if d.driver == Postgres {
+		return " FOR UPDATE"
+	}
+	return ""
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE ||
+			sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+
+	return false
+}
+
+// nullableString stores empty values as NULL, so optional columns such
+// as users.email stay out of their unique index.
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func placeholders(count int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", count), ", ")
+}
+
+func likePattern(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(strings.ToLower(value))
+}
@@ -0,0 +1,329 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/job_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+	"time"
+)
+
+const jobColumns = "id, type, payload, status, run_at, cron, idempotency_key, attempts, max_attempts, " +
+	"last_error, claimed_by, token, lease_expires_at, created_at, updated_at"
+
+var (
+	jobStatusToSQL = map[job_entity.JobStatus]string{
+		job_entity.Pending:   "pending",
+		job_entity.Running:   "running",
+		job_entity.Succeeded: "succeeded",
+		job_entity.Failed:    "failed",
+		job_entity.Cancelled: "cancelled",
+	}
+	jobStatusFromSQL = map[string]job_entity.JobStatus{
+		"pending":   job_entity.Pending,
+		"running":   job_entity.Running,
+		"succeeded": job_entity.Succeeded,
+		"failed":    job_entity.Failed,
+		"cancelled": job_entity.Cancelled,
+	}
+)
+
+type JobRepository struct {
+	Database *Database
+}
+
+func NewJobRepository(database *Database) *JobRepository {
+	return &JobRepository{Database: database}
+}
+
+func (jr *JobRepository) CreateJob(
+	ctx context.Context, job *job_entity.Job) (*job_entity.Job, bool, *internal_error.InternalError) {
+	_, err := jr.Database.DB.ExecContext(ctx, jr.Database.rebind(
+		"INSERT INTO jobs ("+jobColumns+") VALUES ("+placeholders(15)+")"),
+		job.Id,
+		job.Type,
+		job.Payload,
+		jobStatusToSQL[job.Status],
+		job.RunAt.UnixMilli(),
+		job.Cron,
+		nullableString(job.IdempotencyKey),
+		job.Attempts,
+		job.MaxAttempts,
+		job.LastError,
+		job.ClaimedBy,
+		job.Token,
+		toMilli(job.LeaseExpiresAt),
+		job.CreatedAt.UnixMilli(),
+		job.UpdatedAt.UnixMilli())
+	if err == nil {
+		stored, findErr := jr.FindJobById(ctx, job.Id)
+		if findErr != nil {
+			return nil, false, findErr
+		}
+		return stored, true, nil
+	} else if !isUniqueViolation(err) || job.IdempotencyKey == "" {
+		logger.Error(fmt.Sprintf("Error trying to insert job %s", job.Type), err)
+		return nil, false, internal_error.NewInternalServerError("Error trying to insert job")
+	}
+
+	existing, scanErr := scanJob(jr.Database.DB.QueryRowContext(ctx, jr.Database.rebind(
+		"SELECT "+jobColumns+" FROM jobs WHERE idempotency_key = ?"), job.IdempotencyKey))
+	if scanErr != nil {
+		logger.Error(fmt.Sprintf("Error trying to find job with idempotency key %s", job.IdempotencyKey), scanErr)
+		return nil, false, internal_error.NewInternalServerError("Error trying to insert job")
+	}
+
+	return existing, false, nil
+}
+
+// ClaimJob locks the next due job, skipping the ones other workers are
+// claiming on PostgreSQL, and marks it as running in the same transaction.
+func (jr *JobRepository) ClaimJob(
+	ctx context.Context,
+	workerId string,
+	now time.Time,
+	leaseTTL time.Duration) (*job_entity.Job, *internal_error.InternalError) {
+	var claimed *job_entity.Job
+	err := jr.Database.withTx(ctx, func(tx *sql.Tx) error {
+		lock := jr.Database.forUpdate()
+		if lock != "" {
+			lock += " SKIP LOCKED"
+		}
+
+		job, err := scanJob(tx.QueryRowContext(ctx, jr.Database.rebind(
+			"SELECT "+jobColumns+" FROM jobs "+
+				"WHERE (status = ? AND run_at <= ?) OR (status = ? AND lease_expires_at <= ?) "+
+				"ORDER BY run_at, id LIMIT 1"+lock),
+			jobStatusToSQL[job_entity.Pending], now.UnixMilli(),
+			jobStatusToSQL[job_entity.Running], now.UnixMilli()))
+		if err != nil {
+			return err
+		}
+
+		job.Status = job_entity.Running
+		job.ClaimedBy = workerId
+		job.Token++
+		job.Attempts++
+		job.LeaseExpiresAt = time.UnixMilli(now.Add(leaseTTL).UnixMilli())
+		job.UpdatedAt = time.UnixMilli(now.UnixMilli())
+
+		if _, err := tx.ExecContext(ctx, jr.Database.rebind(
+			"UPDATE jobs SET status = ?, claimed_by = ?, token = ?, attempts = ?, "+
+				"lease_expires_at = ?, updated_at = ? WHERE id = ?"),
+			jobStatusToSQL[job.Status], job.ClaimedBy, job.Token, job.Attempts,
+			job.LeaseExpiresAt.UnixMilli(), job.UpdatedAt.UnixMilli(), job.Id); err != nil {
+			return err
+		}
+
+		claimed = job
+		return nil
+	})
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		logger.Error("Error trying to claim job", err)
+		return nil, internal_error.NewInternalServerError("Error trying to claim job")
+	}
+
+	return claimed, nil
+}
+
+func (jr *JobRepository) FinishJob(
+	ctx context.Context, job *job_entity.Job) (bool, *internal_error.InternalError) {
+	result, err := jr.Database.DB.ExecContext(ctx, jr.Database.rebind(
+		"UPDATE jobs SET status = ?, run_at = ?, attempts = ?, last_error = ?, "+
+			"claimed_by = '', lease_expires_at = 0, updated_at = ? "+
+			"WHERE id = ? AND status = ? AND claimed_by = ? AND token = ?"),
+		jobStatusToSQL[job.Status], job.RunAt.UnixMilli(), job.Attempts, job.LastError,
+		job.UpdatedAt.UnixMilli(),
+		job.Id, jobStatusToSQL[job_entity.Running], job.ClaimedBy, job.Token)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to finish job %s", job.Id), err)
+		return false, internal_error.NewInternalServerError("Error trying to finish job")
+	}
+
+	finished, _ := result.RowsAffected()
+	return finished > 0, nil
+}
+
+func (jr *JobRepository) FindJobById(
+	ctx context.Context, id string) (*job_entity.Job, *internal_error.InternalError) {
+	job, err := scanJob(jr.Database.DB.QueryRowContext(ctx, jr.Database.rebind(
+		"SELECT "+jobColumns+" FROM jobs WHERE id = ?"), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError("Job not found")
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find job %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find job")
+	}
+
+	return job, nil
+}
+
+func (jr *JobRepository) FindJobs(
+	ctx context.Context, jobFilter job_entity.JobFilter) ([]job_entity.Job, int64, *internal_error.InternalError) {
+	var conditions []string
+	var args []interface{}
+
+	if jobFilter.Status != nil {
+		conditions = append(conditions, "status = ?")
+		args = append(args, jobStatusToSQL[*jobFilter.Status])
+	}
+
+	if jobFilter.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, jobFilter.Type)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := jr.Database.DB.QueryRowContext(ctx, jr.Database.rebind(
+		"SELECT COUNT(*) FROM jobs"+where), args...).Scan(&total); err != nil {
+		logger.Error("Error trying to count jobs", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find jobs")
+	}
+
+	rows, err := jr.Database.DB.QueryContext(ctx, jr.Database.rebind(
+		"SELECT "+jobColumns+" FROM jobs"+where+" ORDER BY run_at, id LIMIT ? OFFSET ?"),
+		append(args, jobFilter.Limit, (jobFilter.Page-1)*jobFilter.Limit)...)
+	if err != nil {
+		logger.Error("Error trying to find jobs", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find jobs")
+	}
+	defer rows.Close()
+
+	jobs := []job_entity.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			logger.Error("Error trying to decode jobs", err)
+			return nil, 0, internal_error.NewInternalServerError("Error trying to find jobs")
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Error trying to find jobs", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find jobs")
+	}
+
+	return jobs, total, nil
+}
+
+func (jr *JobRepository) RetryJob(
+	ctx context.Context, id string, runAt time.Time) (*job_entity.Job, *internal_error.InternalError) {
+	return jr.transition(ctx, id,
+		[]job_entity.JobStatus{job_entity.Failed, job_entity.Cancelled},
+		"status = ?, run_at = ?, attempts = 0",
+		[]interface{}{jobStatusToSQL[job_entity.Pending], runAt.UnixMilli()},
+		"Only failed or cancelled jobs can be retried")
+}
+
+func (jr *JobRepository) CancelJob(
+	ctx context.Context, id string) (*job_entity.Job, *internal_error.InternalError) {
+	return jr.transition(ctx, id,
+		[]job_entity.JobStatus{job_entity.Pending, job_entity.Failed},
+		"status = ?",
+		[]interface{}{jobStatusToSQL[job_entity.Cancelled]},
+		"Only pending or failed jobs can be cancelled")
+}
+
+// transition applies set to a job currently in one of from, telling a
+// missing job apart from one in another status.
+func (jr *JobRepository) transition(
+	ctx context.Context,
+	id string,
+	from []job_entity.JobStatus,
+	set string,
+	setArgs []interface{},
+	invalidMessage string) (*job_entity.Job, *internal_error.InternalError) {
+	args := append(setArgs, id)
+	for _, status := range from {
+		args = append(args, jobStatusToSQL[status])
+	}
+
+	var job *job_entity.Job
+	err := jr.Database.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, jr.Database.rebind(
+			"UPDATE jobs SET "+set+" WHERE id = ? AND status IN ("+placeholders(len(from))+")"), args...)
+		if err != nil {
+			return err
+		}
+
+		if updated, _ := result.RowsAffected(); updated == 0 {
+			if _, err := scanJob(tx.QueryRowContext(ctx, jr.Database.rebind(
+				"SELECT "+jobColumns+" FROM jobs WHERE id = ?"), id)); err != nil {
+				return err
+			}
+			return errJobStatus
+		}
+
+		job, err = scanJob(tx.QueryRowContext(ctx, jr.Database.rebind(
+			"SELECT "+jobColumns+" FROM jobs WHERE id = ?"), id))
+		return err
+	})
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError("Job not found")
+	} else if errors.Is(err, errJobStatus) {
+		return nil, internal_error.NewBadRequestError(invalidMessage)
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to update job %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to update job")
+	}
+
+	return job, nil
+}
+
+func scanJob(row scanner) (*job_entity.Job, error) {
+	var job job_entity.Job
+	var status string
+	var idempotencyKey sql.NullString
+	var runAt, leaseExpiresAt, createdAt, updatedAt int64
+
+	if err := row.Scan(
+		&job.Id,
+		&job.Type,
+		&job.Payload,
+		&status,
+		&runAt,
+		&job.Cron,
+		&idempotencyKey,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.LastError,
+		&job.ClaimedBy,
+		&job.Token,
+		&leaseExpiresAt,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	job.Status = jobStatusFromSQL[status]
+	job.IdempotencyKey = idempotencyKey.String
+	job.RunAt = time.UnixMilli(runAt)
+	if leaseExpiresAt != 0 {
+		job.LeaseExpiresAt = time.UnixMilli(leaseExpiresAt)
+	}
+	job.CreatedAt = time.UnixMilli(createdAt)
+	job.UpdatedAt = time.UnixMilli(updatedAt)
+
+	return &job, nil
+}
+
+func toMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
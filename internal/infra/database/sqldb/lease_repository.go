@@ -0,0 +1,82 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type LeaseRepository struct {
+	Database *Database
+	clock    clock.Clock
+}
+
+func NewLeaseRepository(database *Database, clock clock.Clock) *LeaseRepository {
+	return &LeaseRepository{Database: database, clock: clock}
+}
+
+// AcquireLease renews or takes over the lease with a single conditional
+// upsert, so concurrent instances cannot both hold it.
+func (lr *LeaseRepository) AcquireLease(
+	ctx context.Context,
+	name, holderId string,
+	ttl time.Duration) (*lease_entity.Lease, bool, *internal_error.InternalError) {
+	now := lr.clock.Now()
+
+	_, err := lr.Database.DB.ExecContext(ctx, lr.Database.rebind(
+		"INSERT INTO leases (name, holder_id, token, expires_at) VALUES (?, ?, 1, ?) "+
+			"ON CONFLICT (name) DO UPDATE SET "+
+			"token = CASE WHEN leases.holder_id = excluded.holder_id AND leases.expires_at > ? "+
+			"THEN leases.token ELSE leases.token + 1 END, "+
+			"holder_id = excluded.holder_id, expires_at = excluded.expires_at "+
+			"WHERE leases.expires_at <= ? OR leases.holder_id = excluded.holder_id"),
+		name, holderId, now.Add(ttl).UnixMilli(), now.UnixMilli(), now.UnixMilli())
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to acquire lease %s", name), err)
+		return nil, false, internal_error.NewInternalServerError("Error trying to acquire lease")
+	}
+
+	lease, findErr := lr.FindLease(ctx, name)
+	if findErr != nil {
+		return nil, false, findErr
+	}
+
+	return lease, lease.HolderId == holderId && lease.ExpiresAt.After(now), nil
+}
+
+func (lr *LeaseRepository) ReleaseLease(
+	ctx context.Context, name, holderId string, token int64) *internal_error.InternalError {
+	_, err := lr.Database.DB.ExecContext(ctx, lr.Database.rebind(
+		"UPDATE leases SET expires_at = 0 WHERE name = ? AND holder_id = ? AND token = ?"),
+		name, holderId, token)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to release lease %s", name), err)
+		return internal_error.NewInternalServerError("Error trying to release lease")
+	}
+
+	return nil
+}
+
+func (lr *LeaseRepository) FindLease(
+	ctx context.Context, name string) (*lease_entity.Lease, *internal_error.InternalError) {
+	var lease lease_entity.Lease
+	var expiresAt int64
+	err := lr.Database.DB.QueryRowContext(ctx, lr.Database.rebind(
+		"SELECT name, holder_id, token, expires_at FROM leases WHERE name = ?"), name).
+		Scan(&lease.Name, &lease.HolderId, &lease.Token, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Lease %s not found", name))
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find lease %s", name), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find lease")
+	}
+
+	lease.ExpiresAt = time.UnixMilli(expiresAt)
+	return &lease, nil
+}
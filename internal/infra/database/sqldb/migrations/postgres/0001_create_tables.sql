@@ -0,0 +1,64 @@
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT,
+    status TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX users_email_unique ON users (email);
+
+CREATE TABLE categories (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    parent_id TEXT,
+    attributes JSONB NOT NULL DEFAULT '[]',
+    timestamp BIGINT NOT NULL
+);
+
+CREATE UNIQUE INDEX categories_slug_unique ON categories (slug);
+CREATE INDEX categories_parent_id ON categories (parent_id);
+
+CREATE TABLE auctions (
+    id TEXT PRIMARY KEY,
+    product_name TEXT NOT NULL,
+    category_id TEXT NOT NULL,
+    category TEXT NOT NULL,
+    description TEXT NOT NULL,
+    condition INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    timestamp BIGINT NOT NULL,
+    highest_bid DOUBLE PRECISION NOT NULL DEFAULT 0,
+    bid_count BIGINT NOT NULL DEFAULT 0,
+    attributes JSONB NOT NULL DEFAULT '{}',
+    -- Space-delimited Slugify tokens used by the weighted text search.
+    product_name_tokens TEXT NOT NULL DEFAULT '',
+    category_tokens TEXT NOT NULL DEFAULT '',
+    description_tokens TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX auctions_status_timestamp ON auctions (status, timestamp);
+CREATE INDEX auctions_category_id ON auctions (category_id);
+
+CREATE TABLE auction_images (
+    id TEXT NOT NULL,
+    auction_id TEXT NOT NULL REFERENCES auctions (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    storage_key TEXT NOT NULL,
+    thumbnail_key TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    timestamp BIGINT NOT NULL,
+    PRIMARY KEY (auction_id, id)
+);
+
+CREATE TABLE bids (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    auction_id TEXT NOT NULL,
+    amount DOUBLE PRECISION NOT NULL,
+    timestamp BIGINT NOT NULL
+);
+
+CREATE INDEX bids_auction_id_amount ON bids (auction_id, amount DESC, timestamp);
+CREATE INDEX bids_user_id_timestamp ON bids (user_id, timestamp DESC);
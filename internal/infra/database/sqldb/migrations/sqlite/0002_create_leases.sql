@@ -0,0 +1,6 @@
+CREATE TABLE leases (
+    name TEXT PRIMARY KEY,
+    holder_id TEXT NOT NULL,
+    token INTEGER NOT NULL,
+    expires_at INTEGER NOT NULL
+);
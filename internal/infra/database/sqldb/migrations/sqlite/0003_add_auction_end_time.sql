@@ -0,0 +1,2 @@
+ALTER TABLE auctions ADD COLUMN end_time INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX auctions_status_end_time ON auctions (status, end_time);
@@ -0,0 +1,20 @@
+CREATE TABLE jobs (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    payload BLOB,
+    status TEXT NOT NULL,
+    run_at INTEGER NOT NULL,
+    cron TEXT NOT NULL DEFAULT '',
+    idempotency_key TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    claimed_by TEXT NOT NULL DEFAULT '',
+    token INTEGER NOT NULL DEFAULT 0,
+    lease_expires_at INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE UNIQUE INDEX jobs_idempotency_key_unique ON jobs (idempotency_key);
+CREATE INDEX jobs_status_run_at ON jobs (status, run_at);
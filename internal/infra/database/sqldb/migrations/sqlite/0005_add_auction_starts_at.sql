@@ -0,0 +1,3 @@
+ALTER TABLE auctions ADD COLUMN starts_at INTEGER NOT NULL DEFAULT 0;
+UPDATE auctions SET starts_at = timestamp * 1000;
+CREATE INDEX auctions_status_starts_at ON auctions (status, starts_at);
@@ -0,0 +1,211 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+)
+
+const userColumns = "id, name, email, status"
+
+var (
+	userStatusToSQL = map[user_entity.UserStatus]string{
+		user_entity.Active:   "active",
+		user_entity.Inactive: "inactive",
+	}
+	userStatusFromSQL = map[string]user_entity.UserStatus{
+		"active":   user_entity.Active,
+		"inactive": user_entity.Inactive,
+	}
+)
+
+type UserRepository struct {
+	Database *Database
+}
+
+func NewUserRepository(database *Database) *UserRepository {
+	return &UserRepository{Database: database}
+}
+
+func (ur *UserRepository) FindUserById(
+	ctx context.Context, userId string) (*user_entity.User, *internal_error.InternalError) {
+	user, err := scanUser(ur.Database.DB.QueryRowContext(ctx, ur.Database.rebind(
+		"SELECT "+userColumns+" FROM users WHERE id = ?"), userId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Error(fmt.Sprintf("User not found with this id = %s", userId), err)
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("User not found with this id = %s", userId))
+		}
+
+		logger.Error("Error trying to find user by userId", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find user by userId")
+	}
+
+	return user, nil
+}
+
+func (ur *UserRepository) CreateUser(
+	ctx context.Context, user *user_entity.User) *internal_error.InternalError {
+	_, err := ur.Database.DB.ExecContext(ctx, ur.Database.rebind(
+		"INSERT INTO users ("+userColumns+") VALUES (?, ?, ?, ?)"),
+		user.Id, user.Name, nullableString(user.Email), userStatusToSQL[user.Status])
+	if err != nil {
+		if isUniqueViolation(err) {
+			logger.Info(fmt.Sprintf("User already exists with id: %s or email: %s", user.Id, user.Email))
+			return internal_error.NewConflictError("User already exists with this email")
+		}
+
+		logger.Error(fmt.Sprintf("Error creating user with id: %s", user.Id), err)
+		return internal_error.NewInternalServerError(
+			fmt.Sprintf("Error creating user with id: %s", user.Id))
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) UpdateUserProfile(
+	ctx context.Context,
+	userId string,
+	update user_entity.UserProfileUpdate) *internal_error.InternalError {
+	var sets []string
+	var args []interface{}
+	if update.Name != nil {
+		sets = append(sets, "name = ?")
+		args = append(args, *update.Name)
+	}
+	if update.Email != nil {
+		sets = append(sets, "email = ?")
+		args = append(args, *update.Email)
+	}
+	args = append(args, userId, userStatusToSQL[user_entity.Inactive])
+
+	// The status condition keeps a profile update from racing a
+	// deactivation; only the changed columns are written, never the status.
+	var matched int64
+	if len(sets) == 0 {
+		if err := ur.Database.DB.QueryRowContext(ctx, ur.Database.rebind(
+			"SELECT COUNT(*) FROM users WHERE id = ? AND status <> ?"), args...).Scan(&matched); err != nil {
+			logger.Error(fmt.Sprintf("Error updating user with id: %s", userId), err)
+			return internal_error.NewInternalServerError(
+				fmt.Sprintf("Error updating user with id: %s", userId))
+		}
+	} else {
+		result, err := ur.Database.DB.ExecContext(ctx, ur.Database.rebind(
+			"UPDATE users SET "+strings.Join(sets, ", ")+" WHERE id = ? AND status <> ?"), args...)
+		if err != nil {
+			if isUniqueViolation(err) {
+				logger.Info(fmt.Sprintf("Email %s already in use, user %s not updated", *update.Email, userId))
+				return internal_error.NewConflictError("User already exists with this email")
+			}
+
+			logger.Error(fmt.Sprintf("Error updating user with id: %s", userId), err)
+			return internal_error.NewInternalServerError(
+				fmt.Sprintf("Error updating user with id: %s", userId))
+		}
+		matched, _ = result.RowsAffected()
+	}
+
+	if matched == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Active user not found with this id = %s", userId))
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) DeactivateUser(
+	ctx context.Context, userId string) *internal_error.InternalError {
+	result, err := ur.Database.DB.ExecContext(ctx, ur.Database.rebind(
+		"UPDATE users SET status = ? WHERE id = ?"), userStatusToSQL[user_entity.Inactive], userId)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error deactivating user with id: %s", userId), err)
+		return internal_error.NewInternalServerError(
+			fmt.Sprintf("Error deactivating user with id: %s", userId))
+	}
+
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("User not found with this id = %s", userId))
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) FindUsers(
+	ctx context.Context, userFilter user_entity.UserFilter) ([]user_entity.User, int64, *internal_error.InternalError) {
+	var conditions []string
+	var args []interface{}
+
+	if userFilter.Name != "" {
+		conditions = append(conditions, `LOWER(name) LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likePattern(userFilter.Name)+"%")
+	}
+
+	if userFilter.Email != "" {
+		conditions = append(conditions, "email = ?")
+		args = append(args, user_entity.NormalizeEmail(userFilter.Email))
+	}
+
+	if userFilter.Status != nil {
+		conditions = append(conditions, "status = ?")
+		args = append(args, userStatusToSQL[*userFilter.Status])
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := ur.Database.DB.QueryRowContext(ctx, ur.Database.rebind(
+		"SELECT COUNT(*) FROM users"+where), args...).Scan(&total); err != nil {
+		logger.Error("Error trying to count users", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find users")
+	}
+
+	rows, err := ur.Database.DB.QueryContext(ctx, ur.Database.rebind(
+		"SELECT "+userColumns+" FROM users"+where+
+			" ORDER BY "+ur.Database.collate("name")+", id LIMIT ? OFFSET ?"),
+		append(args, userFilter.Limit, (userFilter.Page-1)*userFilter.Limit)...)
+	if err != nil {
+		logger.Error("Error trying to find users", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find users")
+	}
+	defer rows.Close()
+
+	users := []user_entity.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			logger.Error("Error trying to decode users", err)
+			return nil, 0, internal_error.NewInternalServerError("Error trying to find users")
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Error trying to find users", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find users")
+	}
+
+	return users, total, nil
+}
+
+func scanUser(row scanner) (*user_entity.User, error) {
+	var user user_entity.User
+	var email sql.NullString
+	var status string
+	if err := row.Scan(&user.Id, &user.Name, &email, &status); err != nil {
+		return nil, err
+	}
+
+	user.Email = email.String
+	user.Status = userStatusFromSQL[status]
+
+	return &user, nil
+}
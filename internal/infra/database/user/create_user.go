@@ -0,0 +1,38 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (ur *UserRepository) CreateUser(
+	ctx context.Context, user *user_entity.User) *internal_error.InternalError {
+
+	opts := options.InsertOne()
+
+	_, err := ur.Collection.InsertOne(ctx, UserEntityMongo{
+		Id:     user.Id,
+		Name:   user.Name,
+		Email:  user.Email,
+		Status: userStatusToMongo[user.Status],
+	}, opts)
+
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			logger.Info(fmt.Sprintf("User already exists with id: %s or email: %s", user.Id, user.Email))
+			return internal_error.NewConflictError("User already exists with this email")
+		}
+
+		logger.Error(fmt.Sprintf("Error creating user with id: %s", user.Id), err)
+		return internal_error.NewInternalServerError(
+			fmt.Sprintf("Error creating user with id: %s", user.Id))
+	}
+
+	return nil
+}
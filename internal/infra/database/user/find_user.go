@@ -7,21 +7,36 @@ import (
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/user_entity"
 	"fullcycle-auction_go/internal/internal_error"
+	"regexp"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserEntityMongo struct {
-	Id   string `bson:"_id"`
-	Name string `bson:"name"`
+	Id     string `bson:"_id"`
+	Name   string `bson:"name"`
+	Email  string `bson:"email,omitempty"`
+	Status string `bson:"status"`
 }
 
 type UserRepository struct {
 	Collection *mongo.Collection
 }
 
+var (
+	userStatusToMongo = map[user_entity.UserStatus]string{
+		user_entity.Active:   "active",
+		user_entity.Inactive: "inactive",
+	}
+	userStatusFromMongo = map[string]user_entity.UserStatus{
+		"active":   user_entity.Active,
+		"inactive": user_entity.Inactive,
+	}
+)
+
 func NewUserRepository(database *mongo.Database) *UserRepository {
 	return &UserRepository{
 		Collection: database.Collection("users"),
@@ -46,38 +61,66 @@ func (ur *UserRepository) FindUserById(
 		return nil, internal_error.NewInternalServerError("Error trying to find user by userId")
 	}
 
-	userEntity := &user_entity.User{
-		Id:   userEntityMongo.Id,
-		Name: userEntityMongo.Name,
+	return toUserEntity(userEntityMongo), nil
+}
+
+func (ur *UserRepository) FindUsers(
+	ctx context.Context, userFilter user_entity.UserFilter) ([]user_entity.User, int64, *internal_error.InternalError) {
+	filter := bson.M{}
+
+	if userFilter.Name != "" {
+		filter["name"] = primitive.Regex{Pattern: regexp.QuoteMeta(userFilter.Name), Options: "i"}
 	}
 
-	return userEntity, nil
-}
+	if userFilter.Email != "" {
+		filter["email"] = user_entity.NormalizeEmail(userFilter.Email)
+	}
 
-func (ur *UserRepository) CreateUser(
-	ctx context.Context, user *user_entity.User) *internal_error.InternalError {
+	if userFilter.Status != nil {
+		if *userFilter.Status == user_entity.Active {
+			filter["status"] = bson.M{"$ne": userStatusToMongo[user_entity.Inactive]}
+		} else {
+			filter["status"] = userStatusToMongo[*userFilter.Status]
+		}
+	}
 
-	opts := options.InsertOne()
+	total, err := ur.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error("Error trying to count users", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find users")
+	}
 
-	_, err := ur.Collection.InsertOne(ctx, UserEntityMongo{
-		Id:   user.Id,
-		Name: user.Name,
-	}, opts)
+	opts := options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip((userFilter.Page - 1) * userFilter.Limit).
+		SetLimit(userFilter.Limit)
 
+	cursor, err := ur.Collection.Find(ctx, filter, opts)
 	if err != nil {
-		if writeErr, ok := err.(mongo.WriteException); ok {
-			for _, e := range writeErr.WriteErrors {
-				if e.Code == 11000 {
-					logger.Info(fmt.Sprintf("User already exists with id: %s", user.Id))
-					return internal_error.NewConflictError("User already exists")
-				}
-			}
-		}
+		logger.Error("Error trying to find users", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find users")
+	}
+	defer cursor.Close(ctx)
 
-		logger.Error(fmt.Sprintf("Error creating user with id: %s", user.Id), err)
-		return internal_error.NewInternalServerError(
-			fmt.Sprintf("Error creating user with id: %s", user.Id))
+	var usersMongo []UserEntityMongo
+	if err := cursor.All(ctx, &usersMongo); err != nil {
+		logger.Error("Error trying to decode users", err)
+		return nil, 0, internal_error.NewInternalServerError("Error trying to find users")
 	}
 
-	return nil
+	users := make([]user_entity.User, 0, len(usersMongo))
+	for _, userMongo := range usersMongo {
+		users = append(users, *toUserEntity(userMongo))
+	}
+
+	return users, total, nil
+}
+
+func toUserEntity(userEntityMongo UserEntityMongo) *user_entity.User {
+	return &user_entity.User{
+		Id:     userEntityMongo.Id,
+		Name:   userEntityMongo.Name,
+		Email:  userEntityMongo.Email,
+		Status: userStatusFromMongo[userEntityMongo.Status],
+	}
 }
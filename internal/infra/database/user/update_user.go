@@ -0,0 +1,82 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func (ur *UserRepository) UpdateUserProfile(
+	ctx context.Context,
+	userId string,
+	update user_entity.UserProfileUpdate) *internal_error.InternalError {
+	set := bson.M{}
+	if update.Name != nil {
+		set["name"] = *update.Name
+	}
+	if update.Email != nil {
+		set["email"] = *update.Email
+	}
+
+	// The status filter keeps a profile update from racing a deactivation;
+	// only the changed fields are written, never the status.
+	filter := bson.M{
+		"_id":    userId,
+		"status": bson.M{"$ne": userStatusToMongo[user_entity.Inactive]},
+	}
+
+	var matched int64
+	if len(set) == 0 {
+		count, err := ur.Collection.CountDocuments(ctx, filter)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error updating user with id: %s", userId), err)
+			return internal_error.NewInternalServerError(
+				fmt.Sprintf("Error updating user with id: %s", userId))
+		}
+		matched = count
+	} else {
+		result, err := ur.Collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				logger.Info(fmt.Sprintf("Email %s already in use, user %s not updated", set["email"], userId))
+				return internal_error.NewConflictError("User already exists with this email")
+			}
+
+			logger.Error(fmt.Sprintf("Error updating user with id: %s", userId), err)
+			return internal_error.NewInternalServerError(
+				fmt.Sprintf("Error updating user with id: %s", userId))
+		}
+		matched = result.MatchedCount
+	}
+
+	if matched == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Active user not found with this id = %s", userId))
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) DeactivateUser(
+	ctx context.Context, userId string) *internal_error.InternalError {
+	result, err := ur.Collection.UpdateByID(ctx, userId, bson.M{
+		"$set": bson.M{"status": userStatusToMongo[user_entity.Inactive]},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error deactivating user with id: %s", userId), err)
+		return internal_error.NewInternalServerError(
+			fmt.Sprintf("Error deactivating user with id: %s", userId))
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("User not found with this id = %s", userId))
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+// Package events dispatches in-process events synchronously to subscribers.
+package events
+
+import "sync"
+
+type Bus[T any] struct {
+	mutex    sync.RWMutex
+	handlers []func(T)
+}
+
+func NewBus[T any]() *Bus[T] {
+	return &Bus[T]{}
+}
+
+func (b *Bus[T]) Subscribe(handler func(T)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish calls every subscriber before returning, in subscription order.
+func (b *Bus[T]) Publish(event T) {
+	b.mutex.RLock()
+	handlers := b.handlers
+	b.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,209 @@
+// Package export writes rows to CSV, NDJSON or Parquet files. The columns are
+// the fields of the row struct, in order and named by their json tag, so the
+// three formats of a dataset share one schema.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	CSV     Format = "csv"
+	NDJSON  Format = "ndjson"
+	Parquet Format = "parquet"
+)
+
+const timeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case CSV, NDJSON, Parquet:
+		return format, nil
+	}
+
+	return "", fmt.Errorf("unknown export format %q, expected csv, ndjson or parquet", name)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case NDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// Writer writes rows of type T. Close must be called once every row was
+// written; Parquet files are unreadable without it.
+type Writer[T any] interface {
+	Write(row T) error
+	Close() error
+}
+
+// NewWriter returns a writer of rows of the struct type T to out. Fields may
+// be strings, float64, int64 or time.Time, which every format stores in UTC
+// with millisecond precision.
+func NewWriter[T any](format Format, out io.Writer) (Writer[T], error) {
+	columns, err := columnsOf[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case CSV:
+		return newCSVWriter[T](out, columns)
+	case NDJSON:
+		return &ndjsonWriter[T]{buffered: bufio.NewWriter(out), columns: columns}, nil
+	case Parquet:
+		return newParquetWriter[T](out, columns), nil
+	}
+
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+type columnKind int
+
+const (
+	stringColumn columnKind = iota
+	float64Column
+	int64Column
+	timeColumn
+)
+
+type column struct {
+	name string
+	kind columnKind
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func columnsOf[T any]() ([]column, error) {
+	rowType := reflect.TypeOf(*new(T))
+	if rowType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("export rows must be structs, got %s", rowType)
+	}
+
+	columns := make([]column, 0, rowType.NumField())
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		var kind columnKind
+		switch {
+		case field.Type == timeType:
+			kind = timeColumn
+		case field.Type.Kind() == reflect.String:
+			kind = stringColumn
+		case field.Type.Kind() == reflect.Float64:
+			kind = float64Column
+		case field.Type.Kind() == reflect.Int64:
+			kind = int64Column
+		default:
+			return nil, fmt.Errorf("unsupported type %s of export column %s", field.Type, name)
+		}
+
+		columns = append(columns, column{name: name, kind: kind})
+	}
+
+	return columns, nil
+}
+
+type csvWriter[T any] struct {
+	writer  *csv.Writer
+	columns []column
+	record  []string
+}
+
+func newCSVWriter[T any](out io.Writer, columns []column) (*csvWriter[T], error) {
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, column.name)
+	}
+
+	writer := csv.NewWriter(out)
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &csvWriter[T]{writer: writer, columns: columns, record: make([]string, len(columns))}, nil
+}
+
+func (w *csvWriter[T]) Write(row T) error {
+	value := reflect.ValueOf(row)
+	for i, column := range w.columns {
+		field := value.Field(i)
+		switch column.kind {
+		case stringColumn:
+			w.record[i] = field.String()
+		case float64Column:
+			w.record[i] = strconv.FormatFloat(field.Float(), 'f', -1, 64)
+		case int64Column:
+			w.record[i] = strconv.FormatInt(field.Int(), 10)
+		case timeColumn:
+			w.record[i] = field.Interface().(time.Time).UTC().Format(timeLayout)
+		}
+	}
+
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter[T]) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// ndjsonWriter writes each row as a JSON object with the keys in column
+// order. Times are formatted as in CSV rather than by time.Time's encoding,
+// which keeps their zone and every digit of the nanoseconds.
+type ndjsonWriter[T any] struct {
+	buffered *bufio.Writer
+	columns  []column
+	line     []byte
+}
+
+func (w *ndjsonWriter[T]) Write(row T) error {
+	value := reflect.ValueOf(row)
+	w.line = append(w.line[:0], '{')
+	for i, column := range w.columns {
+		if i > 0 {
+			w.line = append(w.line, ',')
+		}
+
+		field := value.Field(i).Interface()
+		if column.kind == timeColumn {
+			field = field.(time.Time).UTC().Format(timeLayout)
+		}
+
+		name, err := json.Marshal(column.name)
+		if err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(field)
+		if err != nil {
+			return err
+		}
+		w.line = append(append(append(w.line, name...), ':'), encoded...)
+	}
+
+	_, err := w.buffered.Write(append(w.line, '}', '\n'))
+	return err
+}
+
+func (w *ndjsonWriter[T]) Close() error {
+	return w.buffered.Flush()
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+type testRow struct {
+	Id     string    `json:"id"`
+	Amount float64   `json:"amount"`
+	Count  int64     `json:"count"`
+	At     time.Time `json:"at"`
+}
+
+func writeRows(t *testing.T, format Format, rows ...testRow) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	writer, err := NewWriter[testRow](format, &out)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	return out.Bytes()
+}
+
+func TestTextFormats(t *testing.T) {
+	at := time.Date(2026, 10, 19, 12, 30, 0, 250*int(time.Millisecond)+999, time.FixedZone("BRT", -3*3600))
+	rows := []testRow{
+		{Id: "a", Amount: 10.5, Count: 2, At: at},
+		{Id: "b, \"quoted\"", Amount: 3, Count: 0, At: at.UTC()},
+	}
+
+	csv := string(writeRows(t, CSV, rows...))
+	wantCSV := "id,amount,count,at\n" +
+		"a,10.5,2,2026-10-19T15:30:00.250Z\n" +
+		"\"b, \"\"quoted\"\"\",3,0,2026-10-19T15:30:00.250Z\n"
+	if csv != wantCSV {
+		t.Errorf("unexpected CSV:\n%s", csv)
+	}
+
+	if header := string(writeRows(t, CSV)); header != "id,amount,count,at\n" {
+		t.Errorf("expected an empty export to keep its header, got %q", header)
+	}
+
+	ndjson := string(writeRows(t, NDJSON, rows...))
+	wantNDJSON := `{"id":"a","amount":10.5,"count":2,"at":"2026-10-19T15:30:00.250Z"}` + "\n" +
+		`{"id":"b, \"quoted\"","amount":3,"count":0,"at":"2026-10-19T15:30:00.250Z"}` + "\n"
+	if ndjson != wantNDJSON {
+		t.Errorf("unexpected NDJSON:\n%s", ndjson)
+	}
+}
+
+func TestParquetLayout(t *testing.T) {
+	for _, count := range []int{0, 1, parquetRowGroupSize + 1} {
+		rows := make([]testRow, count)
+		for i := range rows {
+			rows[i] = testRow{Id: "row", Amount: float64(i), Count: int64(i), At: time.UnixMilli(int64(i))}
+		}
+
+		file := writeRows(t, Parquet, rows...)
+		if !bytes.HasPrefix(file, parquetMagic) || !bytes.HasSuffix(file, parquetMagic) {
+			t.Fatalf("%d rows: expected the file to start and end with %q", count, parquetMagic)
+		}
+
+		footerLength := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+		if footerLength <= 0 || footerLength > len(file)-12 {
+			t.Errorf("%d rows: invalid footer length %d in a %d bytes file", count, footerLength, len(file))
+		}
+	}
+}
+
+func TestUnsupportedColumn(t *testing.T) {
+	var out bytes.Buffer
+	if _, err := NewWriter[struct{ Ids []string }](CSV, &out); err == nil {
+		t.Errorf("expected rows with a slice column to be refused")
+	}
+}
@@ -0,0 +1,344 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+// The Parquet writer covers what exports need: flat schemas of required
+// columns, PLAIN encoded and snappy compressed, one page per column chunk.
+// The metadata is Thrift with the compact protocol, as the format requires.
+
+// parquetRowGroupSize bounds the rows buffered in memory before they are
+// written out as a row group.
+const parquetRowGroupSize = 10000
+
+var parquetMagic = []byte("PAR1")
+
+// Values of the enums of the Parquet format.
+const (
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetRequired = 0
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMillis = 9
+
+	parquetPlainEncoding = 0
+	parquetRLEEncoding   = 3
+
+	parquetSnappyCodec = 1
+
+	parquetDataPage = 0
+)
+
+type parquetWriter[T any] struct {
+	out       *countingWriter
+	columns   []column
+	values    [][]byte
+	rows      int64
+	totalRows int64
+	rowGroups []thriftStruct
+	err       error
+}
+
+func newParquetWriter[T any](out io.Writer, columns []column) *parquetWriter[T] {
+	return &parquetWriter[T]{
+		out:     &countingWriter{writer: out},
+		columns: columns,
+		values:  make([][]byte, len(columns)),
+	}
+}
+
+func (w *parquetWriter[T]) Write(row T) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	value := reflect.ValueOf(row)
+	for i, column := range w.columns {
+		field := value.Field(i)
+		switch column.kind {
+		case stringColumn:
+			w.values[i] = binary.LittleEndian.AppendUint32(w.values[i], uint32(field.Len()))
+			w.values[i] = append(w.values[i], field.String()...)
+		case float64Column:
+			w.values[i] = binary.LittleEndian.AppendUint64(w.values[i], math.Float64bits(field.Float()))
+		case int64Column:
+			w.values[i] = binary.LittleEndian.AppendUint64(w.values[i], uint64(field.Int()))
+		case timeColumn:
+			w.values[i] = binary.LittleEndian.AppendUint64(w.values[i],
+				uint64(field.Interface().(time.Time).UnixMilli()))
+		}
+	}
+
+	w.rows++
+	if w.rows == parquetRowGroupSize {
+		w.err = w.flushRowGroup()
+	}
+	return w.err
+}
+
+func (w *parquetWriter[T]) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if w.out.offset == 0 {
+		if _, err := w.out.Write(parquetMagic); err != nil {
+			return err
+		}
+	}
+	if w.rows > 0 {
+		if err := w.flushRowGroup(); err != nil {
+			return err
+		}
+	}
+
+	schema := []interface{}{thriftStruct{
+		{4, "schema"},
+		{5, int32(len(w.columns))},
+	}}
+	for _, column := range w.columns {
+		schema = append(schema, column.parquetSchema())
+	}
+
+	rowGroups := make([]interface{}, 0, len(w.rowGroups))
+	for _, rowGroup := range w.rowGroups {
+		rowGroups = append(rowGroups, rowGroup)
+	}
+
+	var footer bytes.Buffer
+	writeThriftStruct(&footer, thriftStruct{
+		{1, int32(1)},
+		{2, thriftList{elementType: thriftStructType, items: schema}},
+		{3, w.totalRows},
+		{4, thriftList{elementType: thriftStructType, items: rowGroups}},
+		{6, "fullcycle-auction_go"},
+	})
+	length := binary.LittleEndian.AppendUint32(nil, uint32(footer.Len()))
+
+	_, err := w.out.Write(append(append(footer.Bytes(), length...), parquetMagic...))
+	return err
+}
+
+// flushRowGroup writes the buffered rows as a row group, a column chunk of a
+// single data page per column.
+func (w *parquetWriter[T]) flushRowGroup() error {
+	if w.out.offset == 0 {
+		if _, err := w.out.Write(parquetMagic); err != nil {
+			return err
+		}
+	}
+
+	var chunks []interface{}
+	var totalSize int64
+	for i, column := range w.columns {
+		data := w.values[i]
+		compressed := snappy.Encode(nil, data)
+
+		var header bytes.Buffer
+		writeThriftStruct(&header, thriftStruct{
+			{1, int32(parquetDataPage)},
+			{2, int32(len(data))},
+			{3, int32(len(compressed))},
+			{5, thriftStruct{
+				{1, int32(w.rows)},
+				{2, int32(parquetPlainEncoding)},
+				{3, int32(parquetRLEEncoding)},
+				{4, int32(parquetRLEEncoding)},
+			}},
+		})
+
+		offset := w.out.offset
+		if _, err := w.out.Write(header.Bytes()); err != nil {
+			return err
+		}
+		if _, err := w.out.Write(compressed); err != nil {
+			return err
+		}
+
+		uncompressedSize := int64(header.Len() + len(data))
+		totalSize += uncompressedSize
+		chunks = append(chunks, thriftStruct{
+			{2, offset},
+			{3, thriftStruct{
+				{1, column.parquetType()},
+				{2, thriftList{elementType: thriftI32Type, items: []interface{}{int32(parquetPlainEncoding)}}},
+				{3, thriftList{elementType: thriftBinaryType, items: []interface{}{column.name}}},
+				{4, int32(parquetSnappyCodec)},
+				{5, w.rows},
+				{6, uncompressedSize},
+				{7, int64(header.Len() + len(compressed))},
+				{9, offset},
+			}},
+		})
+
+		w.values[i] = w.values[i][:0]
+	}
+
+	w.rowGroups = append(w.rowGroups, thriftStruct{
+		{1, thriftList{elementType: thriftStructType, items: chunks}},
+		{2, totalSize},
+		{3, w.rows},
+	})
+	w.totalRows += w.rows
+	w.rows = 0
+
+	return nil
+}
+
+func (c column) parquetType() int32 {
+	switch c.kind {
+	case stringColumn:
+		return parquetByteArray
+	case float64Column:
+		return parquetDouble
+	default:
+		return parquetInt64
+	}
+}
+
+// parquetSchema returns the SchemaElement of the column, with both the
+// converted type and the logical type so older and newer readers agree on it.
+func (c column) parquetSchema() thriftStruct {
+	element := thriftStruct{
+		{1, c.parquetType()},
+		{3, int32(parquetRequired)},
+		{4, c.name},
+	}
+
+	switch c.kind {
+	case stringColumn:
+		element = append(element,
+			thriftField{6, int32(parquetConvertedUTF8)},
+			thriftField{10, thriftStruct{{1, thriftStruct{}}}})
+	case timeColumn:
+		element = append(element,
+			thriftField{6, int32(parquetConvertedTimestampMillis)},
+			thriftField{10, thriftStruct{{8, thriftStruct{
+				{1, true},
+				{2, thriftStruct{{1, thriftStruct{}}}},
+			}}}})
+	}
+
+	return element
+}
+
+type countingWriter struct {
+	writer io.Writer
+	offset int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.offset += int64(n)
+	return n, err
+}
+
+// thriftStruct is a Thrift struct as its fields, in increasing id order.
+// Values are int32, int64, string, bool, thriftStruct or thriftList.
+type thriftStruct []thriftField
+
+type thriftField struct {
+	id    int16
+	value interface{}
+}
+
+type thriftList struct {
+	elementType byte
+	items       []interface{}
+}
+
+// Types of the Thrift compact protocol.
+const (
+	thriftTrueType   = 1
+	thriftFalseType  = 2
+	thriftI32Type    = 5
+	thriftI64Type    = 6
+	thriftBinaryType = 8
+	thriftListType   = 9
+	thriftStructType = 12
+)
+
+func writeThriftStruct(buffer *bytes.Buffer, fields thriftStruct) {
+	var lastId int16
+	for _, field := range fields {
+		fieldType := thriftTypeOf(field.value)
+		if delta := field.id - lastId; delta > 0 && delta <= 15 {
+			buffer.WriteByte(byte(delta)<<4 | fieldType)
+		} else {
+			buffer.WriteByte(fieldType)
+			writeVarint(buffer, int64(field.id))
+		}
+		lastId = field.id
+
+		if _, ok := field.value.(bool); !ok {
+			writeThriftValue(buffer, field.value)
+		}
+	}
+	buffer.WriteByte(0)
+}
+
+func writeThriftValue(buffer *bytes.Buffer, value interface{}) {
+	switch value := value.(type) {
+	case int32:
+		writeVarint(buffer, int64(value))
+	case int64:
+		writeVarint(buffer, value)
+	case string:
+		writeUvarint(buffer, uint64(len(value)))
+		buffer.WriteString(value)
+	case thriftStruct:
+		writeThriftStruct(buffer, value)
+	case thriftList:
+		if len(value.items) < 15 {
+			buffer.WriteByte(byte(len(value.items))<<4 | value.elementType)
+		} else {
+			buffer.WriteByte(0xf0 | value.elementType)
+			writeUvarint(buffer, uint64(len(value.items)))
+		}
+		for _, item := range value.items {
+			writeThriftValue(buffer, item)
+		}
+	}
+}
+
+func thriftTypeOf(value interface{}) byte {
+	switch value := value.(type) {
+	case bool:
+		if value {
+			return thriftTrueType
+		}
+		return thriftFalseType
+	case int32:
+		return thriftI32Type
+	case int64:
+		return thriftI64Type
+	case string:
+		return thriftBinaryType
+	case thriftList:
+		return thriftListType
+	default:
+		return thriftStructType
+	}
+}
+
+// writeVarint writes a zigzag encoded varint, as the compact protocol stores
+// i16, i32 and i64 values.
+func writeVarint(buffer *bytes.Buffer, value int64) {
+	writeUvarint(buffer, uint64(value<<1^value>>63))
+}
+
+func writeUvarint(buffer *bytes.Buffer, value uint64) {
+	var encoded [binary.MaxVarintLen64]byte
+	buffer.Write(encoded[:binary.PutUvarint(encoded[:], value)])
+}
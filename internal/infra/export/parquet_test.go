@@ -0,0 +1,209 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+// thriftReader decodes the compact protocol into generic values: structs as
+// maps by field id, lists as slices, integers as int64 and binaries as
+// strings.
+type thriftReader struct {
+	t    *testing.T
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	r.pos++
+	return r.data[r.pos-1]
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.t.Fatalf("invalid varint at offset %d", r.pos)
+	}
+	r.pos += n
+	return value
+}
+
+func (r *thriftReader) varint() int64 {
+	value := r.uvarint()
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var id int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.varint())
+		}
+
+		switch fieldType := header & 0x0f; fieldType {
+		case thriftTrueType:
+			fields[id] = true
+		case thriftFalseType:
+			fields[id] = false
+		default:
+			fields[id] = r.readValue(fieldType)
+		}
+	}
+}
+
+func (r *thriftReader) readValue(valueType byte) interface{} {
+	switch valueType {
+	case thriftI32Type, thriftI64Type:
+		return r.varint()
+	case thriftBinaryType:
+		length := int(r.uvarint())
+		r.pos += length
+		return string(r.data[r.pos-length : r.pos])
+	case thriftListType:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		items := make([]interface{}, size)
+		for i := range items {
+			items[i] = r.readValue(header & 0x0f)
+		}
+		return items
+	case thriftStructType:
+		return r.readStruct()
+	}
+
+	r.t.Fatalf("unexpected thrift type %d at offset %d", valueType, r.pos)
+	return nil
+}
+
+// readParquet reads the testRow columns of a file written by the Parquet
+// writer, following the footer's offsets as a reader would.
+func readParquet(t *testing.T, file []byte) []testRow {
+	t.Helper()
+
+	if !bytes.HasPrefix(file, parquetMagic) || !bytes.HasSuffix(file, parquetMagic) {
+		t.Fatalf("expected the file to start and end with %q", parquetMagic)
+	}
+	footerEnd := len(file) - 8
+	footerStart := footerEnd - int(binary.LittleEndian.Uint32(file[footerEnd:]))
+	footer := &thriftReader{t: t, data: file[footerStart:footerEnd]}
+	metadata := footer.readStruct()
+
+	schema := metadata[2].([]interface{})
+	wantSchema := []struct {
+		name          string
+		physicalType  int64
+		convertedType interface{}
+	}{
+		{"id", parquetByteArray, int64(parquetConvertedUTF8)},
+		{"amount", parquetDouble, nil},
+		{"count", parquetInt64, nil},
+		{"at", parquetInt64, int64(parquetConvertedTimestampMillis)},
+	}
+	if len(schema) != len(wantSchema)+1 || schema[0].(map[int16]interface{})[5] != int64(len(wantSchema)) {
+		t.Fatalf("unexpected schema %v", schema)
+	}
+	for i, want := range wantSchema {
+		element := schema[i+1].(map[int16]interface{})
+		if element[4] != want.name || element[1] != want.physicalType || element[6] != want.convertedType {
+			t.Errorf("unexpected schema element %v, expected %+v", element, want)
+		}
+	}
+
+	var rows []testRow
+	for _, rowGroup := range metadata[4].([]interface{}) {
+		rowGroup := rowGroup.(map[int16]interface{})
+		groupRows := make([]testRow, rowGroup[3].(int64))
+
+		for i, chunk := range rowGroup[1].([]interface{}) {
+			chunkMetadata := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+			if chunkMetadata[4] != int64(parquetSnappyCodec) || chunkMetadata[5] != int64(len(groupRows)) {
+				t.Fatalf("unexpected column chunk %v", chunkMetadata)
+			}
+
+			page := &thriftReader{t: t, data: file, pos: int(chunkMetadata[9].(int64))}
+			header := page.readStruct()
+			compressed := file[page.pos : page.pos+int(header[3].(int64))]
+			data, err := snappy.Decode(nil, compressed)
+			if err != nil || len(data) != int(header[2].(int64)) {
+				t.Fatalf("decoding page of column %d: %v", i, err)
+			}
+
+			for j := range groupRows {
+				field := reflect.ValueOf(&groupRows[j]).Elem().Field(i)
+				switch wantSchema[i].physicalType {
+				case parquetByteArray:
+					length := int(binary.LittleEndian.Uint32(data))
+					field.SetString(string(data[4 : 4+length]))
+					data = data[4+length:]
+				case parquetDouble:
+					field.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(data)))
+					data = data[8:]
+				default:
+					value := int64(binary.LittleEndian.Uint64(data))
+					if field.Type() == timeType {
+						field.Set(reflect.ValueOf(time.UnixMilli(value).UTC()))
+					} else {
+						field.SetInt(value)
+					}
+					data = data[8:]
+				}
+			}
+			if len(data) != 0 {
+				t.Errorf("%d bytes left in the page of column %d", len(data), i)
+			}
+		}
+
+		rows = append(rows, groupRows...)
+	}
+
+	if metadata[3] != int64(len(rows)) {
+		t.Errorf("the footer counts %v rows, the row groups %d", metadata[3], len(rows))
+	}
+	return rows
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	at := time.Date(2026, 10, 19, 12, 30, 0, 250*int(time.Millisecond)+999, time.FixedZone("BRT", -3*3600))
+	ids := []string{"", "a", "b, \"quoted\"", "relógio"}
+
+	for _, count := range []int{0, 3, parquetRowGroupSize + 1} {
+		rows := make([]testRow, count)
+		for i := range rows {
+			rows[i] = testRow{
+				Id:     ids[i%len(ids)],
+				Amount: float64(i) + 0.25,
+				Count:  int64(i) - 1,
+				At:     at.Add(time.Duration(i) * time.Second),
+			}
+		}
+
+		read := readParquet(t, writeRows(t, Parquet, rows...))
+		if len(read) != count {
+			t.Fatalf("%d rows: read %d rows", count, len(read))
+		}
+		for i, row := range rows {
+			want := row
+			want.At = row.At.UTC().Truncate(time.Millisecond)
+			if read[i] != want {
+				t.Fatalf("%d rows: expected row %d to be %+v, got %+v", count, i, want, read[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package lifecycle coordinates the shutdown of the application: background
+// workers and closing hooks are stopped in the reverse order they were
+// registered, like deferred calls, within a single deadline.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+type Manager struct {
+	mutex sync.Mutex
+	hooks []hook
+}
+
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+type shutdownKey struct{}
+
+// shutdown holds the context of the shutdown that stopped a worker.
+type shutdown struct {
+	ctx context.Context
+}
+
+// Go runs worker in its own goroutine. On shutdown the worker's context is
+// canceled and the manager waits for it to return; ShutdownContext gives the
+// worker the deadline it has to do so.
+func (m *Manager) Go(name string, worker func(ctx context.Context)) {
+	stop := &shutdown{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), shutdownKey{}, stop))
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		worker(ctx)
+	}()
+
+	m.OnShutdown(name, func(shutdownCtx context.Context) error {
+		stop.ctx = shutdownCtx
+		cancel()
+
+		select {
+		case <-done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	})
+}
+
+// ShutdownContext returns the context of the shutdown that canceled ctx, the
+// context of a worker run by Go, so the work a worker does on its way out is
+// bound by the shutdown deadline. Otherwise it returns context.Background().
+func ShutdownContext(ctx context.Context) context.Context {
+	// The shutdown context is set before ctx is canceled, so it is only read
+	// once ctx reports it.
+	stop, ok := ctx.Value(shutdownKey{}).(*shutdown)
+	if !ok || ctx.Err() == nil || stop.ctx == nil {
+		return context.Background()
+	}
+
+	return stop.ctx
+}
+
+// OnShutdown registers stop to be called on shutdown.
+func (m *Manager) OnShutdown(name string, stop func(ctx context.Context) error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.hooks = append(m.hooks, hook{name: name, stop: stop})
+}
+
+// Wait blocks until one of signals is received.
+func (m *Manager) Wait(signals ...os.Signal) os.Signal {
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+	defer signal.Stop(received)
+
+	return <-received
+}
+
+// Shutdown runs every hook, most recently registered first. Hooks still run
+// after the deadline expires, with an already expired context, so resources
+// get released even when an earlier hook timed out.
+func (m *Manager) Shutdown(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	m.mutex.Lock()
+	hooks := m.hooks
+	m.hooks = nil
+	m.mutex.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].stop(ctx); err != nil {
+			logger.Error(fmt.Sprintf("Error stopping %s", hooks[i].name), err)
+			errs = append(errs, fmt.Errorf("%s: %w", hooks[i].name, err))
+			continue
+		}
+
+		logger.Info(fmt.Sprintf("Stopped %s", hooks[i].name))
+	}
+
+	return errors.Join(errs...)
+}
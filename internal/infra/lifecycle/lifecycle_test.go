@@ -0,0 +1,87 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestShutdownStopsInReverseOrder(t *testing.T) {
+	manager := NewManager()
+
+	var stopped []string
+	record := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			stopped = append(stopped, name)
+			return nil
+		}
+	}
+
+	manager.OnShutdown("database", record("database"))
+	manager.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		stopped = append(stopped, "worker")
+	})
+	manager.OnShutdown("server", record("server"))
+
+	if err := manager.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if got := fmt.Sprint(stopped); got != "[server worker database]" {
+		t.Errorf("unexpected stop order %s", got)
+	}
+}
+
+func TestShutdownRunsRemainingHooksAfterDeadline(t *testing.T) {
+	manager := NewManager()
+
+	closed := false
+	manager.OnShutdown("database", func(ctx context.Context) error {
+		closed = true
+		return nil
+	})
+	manager.Go("stuck worker", func(ctx context.Context) {
+		time.Sleep(time.Second)
+	})
+
+	start := time.Now()
+	if err := manager.Shutdown(10 * time.Millisecond); err == nil {
+		t.Error("expected the stuck worker to fail the shutdown")
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("shutdown took %s, past its deadline", elapsed)
+	}
+	if !closed {
+		t.Error("expected the database hook to run after the deadline")
+	}
+}
+
+func TestShutdownContextCarriesTheDeadline(t *testing.T) {
+	manager := NewManager()
+
+	var running, stopping context.Context
+	started := make(chan struct{})
+	manager.Go("worker", func(ctx context.Context) {
+		running = ShutdownContext(ctx)
+		close(started)
+		<-ctx.Done()
+		stopping = ShutdownContext(ctx)
+	})
+	<-started
+
+	start := time.Now()
+	if err := manager.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if _, ok := running.Deadline(); ok {
+		t.Error("expected no deadline before the shutdown")
+	}
+	deadline, ok := stopping.Deadline()
+	if !ok || deadline.Before(start) || deadline.After(start.Add(time.Second+100*time.Millisecond)) {
+		t.Errorf("expected the shutdown deadline, got %v %v", deadline, ok)
+	}
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/internal/entity/blob_entity"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NewBlobStore opens the blob store selected by BLOB_STORE. database is only
+// used by gridfs.
+func NewBlobStore(cfg *config.Config, database *mongo.Database) (blob_entity.BlobStore, error) {
+	switch cfg.BlobStore {
+	case "gridfs":
+		return NewGridFSBlobStore(database, "auction_images")
+	default:
+		return NewFileSystemBlobStore(cfg.BlobStorePath)
+	}
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type FileSystemBlobStore struct {
+	basePath string
+}
+
+func NewFileSystemBlobStore(basePath string) (*FileSystemBlobStore, error) {
+	absolutePath, err := filepath.Abs(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(absolutePath, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileSystemBlobStore{basePath: absolutePath}, nil
+}
+
+func (fs *FileSystemBlobStore) Put(
+	ctx context.Context, key string, data io.Reader) *internal_error.InternalError {
+	path, err := fs.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		logger.Error(fmt.Sprintf("Error creating directory for blob %s", key), err)
+		return internal_error.NewInternalServerError("Error trying to store blob")
+	}
+
+	tmpFile, errTmp := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if errTmp != nil {
+		logger.Error(fmt.Sprintf("Error creating temporary file for blob %s", key), errTmp)
+		return internal_error.NewInternalServerError("Error trying to store blob")
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, errCopy := io.Copy(tmpFile, data); errCopy != nil {
+		tmpFile.Close()
+		logger.Error(fmt.Sprintf("Error writing blob %s", key), errCopy)
+		return internal_error.NewInternalServerError("Error trying to store blob")
+	}
+
+	if errClose := tmpFile.Close(); errClose != nil {
+		logger.Error(fmt.Sprintf("Error closing blob %s", key), errClose)
+		return internal_error.NewInternalServerError("Error trying to store blob")
+	}
+
+	if errRename := os.Rename(tmpFile.Name(), path); errRename != nil {
+		logger.Error(fmt.Sprintf("Error moving blob %s into place", key), errRename)
+		return internal_error.NewInternalServerError("Error trying to store blob")
+	}
+
+	return nil
+}
+
+func (fs *FileSystemBlobStore) Open(
+	ctx context.Context, key string) (io.ReadCloser, *internal_error.InternalError) {
+	path, err := fs.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, errOpen := os.Open(path)
+	if errOpen != nil {
+		if errors.Is(errOpen, os.ErrNotExist) {
+			return nil, internal_error.NewNotFoundError("Blob not found")
+		}
+
+		logger.Error(fmt.Sprintf("Error opening blob %s", key), errOpen)
+		return nil, internal_error.NewInternalServerError("Error trying to read blob")
+	}
+
+	return file, nil
+}
+
+func (fs *FileSystemBlobStore) Delete(
+	ctx context.Context, key string) *internal_error.InternalError {
+	path, err := fs.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	if errRemove := os.Remove(path); errRemove != nil && !errors.Is(errRemove, os.ErrNotExist) {
+		logger.Error(fmt.Sprintf("Error deleting blob %s", key), errRemove)
+		return internal_error.NewInternalServerError("Error trying to delete blob")
+	}
+
+	return nil
+}
+
+func (fs *FileSystemBlobStore) resolve(key string) (string, *internal_error.InternalError) {
+	path := filepath.Join(fs.basePath, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, fs.basePath+string(os.PathSeparator)) {
+		return "", internal_error.NewBadRequestError("Invalid blob key")
+	}
+
+	return path, nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type GridFSBlobStore struct {
+	bucket *gridfs.Bucket
+}
+
+func NewGridFSBlobStore(database *mongo.Database, bucketName string) (*GridFSBlobStore, error) {
+	bucket, err := gridfs.NewBucket(database, options.GridFSBucket().SetName(bucketName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GridFSBlobStore{bucket: bucket}, nil
+}
+
+func (gs *GridFSBlobStore) Put(
+	ctx context.Context, key string, data io.Reader) *internal_error.InternalError {
+	if err := gs.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	if _, err := gs.bucket.UploadFromStream(key, data); err != nil {
+		logger.Error(fmt.Sprintf("Error uploading blob %s to GridFS", key), err)
+		return internal_error.NewInternalServerError("Error trying to store blob")
+	}
+
+	return nil
+}
+
+func (gs *GridFSBlobStore) Open(
+	ctx context.Context, key string) (io.ReadCloser, *internal_error.InternalError) {
+	stream, err := gs.bucket.OpenDownloadStreamByName(key)
+	if err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return nil, internal_error.NewNotFoundError("Blob not found")
+		}
+
+		logger.Error(fmt.Sprintf("Error opening blob %s from GridFS", key), err)
+		return nil, internal_error.NewInternalServerError("Error trying to read blob")
+	}
+
+	return stream, nil
+}
+
+func (gs *GridFSBlobStore) Delete(
+	ctx context.Context, key string) *internal_error.InternalError {
+	cursor, err := gs.bucket.FindContext(ctx, bson.M{"filename": key})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error finding blob %s in GridFS", key), err)
+		return internal_error.NewInternalServerError("Error trying to delete blob")
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var file struct {
+			Id interface{} `bson:"_id"`
+		}
+		if err := cursor.Decode(&file); err != nil {
+			logger.Error(fmt.Sprintf("Error decoding blob %s from GridFS", key), err)
+			return internal_error.NewInternalServerError("Error trying to delete blob")
+		}
+
+		if err := gs.bucket.DeleteContext(ctx, file.Id); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			logger.Error(fmt.Sprintf("Error deleting blob %s from GridFS", key), err)
+			return internal_error.NewInternalServerError("Error trying to delete blob")
+		}
+	}
+
+	return nil
+}
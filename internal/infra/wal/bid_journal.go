@@ -0,0 +1,165 @@
+package wal
+
+import (
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DeadLetterFile holds, one JSON record per line, the bids set aside by
+// DeadLetter in the journal directory.
+const DeadLetterFile = "dead_letters.ndjson"
+
+type bidRecord struct {
+	Id        string  `json:"id"`
+	UserId    string  `json:"user_id"`
+	AuctionId string  `json:"auction_id"`
+	Amount    float64 `json:"amount"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+type deadLetterRecord struct {
+	bidRecord
+	Reason   string `json:"reason"`
+	FailedAt int64  `json:"failed_at"`
+}
+
+// BidJournal stores bids in a Log as JSON records.
+type BidJournal struct {
+	log *Log
+	dir string
+
+	deadLetterMutex sync.Mutex
+}
+
+func NewBidJournal(dir string, segmentSize int64) (*BidJournal, error) {
+	log, err := Open(dir, segmentSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BidJournal{log: log, dir: dir}, nil
+}
+
+func newBidRecord(bid bid_entity.Bid) bidRecord {
+	return bidRecord{
+		Id:        bid.Id,
+		UserId:    bid.UserId,
+		AuctionId: bid.AuctionId,
+		Amount:    bid.Amount,
+		Timestamp: bid.Timestamp.UnixNano(),
+	}
+}
+
+func (bj *BidJournal) Append(bid bid_entity.Bid) (uint64, *internal_error.InternalError) {
+	payload, err := json.Marshal(newBidRecord(bid))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error encoding bid %s for the journal", bid.Id), err)
+		return 0, internal_error.NewInternalServerError("Error trying to record bid")
+	}
+
+	lsn, err := bj.log.Append(payload)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error appending bid %s to the journal", bid.Id), err)
+		return 0, internal_error.NewInternalServerError("Error trying to record bid")
+	}
+
+	return lsn, nil
+}
+
+func (bj *BidJournal) Commit(lsn uint64) *internal_error.InternalError {
+	if err := bj.log.Commit(lsn); err != nil {
+		logger.Error(fmt.Sprintf("Error committing bid journal up to %d", lsn), err)
+		return internal_error.NewInternalServerError("Error trying to commit bid journal")
+	}
+
+	return nil
+}
+
+func (bj *BidJournal) Replay(
+	fn func(lsn uint64, bid bid_entity.Bid) *internal_error.InternalError) *internal_error.InternalError {
+	var replayErr *internal_error.InternalError
+	err := bj.log.Replay(func(lsn uint64, payload []byte) error {
+		var record bidRecord
+		if err := json.Unmarshal(payload, &record); err != nil {
+			logger.Error(fmt.Sprintf("Skipping undecodable bid journal record %d", lsn), err)
+			return nil
+		}
+
+		replayErr = fn(lsn, bid_entity.Bid{
+			Id:        record.Id,
+			UserId:    record.UserId,
+			AuctionId: record.AuctionId,
+			Amount:    record.Amount,
+			Timestamp: time.Unix(0, record.Timestamp),
+		})
+		if replayErr != nil {
+			return replayErr
+		}
+		return nil
+	})
+
+	if replayErr != nil {
+		return replayErr
+	} else if err != nil {
+		logger.Error("Error replaying bid journal", err)
+		return internal_error.NewInternalServerError("Error trying to replay bid journal")
+	}
+
+	return nil
+}
+
+// DeadLetter appends the bid to DeadLetterFile and fsyncs it, so it is kept
+// once the journal is committed past it.
+func (bj *BidJournal) DeadLetter(bid bid_entity.Bid, reason string) *internal_error.InternalError {
+	payload, err := json.Marshal(deadLetterRecord{
+		bidRecord: newBidRecord(bid),
+		Reason:    reason,
+		FailedAt:  time.Now().UnixNano(),
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error encoding dead letter of bid %s", bid.Id), err)
+		return internal_error.NewInternalServerError("Error trying to set aside bid")
+	}
+
+	bj.deadLetterMutex.Lock()
+	defer bj.deadLetterMutex.Unlock()
+
+	if err := appendLine(filepath.Join(bj.dir, DeadLetterFile), payload); err != nil {
+		logger.Error(fmt.Sprintf("Error writing dead letter of bid %s", bid.Id), err)
+		return internal_error.NewInternalServerError("Error trying to set aside bid")
+	}
+
+	return nil
+}
+
+func appendLine(path string, payload []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(payload, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+func (bj *BidJournal) Close() error {
+	return bj.log.Close()
+}
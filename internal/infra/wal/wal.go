@@ -0,0 +1,353 @@
+// Package wal implements an append-only write-ahead log split into segment
+// files. Every record gets a log sequence number (LSN) and is fsynced before
+// Append returns; Commit marks a prefix of the log as applied so that fully
+// applied segments can be deleted and are skipped by Replay.
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentSuffix  = ".wal"
+	checkpointFile = "checkpoint"
+
+	// Each record is framed as payload length, CRC-32 of LSN and payload,
+	// LSN, then the payload itself.
+	headerSize = 4 + 4 + 8
+
+	maxRecordSize = 16 << 20
+)
+
+type Log struct {
+	dir         string
+	segmentSize int64
+
+	mutex      sync.Mutex
+	segments   []uint64
+	active     *os.File
+	activeSize int64
+	nextLSN    uint64
+	checkpoint uint64
+	closed     bool
+}
+
+// Open opens or creates the log in dir, truncating a torn record left at the
+// end of the last segment by a crash. Segments rotate once they exceed
+// segmentSize bytes.
+func Open(dir string, segmentSize int64) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	log := &Log{dir: dir, segmentSize: segmentSize, nextLSN: 1}
+
+	checkpoint, err := readCheckpoint(filepath.Join(dir, checkpointFile))
+	if err != nil {
+		return nil, err
+	}
+	log.checkpoint = checkpoint
+
+	if log.segments, err = listSegments(dir); err != nil {
+		return nil, err
+	}
+
+	if len(log.segments) == 0 {
+		log.nextLSN = checkpoint + 1
+		if err := log.rotate(log.nextLSN); err != nil {
+			return nil, err
+		}
+		return log, nil
+	}
+
+	lastSegment := log.segments[len(log.segments)-1]
+	lastLSN, validSize, err := scanSegment(log.segmentPath(lastSegment), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	log.nextLSN = lastSegment
+	if lastLSN > 0 {
+		log.nextLSN = lastLSN + 1
+	}
+	if log.nextLSN <= checkpoint {
+		log.nextLSN = checkpoint + 1
+	}
+
+	active, err := os.OpenFile(log.segmentPath(lastSegment), os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := active.Truncate(validSize); err != nil {
+		active.Close()
+		return nil, err
+	}
+	if _, err := active.Seek(validSize, io.SeekStart); err != nil {
+		active.Close()
+		return nil, err
+	}
+
+	log.active = active
+	log.activeSize = validSize
+
+	return log, nil
+}
+
+// Append durably writes payload and returns its LSN.
+func (l *Log) Append(payload []byte) (uint64, error) {
+	if len(payload) > maxRecordSize {
+		return 0, fmt.Errorf("wal record of %d bytes exceeds the %d bytes limit", len(payload), maxRecordSize)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.closed {
+		return 0, errors.New("wal is closed")
+	}
+
+	if l.activeSize > 0 && l.activeSize+headerSize+int64(len(payload)) > l.segmentSize {
+		if err := l.rotate(l.nextLSN); err != nil {
+			return 0, err
+		}
+	}
+
+	lsn := l.nextLSN
+	record := encodeRecord(lsn, payload)
+	if _, err := l.active.Write(record); err != nil {
+		return 0, err
+	}
+	if err := l.active.Sync(); err != nil {
+		return 0, err
+	}
+
+	l.nextLSN++
+	l.activeSize += int64(len(record))
+
+	return lsn, nil
+}
+
+// Commit records that every record up to lsn has been applied and deletes
+// the segments holding only applied records.
+func (l *Log) Commit(lsn uint64) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if lsn <= l.checkpoint {
+		return nil
+	}
+
+	if err := writeCheckpoint(filepath.Join(l.dir, checkpointFile), lsn); err != nil {
+		return err
+	}
+	l.checkpoint = lsn
+
+	// A segment ends right before the next one starts; the active segment is
+	// never deleted.
+	for len(l.segments) > 1 && l.segments[1]-1 <= lsn {
+		if err := os.Remove(l.segmentPath(l.segments[0])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		l.segments = l.segments[1:]
+	}
+
+	return nil
+}
+
+// Replay calls fn, in order, for every record not yet committed.
+func (l *Log) Replay(fn func(lsn uint64, payload []byte) error) error {
+	l.mutex.Lock()
+	segments := append([]uint64(nil), l.segments...)
+	checkpoint := l.checkpoint
+	l.mutex.Unlock()
+
+	for _, segment := range segments {
+		_, _, err := scanSegment(l.segmentPath(segment), func(lsn uint64, payload []byte) error {
+			if lsn <= checkpoint {
+				return nil
+			}
+			return fn(lsn, payload)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+
+	return l.active.Close()
+}
+
+func (l *Log) rotate(firstLSN uint64) error {
+	file, err := os.OpenFile(l.segmentPath(firstLSN), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := syncDir(l.dir); err != nil {
+		file.Close()
+		return err
+	}
+
+	if l.active != nil {
+		l.active.Close()
+	}
+
+	l.active = file
+	l.activeSize = 0
+	l.segments = append(l.segments, firstLSN)
+
+	return nil
+}
+
+func (l *Log) segmentPath(firstLSN uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d%s", firstLSN, segmentSuffix))
+}
+
+func encodeRecord(lsn uint64, payload []byte) []byte {
+	record := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint64(record[8:16], lsn)
+	copy(record[headerSize:], payload)
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(record[8:]))
+
+	return record
+}
+
+// scanSegment reads every valid record of a segment, stopping silently at a
+// torn or corrupt tail. It returns the last LSN read and the size of the
+// valid prefix of the file.
+func scanSegment(path string, fn func(lsn uint64, payload []byte) error) (uint64, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	header := make([]byte, headerSize)
+
+	var lastLSN uint64
+	var validSize int64
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return lastLSN, validSize, nil
+		}
+
+		length := binary.BigEndian.Uint32(header[0:4])
+		if length > maxRecordSize {
+			return lastLSN, validSize, nil
+		}
+
+		body := make([]byte, 8+length)
+		copy(body, header[8:16])
+		if _, err := io.ReadFull(reader, body[8:]); err != nil {
+			return lastLSN, validSize, nil
+		}
+		if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(header[4:8]) {
+			return lastLSN, validSize, nil
+		}
+
+		lsn := binary.BigEndian.Uint64(body[:8])
+		if fn != nil {
+			if err := fn(lsn, body[8:]); err != nil {
+				return lastLSN, validSize, err
+			}
+		}
+
+		lastLSN = lsn
+		validSize += int64(headerSize + length)
+	}
+}
+
+func listSegments(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []uint64
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), segmentSuffix)
+		if !ok {
+			continue
+		}
+
+		firstLSN, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected wal segment %s", entry.Name())
+		}
+		segments = append(segments, firstLSN)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+
+	return segments, nil
+}
+
+func readCheckpoint(path string) (uint64, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// writeCheckpoint replaces the checkpoint atomically through a rename.
+func writeCheckpoint(path string, lsn uint64) error {
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.WriteString(strconv.FormatUint(lsn, 10)); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+func syncDir(dir string) error {
+	directory, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer directory.Close()
+
+	return directory.Sync()
+}
@@ -0,0 +1,114 @@
+package wal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func replayAll(t *testing.T, log *Log) []string {
+	t.Helper()
+
+	var records []string
+	if err := log.Replay(func(lsn uint64, payload []byte) error {
+		records = append(records, fmt.Sprintf("%d:%s", lsn, payload))
+		return nil
+	}); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	return records
+}
+
+func mustOpen(t *testing.T, dir string, segmentSize int64) *Log {
+	t.Helper()
+
+	log, err := Open(dir, segmentSize)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { log.Close() })
+
+	return log
+}
+
+func TestReplayAfterReopen(t *testing.T) {
+	dir := t.TempDir()
+	log := mustOpen(t, dir, 1<<20)
+
+	for _, payload := range []string{"a", "b", "c"} {
+		if _, err := log.Append([]byte(payload)); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if err := log.Commit(1); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	log.Close()
+
+	reopened := mustOpen(t, dir, 1<<20)
+	if got := fmt.Sprint(replayAll(t, reopened)); got != "[2:b 3:c]" {
+		t.Errorf("unexpected replay %s", got)
+	}
+
+	lsn, err := reopened.Append([]byte("d"))
+	if err != nil || lsn != 4 {
+		t.Errorf("expected lsn 4 after reopening, got %d (%v)", lsn, err)
+	}
+}
+
+func TestRotationAndCommitDeleteSegments(t *testing.T) {
+	dir := t.TempDir()
+	log := mustOpen(t, dir, headerSize+1)
+
+	for _, payload := range []string{"a", "b", "c"} {
+		if _, err := log.Append([]byte(payload)); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	segments, _ := listSegments(dir)
+	if len(segments) != 3 {
+		t.Fatalf("expected a segment per record, got %v", segments)
+	}
+
+	if err := log.Commit(2); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	segments, _ = listSegments(dir)
+	if fmt.Sprint(segments) != "[3]" {
+		t.Errorf("expected only the segment starting at 3 to remain, got %v", segments)
+	}
+	if got := fmt.Sprint(replayAll(t, log)); got != "[3:c]" {
+		t.Errorf("unexpected replay %s", got)
+	}
+}
+
+func TestTornTailIsTruncated(t *testing.T) {
+	dir := t.TempDir()
+	log := mustOpen(t, dir, 1<<20)
+
+	if _, err := log.Append([]byte("complete")); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	log.Close()
+
+	segment := filepath.Join(dir, fmt.Sprintf("%020d%s", 1, segmentSuffix))
+	file, err := os.OpenFile(segment, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	file.Write(encodeRecord(2, []byte("torn"))[:headerSize+2])
+	file.Close()
+
+	reopened := mustOpen(t, dir, 1<<20)
+	if _, err := reopened.Append([]byte("next")); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	if got := fmt.Sprint(replayAll(t, reopened)); got != "[1:complete 2:next]" {
+		t.Errorf("unexpected replay %s", got)
+	}
+}
@@ -36,3 +36,24 @@ func NewConflictError(message string) *InternalError {
 		Err:     "conflict",
 	}
 }
+
+func NewForbiddenError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Err:     "forbidden",
+	}
+}
+
+func NewServiceUnavailableError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Err:     "service_unavailable",
+	}
+}
+
+func NewTooManyRequestsError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Err:     "too_many_requests",
+	}
+}
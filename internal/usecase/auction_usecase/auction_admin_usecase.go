@@ -0,0 +1,55 @@
+package auction_usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/internal_error"
+)
+
+// ForceCloseAuction completes an active auction before its end time.
+func (au *AuctionUseCase) ForceCloseAuction(
+	ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError) {
+	auction, err := au.auctionRepositoryInterface.ForceCloseAuction(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	au.closureScheduler.Unschedule(auctionId)
+
+	auctionOutputDTO := NewAuctionOutputDTO(auction)
+	return &auctionOutputDTO, nil
+}
+
+// RecomputeWinner rebuilds the highest bid and bid count of an auction from
+// its stored bids, repairing stats left behind by bids that were counted but
+// never stored, and reports the winning bid.
+func (au *AuctionUseCase) RecomputeWinner(
+	ctx context.Context,
+	auctionId string) (*WinningInfoOutputDTO, *internal_error.InternalError) {
+	if _, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId); err != nil {
+		return nil, err
+	}
+
+	bids, err := au.bidRepositoryInterface.FindBidByAuctionId(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	var highestBid float64
+	for _, bid := range bids {
+		if bid.Amount > highestBid {
+			highestBid = bid.Amount
+		}
+	}
+
+	if err := au.auctionRepositoryInterface.UpdateBidStats(
+		ctx, auctionId, highestBid, int64(len(bids))); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Recomputed auction %s: highest bid %v over %d bids",
+		auctionId, highestBid, len(bids)))
+
+	return au.FindWinningBidByAuctionId(ctx, auctionId)
+}
@@ -0,0 +1,72 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"fullcycle-auction_go/internal/usecase/job_usecase"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestForceCloseAndRecomputeWinner(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	auctions := memory.NewAuctionRepository(fake)
+	bids := memory.NewBidRepository(auctions)
+	auctionUseCase := NewAuctionUseCase(auctions, bids, memory.NewCategoryRepository(), nil,
+		NewAuctionClosureScheduler(auctions, fake),
+		job_usecase.NewJobUseCase(memory.NewJobRepository(), fake, "worker-1"), fake)
+
+	auction, err := auction_entity.CreateAuction(
+		"Watch", uuid.New().String(), "A description long enough", auction_entity.New, fake.Now())
+	if err != nil {
+		t.Fatalf("CreateAuction entity: %v", err)
+	}
+	auction.EndTime = fake.Now().Add(time.Hour)
+	if err := auctions.CreateAuction(ctx, auction); err != nil {
+		t.Fatalf("CreateAuction: %v", err)
+	}
+
+	winner := bid_entity.Bid{Id: uuid.New().String(), UserId: uuid.New().String(),
+		AuctionId: auction.Id, Amount: 30, Timestamp: fake.Now()}
+	other := bid_entity.Bid{Id: uuid.New().String(), UserId: uuid.New().String(),
+		AuctionId: auction.Id, Amount: 20, Timestamp: fake.Now()}
+	if _, err := bids.CreateBid(ctx, []bid_entity.Bid{winner, other}); err != nil {
+		t.Fatalf("CreateBid: %v", err)
+	}
+
+	// Stats left behind by a bid that was counted but never stored.
+	if err := auctions.UpdateBidStats(ctx, auction.Id, 50, 3); err != nil {
+		t.Fatalf("UpdateBidStats: %v", err)
+	}
+
+	fake.Advance(time.Minute)
+	closed, err := auctionUseCase.ForceCloseAuction(ctx, auction.Id)
+	if err != nil {
+		t.Fatalf("ForceCloseAuction: %v", err)
+	}
+	if closed.Status != AuctionStatus(auction_entity.Completed) || !closed.EndTime.Equal(fake.Now()) {
+		t.Errorf("expected auction closed at %v, got status %d at %v", fake.Now(), closed.Status, closed.EndTime)
+	}
+
+	recomputed, err := auctionUseCase.RecomputeWinner(ctx, auction.Id)
+	if err != nil {
+		t.Fatalf("RecomputeWinner: %v", err)
+	}
+	if recomputed.Auction.HighestBid != 30 || recomputed.Auction.BidCount != 2 {
+		t.Errorf("expected highest bid 30 over 2 bids, got %v over %d",
+			recomputed.Auction.HighestBid, recomputed.Auction.BidCount)
+	}
+	if recomputed.Bid == nil || recomputed.Bid.Id != winner.Id {
+		t.Errorf("expected bid %s to win, got %+v", winner.Id, recomputed.Bid)
+	}
+
+	if _, err := auctionUseCase.RecomputeWinner(ctx, uuid.New().String()); err == nil || err.Err != "not_found" {
+		t.Errorf("expected an unknown auction to be reported, got %v", err)
+	}
+}
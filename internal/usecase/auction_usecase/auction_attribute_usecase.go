@@ -0,0 +1,129 @@
+package auction_usecase
+
+import (
+	"fmt"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maxAttributeFilters = 10
+
+var attributeRangeSuffixes = []auction_entity.AttributeOperator{
+	auction_entity.AttributeGte,
+	auction_entity.AttributeLte,
+	auction_entity.AttributeGt,
+	auction_entity.AttributeLt,
+}
+
+// parseAttributeFilters turns attr.<key>[_op] query params into filters.
+// When a category is given its schema is used to coerce values; otherwise
+// numeric and boolean looking values also match their typed form.
+func parseAttributeFilters(
+	params map[string][]string,
+	category *category_entity.Category) ([]auction_entity.AttributeFilter, *internal_error.InternalError) {
+	if len(params) > maxAttributeFilters {
+		return nil, internal_error.NewBadRequestError(
+			fmt.Sprintf("At most %d attribute filters are allowed", maxAttributeFilters))
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	filters := make([]auction_entity.AttributeFilter, 0, len(params))
+	for _, name := range names {
+		key, operator := splitAttributeOperator(name, category)
+		if !category_entity.IsValidAttributeKey(key) {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Invalid attribute filter %q", name))
+		}
+
+		var definition *category_entity.AttributeDefinition
+		if category != nil {
+			definition = category.FindAttribute(key)
+		}
+
+		values, err := attributeFilterValues(key, operator, params[name], definition)
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, auction_entity.AttributeFilter{
+			Key:      key,
+			Operator: operator,
+			Values:   values,
+		})
+	}
+
+	return filters, nil
+}
+
+func splitAttributeOperator(
+	name string, category *category_entity.Category) (string, auction_entity.AttributeOperator) {
+	if category != nil && category.FindAttribute(name) != nil {
+		return name, auction_entity.AttributeEq
+	}
+
+	for _, operator := range attributeRangeSuffixes {
+		if key, ok := strings.CutSuffix(name, "_"+string(operator)); ok {
+			return key, operator
+		}
+	}
+
+	return name, auction_entity.AttributeEq
+}
+
+func attributeFilterValues(
+	key string,
+	operator auction_entity.AttributeOperator,
+	rawValues []string,
+	definition *category_entity.AttributeDefinition) ([]interface{}, *internal_error.InternalError) {
+	if operator != auction_entity.AttributeEq {
+		if len(rawValues) != 1 {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute filter %s_%s accepts a single value", key, operator))
+		}
+
+		if definition != nil && definition.Type != category_entity.AttributeNumber {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute %q is not a number and cannot be filtered by range", key))
+		}
+
+		number, err := strconv.ParseFloat(strings.TrimSpace(rawValues[0]), 64)
+		if err != nil {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute filter %s_%s must be a number", key, operator))
+		}
+
+		return []interface{}{number}, nil
+	}
+
+	values := make([]interface{}, 0, len(rawValues))
+	for _, rawValue := range rawValues {
+		rawValue = strings.TrimSpace(rawValue)
+
+		if definition != nil {
+			value, err := definition.Normalize(rawValue)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			continue
+		}
+
+		values = append(values, rawValue)
+		if number, err := strconv.ParseFloat(rawValue, 64); err == nil {
+			values = append(values, number)
+		} else if boolean, err := strconv.ParseBool(rawValue); err == nil {
+			values = append(values, boolean)
+		}
+	}
+
+	return values, nil
+}
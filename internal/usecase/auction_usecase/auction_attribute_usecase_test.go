@@ -0,0 +1,155 @@
+package auction_usecase
+
+import (
+	"fmt"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAttributeFilters(t *testing.T) {
+	watches := &category_entity.Category{
+		Name: "Watches",
+		Attributes: []category_entity.AttributeDefinition{
+			{Key: "year", Type: category_entity.AttributeNumber},
+			{Key: "brand", Type: category_entity.AttributeString},
+			{Key: "boxed", Type: category_entity.AttributeBoolean},
+			// Named like a range filter on "size"; the schema wins.
+			{Key: "size_gt", Type: category_entity.AttributeNumber},
+		},
+	}
+
+	tooMany := map[string][]string{}
+	for i := 0; i <= maxAttributeFilters; i++ {
+		tooMany[fmt.Sprintf("key%d", i)] = []string{"x"}
+	}
+
+	tests := []struct {
+		name     string
+		params   map[string][]string
+		category *category_entity.Category
+		want     []auction_entity.AttributeFilter
+		wantErr  string
+	}{
+		{
+			name:   "range operators",
+			params: map[string][]string{"year_gte": {" 2019 "}, "year_lte": {"2021.5"}, "year_gt": {"1"}, "year_lt": {"-1"}},
+			want: []auction_entity.AttributeFilter{
+				{Key: "year", Operator: auction_entity.AttributeGt, Values: []interface{}{1.0}},
+				{Key: "year", Operator: auction_entity.AttributeGte, Values: []interface{}{2019.0}},
+				{Key: "year", Operator: auction_entity.AttributeLt, Values: []interface{}{-1.0}},
+				{Key: "year", Operator: auction_entity.AttributeLte, Values: []interface{}{2021.5}},
+			},
+		},
+		{
+			name:     "range on a number attribute of the category",
+			params:   map[string][]string{"year_gte": {"2019"}},
+			category: watches,
+			want: []auction_entity.AttributeFilter{
+				{Key: "year", Operator: auction_entity.AttributeGte, Values: []interface{}{2019.0}},
+			},
+		},
+		{
+			name:    "range without a value",
+			params:  map[string][]string{"year_gte": nil},
+			wantErr: "year_gte accepts a single value",
+		},
+		{
+			name:    "range with several values",
+			params:  map[string][]string{"year_lte": {"2019", "2020"}},
+			wantErr: "year_lte accepts a single value",
+		},
+		{
+			name:    "range with an empty value",
+			params:  map[string][]string{"year_gte": {""}},
+			wantErr: "year_gte must be a number",
+		},
+		{
+			name:    "range with a value that is not a number",
+			params:  map[string][]string{"year_gte": {"abc"}},
+			wantErr: "year_gte must be a number",
+		},
+		{
+			name:     "range on a string attribute of the category",
+			params:   map[string][]string{"brand_gte": {"1"}},
+			category: watches,
+			wantErr:  `"brand" is not a number`,
+		},
+		{
+			name:     "attribute named like a range filter",
+			params:   map[string][]string{"size_gt": {"40"}},
+			category: watches,
+			want: []auction_entity.AttributeFilter{
+				{Key: "size_gt", Operator: auction_entity.AttributeEq, Values: []interface{}{40.0}},
+			},
+		},
+		{
+			name:   "unknown operator is part of the key",
+			params: map[string][]string{"year_between": {"2019"}, "year_gte_lte": {"2020"}},
+			want: []auction_entity.AttributeFilter{
+				{Key: "year_between", Operator: auction_entity.AttributeEq, Values: []interface{}{"2019", 2019.0}},
+				{Key: "year_gte", Operator: auction_entity.AttributeLte, Values: []interface{}{2020.0}},
+			},
+		},
+		{
+			name:    "unknown operator that is not a valid key",
+			params:  map[string][]string{"year~gte": {"2019"}},
+			wantErr: `Invalid attribute filter "year~gte"`,
+		},
+		{
+			name:    "operator without a key",
+			params:  map[string][]string{"_gte": {"2019"}},
+			wantErr: `Invalid attribute filter "_gte"`,
+		},
+		{
+			name:   "equality without a category matches typed values",
+			params: map[string][]string{"boxed": {"true"}, "brand": {"Omega", "1960"}},
+			want: []auction_entity.AttributeFilter{
+				{Key: "boxed", Operator: auction_entity.AttributeEq, Values: []interface{}{"true", true}},
+				{Key: "brand", Operator: auction_entity.AttributeEq, Values: []interface{}{"Omega", "1960", 1960.0}},
+			},
+		},
+		{
+			name:     "equality coerced by the category",
+			params:   map[string][]string{"boxed": {"1"}, "year": {" 1960 "}},
+			category: watches,
+			want: []auction_entity.AttributeFilter{
+				{Key: "boxed", Operator: auction_entity.AttributeEq, Values: []interface{}{true}},
+				{Key: "year", Operator: auction_entity.AttributeEq, Values: []interface{}{1960.0}},
+			},
+		},
+		{
+			name:     "equality with a value the category rejects",
+			params:   map[string][]string{"year": {"old"}},
+			category: watches,
+			wantErr:  `"year" must be a number`,
+		},
+		{
+			name:    "too many filters",
+			params:  tooMany,
+			wantErr: fmt.Sprintf("At most %d attribute filters", maxAttributeFilters),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filters, err := parseAttributeFilters(test.params, test.category)
+
+			if test.wantErr != "" {
+				if err == nil || err.Err != "bad_request" || !strings.Contains(err.Message, test.wantErr) {
+					t.Fatalf("expected a bad request containing %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseAttributeFilters: %v", err)
+			}
+			if !reflect.DeepEqual(filters, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, filters)
+			}
+		})
+	}
+}
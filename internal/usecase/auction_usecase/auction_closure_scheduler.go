@@ -0,0 +1,245 @@
+package auction_usecase
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/infra/events"
+	"sync"
+	"time"
+)
+
+// closeRetryDelay is how long the scheduler waits before retrying a closure
+// that failed.
+const closeRetryDelay = time.Second
+
+// AuctionClosureScheduler closes each active auction at its end time. The end
+// times are kept in a min-heap, loaded from the repository when Run starts and
+// updated as auctions are created, extended or cancelled.
+//
+// Schedule and Unschedule are no-ops while Run is not running, as on replicas
+// that are not the leader. Auctions created or extended on those replicas are
+// picked up by the periodic resync, and an auction extended after it was
+// loaded is simply rescheduled when its old end time comes.
+type AuctionClosureScheduler struct {
+	auctionRepository auction_entity.AuctionRepositoryInterface
+	clock             clock.Clock
+
+	// Closed is published once per auction the scheduler closes.
+	Closed *events.Bus[auction_entity.AuctionClosed]
+
+	mutex    sync.Mutex
+	running  bool
+	endTimes map[string]time.Time
+	queue    closureHeap
+	wake     chan struct{}
+}
+
+func NewAuctionClosureScheduler(
+	auctionRepository auction_entity.AuctionRepositoryInterface,
+	clock clock.Clock) *AuctionClosureScheduler {
+	return &AuctionClosureScheduler{
+		auctionRepository: auctionRepository,
+		clock:             clock,
+		Closed:            events.NewBus[auction_entity.AuctionClosed](),
+		endTimes:          map[string]time.Time{},
+		wake:              make(chan struct{}, 1),
+	}
+}
+
+// Schedule closes auctionId at endTime, replacing any end time scheduled for
+// it before.
+func (s *AuctionClosureScheduler) Schedule(auctionId string, endTime time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.running {
+		return
+	}
+
+	s.schedule(auctionId, endTime)
+}
+
+func (s *AuctionClosureScheduler) schedule(auctionId string, endTime time.Time) {
+	if current, ok := s.endTimes[auctionId]; ok && current.Equal(endTime) {
+		return
+	}
+
+	s.endTimes[auctionId] = endTime
+	heap.Push(&s.queue, closureEntry{auctionId: auctionId, endTime: endTime})
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Unschedule drops the closure of auctionId, as when it is cancelled.
+func (s *AuctionClosureScheduler) Unschedule(auctionId string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.endTimes, auctionId)
+}
+
+// Run closes auctions as their end times come until ctx is canceled, making
+// the closures under fence.
+func (s *AuctionClosureScheduler) Run(ctx context.Context, fence lease_entity.Fence) {
+	s.mutex.Lock()
+	s.running = true
+	s.mutex.Unlock()
+
+	defer func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		s.running = false
+		s.endTimes = map[string]time.Time{}
+		s.queue = nil
+	}()
+
+	s.resync(ctx)
+
+	resync := s.clock.NewTimer(config.Current().AuctionSchedulerResync)
+	defer resync.Stop()
+
+	timer := s.clock.NewTimer(s.untilNext())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-resync.C():
+			s.resync(ctx)
+			resync.Reset(config.Current().AuctionSchedulerResync)
+		case <-s.wake:
+		case <-timer.C():
+			s.closeDue(ctx, fence)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C():
+			default:
+			}
+		}
+		timer.Reset(s.untilNext())
+	}
+}
+
+// resync schedules every active auction stored in the repository. Entries
+// scheduled before are kept: an auction that is no longer active is dropped
+// when its closure finds nothing to close.
+func (s *AuctionClosureScheduler) resync(ctx context.Context) {
+	endTimes, err := s.auctionRepository.FindAuctionEndTimes(ctx)
+	if err != nil {
+		logger.Error("Error trying to load auction end times", err)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, endTime := range endTimes {
+		s.schedule(endTime.AuctionId, endTime.EndTime)
+	}
+}
+
+// untilNext returns how long until the earliest end time, discarding the heap
+// entries replaced by a later Schedule or Unschedule.
+func (s *AuctionClosureScheduler) untilNext() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for s.queue.Len() > 0 {
+		next := s.queue[0]
+		if endTime, ok := s.endTimes[next.auctionId]; ok && endTime.Equal(next.endTime) {
+			return next.endTime.Sub(s.clock.Now())
+		}
+		heap.Pop(&s.queue)
+	}
+
+	return config.Current().AuctionSchedulerResync
+}
+
+// popDue removes and returns the auctions whose end time has passed.
+func (s *AuctionClosureScheduler) popDue() []closureEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var due []closureEntry
+	now := s.clock.Now()
+	for s.queue.Len() > 0 && !s.queue[0].endTime.After(now) {
+		entry := heap.Pop(&s.queue).(closureEntry)
+		if endTime, ok := s.endTimes[entry.auctionId]; !ok || !endTime.Equal(entry.endTime) {
+			continue
+		}
+
+		delete(s.endTimes, entry.auctionId)
+		due = append(due, entry)
+	}
+
+	return due
+}
+
+func (s *AuctionClosureScheduler) closeDue(ctx context.Context, fence lease_entity.Fence) {
+	for _, entry := range s.popDue() {
+		closed, err := s.auctionRepository.CloseAuction(ctx, entry.auctionId, fence)
+		if err != nil {
+			s.Schedule(entry.auctionId, s.clock.Now().Add(closeRetryDelay))
+			continue
+		}
+
+		if closed {
+			logger.Info(fmt.Sprintf("Auction %s closed", entry.auctionId))
+			s.Closed.Publish(auction_entity.AuctionClosed{
+				AuctionId: entry.auctionId,
+				EndTime:   entry.endTime,
+				ClosedAt:  s.clock.Now(),
+			})
+			continue
+		}
+
+		// Nothing was closed: the auction was extended, cancelled or closed
+		// by someone else. Only an extended one needs scheduling again.
+		auction, err := s.auctionRepository.FindAuctionById(ctx, entry.auctionId)
+		if err != nil {
+			if err.Err != "not_found" {
+				s.Schedule(entry.auctionId, s.clock.Now().Add(closeRetryDelay))
+			}
+			continue
+		}
+
+		if auction.Status == auction_entity.Active {
+			endTime := auction.EndTime
+			if !endTime.After(s.clock.Now()) {
+				endTime = s.clock.Now().Add(closeRetryDelay)
+			}
+			s.Schedule(entry.auctionId, endTime)
+		}
+	}
+}
+
+type closureEntry struct {
+	auctionId string
+	endTime   time.Time
+}
+
+type closureHeap []closureEntry
+
+func (h closureHeap) Len() int            { return len(h) }
+func (h closureHeap) Less(i, j int) bool  { return h[i].endTime.Before(h[j].endTime) }
+func (h closureHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *closureHeap) Push(x interface{}) { *h = append(*h, x.(closureEntry)) }
+func (h *closureHeap) Pop() interface{} {
+	old := *h
+	entry := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return entry
+}
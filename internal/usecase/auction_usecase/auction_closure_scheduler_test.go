@@ -0,0 +1,121 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestClosureSchedulerClosesAtEndTime(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	auctions := memory.NewAuctionRepository(fake)
+	scheduler := NewAuctionClosureScheduler(auctions, fake)
+
+	var mutex sync.Mutex
+	closed := map[string]auction_entity.AuctionClosed{}
+	scheduler.Closed.Subscribe(func(event auction_entity.AuctionClosed) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		closed[event.AuctionId] = event
+	})
+
+	createAuction := func(endIn time.Duration) *auction_entity.Auction {
+		auction, err := auction_entity.CreateAuction(
+			"Watch", uuid.New().String(), "A description long enough", auction_entity.New, fake.Now())
+		if err != nil {
+			t.Fatalf("CreateAuction entity: %v", err)
+		}
+		auction.EndTime = fake.Now().Add(endIn)
+		if err := auctions.CreateAuction(ctx, auction); err != nil {
+			t.Fatalf("CreateAuction: %v", err)
+		}
+		scheduler.Schedule(auction.Id, auction.EndTime)
+		return auction
+	}
+
+	// Created before Run, so only loaded from the repository.
+	loaded := createAuction(200 * time.Millisecond)
+
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scheduler.Run(runCtx, lease_entity.Fence{})
+	}()
+	waitForIdle(t, scheduler, fake)
+
+	first := createAuction(100 * time.Millisecond)
+	cancelled := createAuction(150 * time.Millisecond)
+	extended := createAuction(250 * time.Millisecond)
+
+	if _, err := auctions.CancelAuction(ctx, cancelled.Id); err != nil {
+		t.Fatalf("CancelAuction: %v", err)
+	}
+	scheduler.Unschedule(cancelled.Id)
+
+	extendedEnd := extended.EndTime.Add(150 * time.Millisecond)
+	if _, err := auctions.ExtendAuction(ctx, extended.Id, extendedEnd); err != nil {
+		t.Fatalf("ExtendAuction: %v", err)
+	}
+	scheduler.Schedule(extended.Id, extendedEnd)
+
+	const step = 10 * time.Millisecond
+	for i := 0; i < 60; i++ {
+		waitForIdle(t, scheduler, fake)
+		fake.Advance(step)
+	}
+	waitForIdle(t, scheduler, fake)
+
+	stop()
+	<-done
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for _, auction := range []*auction_entity.Auction{loaded, first, extended} {
+		event, ok := closed[auction.Id]
+		if !ok {
+			t.Errorf("expected auction ending at %v to be closed", auction.EndTime)
+			continue
+		}
+		if late := event.ClosedAt.Sub(event.EndTime); late < 0 || late > step {
+			t.Errorf("expected auction to close right at its end time, closed %v after", late)
+		}
+	}
+
+	if event := closed[extended.Id]; event.ClosedAt.Before(extendedEnd) {
+		t.Errorf("expected extended auction to close after %v, closed at %v", extendedEnd, event.ClosedAt)
+	}
+	if _, ok := closed[cancelled.Id]; ok {
+		t.Errorf("expected cancelled auction not to be closed")
+	}
+	if len(closed) != 3 {
+		t.Errorf("expected one closure event per auction, got %d", len(closed))
+	}
+}
+
+// waitForIdle waits until the scheduler is running and blocked on both its
+// closure and resync timers with no wake-up pending.
+func waitForIdle(t *testing.T, scheduler *AuctionClosureScheduler, fake *clock.Fake) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		scheduler.mutex.Lock()
+		running := scheduler.running
+		scheduler.mutex.Unlock()
+		if running && fake.Timers() == 2 && len(scheduler.wake) == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("scheduler did not settle")
+}
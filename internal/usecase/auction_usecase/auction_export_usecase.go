@@ -0,0 +1,137 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type AuctionExportInputDTO struct {
+	Status      *AuctionStatus `form:"status" binding:"omitempty,oneof=0 1 2 3"`
+	Category    string         `form:"category"`
+	CreatedFrom *time.Time     `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   *time.Time     `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndedFrom   *time.Time     `form:"ended_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndedTo     *time.Time     `form:"ended_to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// AuctionExportDTO is a row of the auctions export. Its fields are the
+// columns of the export files: new columns go at the end, and existing ones
+// are neither renamed nor retyped.
+type AuctionExportDTO struct {
+	Id          string    `json:"id"`
+	ProductName string    `json:"product_name"`
+	CategoryId  string    `json:"category_id"`
+	Category    string    `json:"category"`
+	Description string    `json:"description"`
+	Condition   string    `json:"condition"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndTime     time.Time `json:"end_time"`
+	HighestBid  float64   `json:"highest_bid"`
+	BidCount    int64     `json:"bid_count"`
+}
+
+// BidExportDTO is a row of the bids export, with the same rules as
+// AuctionExportDTO.
+type BidExportDTO struct {
+	Id        string    `json:"id"`
+	AuctionId string    `json:"auction_id"`
+	UserId    string    `json:"user_id"`
+	Amount    float64   `json:"amount"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ExportAuctions calls fn for every auction matching the input, oldest first,
+// as they are read from the repository. An error returned by fn stops the
+// export.
+func (au *AuctionUseCase) ExportAuctions(
+	ctx context.Context,
+	exportInput AuctionExportInputDTO,
+	fn func(row AuctionExportDTO) error) *internal_error.InternalError {
+	filter, err := au.exportFilter(ctx, exportInput)
+	if err != nil {
+		return err
+	}
+
+	return au.auctionRepositoryInterface.ExportAuctions(ctx, *filter,
+		func(auction *auction_entity.Auction) *internal_error.InternalError {
+			return writeExportRow(fn, AuctionExportDTO{
+				Id:          auction.Id,
+				ProductName: auction.ProductName,
+				CategoryId:  auction.CategoryId,
+				Category:    auction.Category,
+				Description: auction.Description,
+				Condition:   auction.Condition.String(),
+				Status:      auction.Status.String(),
+				CreatedAt:   auction.Timestamp.UTC(),
+				StartsAt:    auction.StartsAt.UTC(),
+				EndTime:     auction.EndTime.UTC(),
+				HighestBid:  auction.HighestBid,
+				BidCount:    auction.BidCount,
+			})
+		})
+}
+
+// ExportBids calls fn for every bid on the auctions matching the input,
+// grouped by auction in the order of ExportAuctions.
+func (au *AuctionUseCase) ExportBids(
+	ctx context.Context,
+	exportInput AuctionExportInputDTO,
+	fn func(row BidExportDTO) error) *internal_error.InternalError {
+	filter, err := au.exportFilter(ctx, exportInput)
+	if err != nil {
+		return err
+	}
+
+	return au.bidRepositoryInterface.ExportBids(ctx, *filter,
+		func(bid *bid_entity.Bid) *internal_error.InternalError {
+			return writeExportRow(fn, BidExportDTO{
+				Id:        bid.Id,
+				AuctionId: bid.AuctionId,
+				UserId:    bid.UserId,
+				Amount:    bid.Amount,
+				Timestamp: bid.Timestamp.UTC(),
+			})
+		})
+}
+
+func (au *AuctionUseCase) exportFilter(
+	ctx context.Context,
+	exportInput AuctionExportInputDTO) (*auction_entity.AuctionExportFilter, *internal_error.InternalError) {
+	filter := &auction_entity.AuctionExportFilter{
+		CreatedFrom: exportInput.CreatedFrom,
+		CreatedTo:   exportInput.CreatedTo,
+		EndedFrom:   exportInput.EndedFrom,
+		EndedTo:     exportInput.EndedTo,
+	}
+
+	if exportInput.Status != nil {
+		status := auction_entity.AuctionStatus(*exportInput.Status)
+		filter.Status = &status
+	}
+
+	// The category tree is resolved up front: the repository must not be
+	// queried while the export reads from it.
+	if exportInput.Category != "" {
+		_, categoryIds, err := au.findCategoryTree(ctx, exportInput.Category)
+		if err != nil {
+			return nil, err
+		}
+		filter.CategoryIds = categoryIds
+	}
+
+	return filter, nil
+}
+
+func writeExportRow[T any](fn func(row T) error, row T) *internal_error.InternalError {
+	if err := fn(row); err != nil {
+		logger.Error("Error writing export row", err)
+		return internal_error.NewInternalServerError("Error writing the export")
+	}
+	return nil
+}
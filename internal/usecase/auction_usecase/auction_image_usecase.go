@@ -0,0 +1,270 @@
+package auction_usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const thumbnailMaxSize = 320
+
+// maxImagePixels bounds the decoded size of an upload: a few compressed
+// bytes can describe an image that takes gigabytes once decoded.
+const maxImagePixels = 25_000_000
+
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type AuctionImageOutputDTO struct {
+	Id           string    `json:"id"`
+	Url          string    `json:"url"`
+	ThumbnailUrl string    `json:"thumbnail_url"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	Position     int       `json:"position"`
+	Timestamp    time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+}
+
+func (au *AuctionUseCase) AddAuctionImage(
+	ctx context.Context,
+	auctionId string,
+	data io.Reader) (*AuctionImageOutputDTO, *internal_error.InternalError) {
+	maxImageSize := config.Current().MaxImageSize
+
+	content, errRead := io.ReadAll(io.LimitReader(data, maxImageSize+1))
+	if errRead != nil {
+		logger.Error(fmt.Sprintf("Error reading image upload for auction %s", auctionId), errRead)
+		return nil, internal_error.NewBadRequestError("Error trying to read image")
+	}
+
+	if int64(len(content)) > maxImageSize {
+		return nil, internal_error.NewBadRequestError(
+			fmt.Sprintf("Image exceeds the maximum size of %d bytes", maxImageSize))
+	}
+
+	contentType := http.DetectContentType(content)
+	extension, ok := allowedImageTypes[contentType]
+	if !ok {
+		return nil, internal_error.NewBadRequestError("Image must be a JPEG, PNG or GIF file")
+	}
+
+	imageConfig, _, errConfig := image.DecodeConfig(bytes.NewReader(content))
+	if errConfig != nil {
+		return nil, internal_error.NewBadRequestError("Image file is corrupted or unsupported")
+	}
+
+	if int64(imageConfig.Width)*int64(imageConfig.Height) > maxImagePixels {
+		return nil, internal_error.NewBadRequestError(
+			fmt.Sprintf("Image exceeds the maximum of %d pixels", maxImagePixels))
+	}
+
+	decodedImage, _, errDecode := image.Decode(bytes.NewReader(content))
+	if errDecode != nil {
+		return nil, internal_error.NewBadRequestError("Image file is corrupted or unsupported")
+	}
+
+	thumbnail, thumbnailExtension, errThumb := encodeThumbnail(decodedImage, contentType)
+	if errThumb != nil {
+		logger.Error(fmt.Sprintf("Error generating thumbnail for auction %s", auctionId), errThumb)
+		return nil, internal_error.NewInternalServerError("Error trying to generate thumbnail")
+	}
+
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if auction.Status != auction_entity.Active && auction.Status != auction_entity.Scheduled {
+		return nil, internal_error.NewBadRequestError("Images can only be added to active or scheduled auctions")
+	}
+
+	imageId := uuid.New().String()
+	auctionImage := auction_entity.AuctionImage{
+		Id:           imageId,
+		Key:          fmt.Sprintf("auctions/%s/%s%s", auctionId, imageId, extension),
+		ThumbnailKey: fmt.Sprintf("auctions/%s/%s_thumb%s", auctionId, imageId, thumbnailExtension),
+		ContentType:  contentType,
+		Size:         int64(len(content)),
+		Timestamp:    au.clock.Now(),
+	}
+
+	if err := au.blobStore.Put(ctx, auctionImage.Key, bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+
+	if err := au.blobStore.Put(ctx, auctionImage.ThumbnailKey, bytes.NewReader(thumbnail)); err != nil {
+		au.deleteImageBlobs(ctx, auctionImage)
+		return nil, err
+	}
+
+	if err := au.auctionRepositoryInterface.AddAuctionImage(
+		ctx, auctionId, auctionImage, config.Current().MaxAuctionImages); err != nil {
+		au.deleteImageBlobs(ctx, auctionImage)
+		return nil, err
+	}
+
+	imageOutput := NewAuctionImageOutputDTO(auctionId, auctionImage, len(auction.Images))
+	return &imageOutput, nil
+}
+
+func (au *AuctionUseCase) OpenAuctionImage(
+	ctx context.Context,
+	auctionId, imageId string,
+	thumbnail bool) (io.ReadCloser, string, *internal_error.InternalError) {
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, auctionImage := range auction.Images {
+		if auctionImage.Id != imageId {
+			continue
+		}
+
+		key, contentType := auctionImage.Key, auctionImage.ContentType
+		if thumbnail {
+			key = auctionImage.ThumbnailKey
+			contentType = thumbnailContentType(auctionImage.ContentType)
+		}
+
+		reader, err := au.blobStore.Open(ctx, key)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return reader, contentType, nil
+	}
+
+	return nil, "", internal_error.NewNotFoundError("Auction image not found")
+}
+
+func (au *AuctionUseCase) RemoveAuctionImage(
+	ctx context.Context,
+	auctionId, imageId string) *internal_error.InternalError {
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return err
+	}
+
+	for _, auctionImage := range auction.Images {
+		if auctionImage.Id != imageId {
+			continue
+		}
+
+		if err := au.auctionRepositoryInterface.RemoveAuctionImage(ctx, auctionId, imageId); err != nil {
+			return err
+		}
+
+		au.deleteImageBlobs(ctx, auctionImage)
+		return nil
+	}
+
+	return internal_error.NewNotFoundError("Auction image not found")
+}
+
+func (au *AuctionUseCase) deleteImageBlobs(ctx context.Context, auctionImage auction_entity.AuctionImage) {
+	for _, key := range []string{auctionImage.Key, auctionImage.ThumbnailKey} {
+		if err := au.blobStore.Delete(ctx, key); err != nil {
+			logger.Error(fmt.Sprintf("Error deleting orphaned blob %s", key), err)
+		}
+	}
+}
+
+func NewAuctionImageOutputDTO(
+	auctionId string, auctionImage auction_entity.AuctionImage, position int) AuctionImageOutputDTO {
+	return AuctionImageOutputDTO{
+		Id:           auctionImage.Id,
+		Url:          fmt.Sprintf("/auction/%s/images/%s", auctionId, auctionImage.Id),
+		ThumbnailUrl: fmt.Sprintf("/auction/%s/images/%s/thumbnail", auctionId, auctionImage.Id),
+		ContentType:  auctionImage.ContentType,
+		Size:         auctionImage.Size,
+		Position:     position,
+		Timestamp:    auctionImage.Timestamp,
+	}
+}
+
+func encodeThumbnail(source image.Image, contentType string) ([]byte, string, error) {
+	thumbnail := resizeToFit(source, thumbnailMaxSize)
+
+	var buffer bytes.Buffer
+	if thumbnailContentType(contentType) == "image/jpeg" {
+		if err := jpeg.Encode(&buffer, thumbnail, &jpeg.Options{Quality: 80}); err != nil {
+			return nil, "", err
+		}
+		return buffer.Bytes(), ".jpg", nil
+	}
+
+	if err := png.Encode(&buffer, thumbnail); err != nil {
+		return nil, "", err
+	}
+	return buffer.Bytes(), ".png", nil
+}
+
+func thumbnailContentType(contentType string) string {
+	if contentType == "image/jpeg" {
+		return contentType
+	}
+
+	return "image/png"
+}
+
+func resizeToFit(source image.Image, maxSize int) image.Image {
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSize && height <= maxSize {
+		return source
+	}
+
+	newWidth, newHeight := maxSize, height*maxSize/width
+	if height > width {
+		newWidth, newHeight = width*maxSize/height, maxSize
+	}
+	if newWidth < 1 {
+		newWidth = 1
+	}
+	if newHeight < 1 {
+		newHeight = 1
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		y0 := bounds.Min.Y + y*height/newHeight
+		y1 := bounds.Min.Y + (y+1)*height/newHeight
+		for x := 0; x < newWidth; x++ {
+			x0 := bounds.Min.X + x*width/newWidth
+			x1 := bounds.Min.X + (x+1)*width/newWidth
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := source.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					count++
+				}
+			}
+
+			thumbnail.Set(x, y, color.RGBA64{
+				R: uint16(r / count), G: uint16(g / count), B: uint16(b / count), A: uint16(a / count),
+			})
+		}
+	}
+
+	return thumbnail
+}
@@ -0,0 +1,111 @@
+package auction_usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"fullcycle-auction_go/internal/infra/storage"
+	"fullcycle-auction_go/internal/usecase/job_usecase"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newImageTestUseCase(t *testing.T) (*AuctionUseCase, *memory.AuctionRepository, *clock.Fake) {
+	t.Helper()
+
+	blobStore, err := storage.NewFileSystemBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileSystemBlobStore: %v", err)
+	}
+
+	fake := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	auctions := memory.NewAuctionRepository(fake)
+	jobUseCase := job_usecase.NewJobUseCase(memory.NewJobRepository(), fake, "worker-1")
+	auctionUseCase := NewAuctionUseCase(auctions, memory.NewBidRepository(auctions), memory.NewCategoryRepository(),
+		blobStore, NewAuctionClosureScheduler(auctions, fake), jobUseCase, fake)
+
+	return auctionUseCase.(*AuctionUseCase), auctions, fake
+}
+
+func newImageTestAuction(
+	t *testing.T, auctions *memory.AuctionRepository, fake *clock.Fake,
+	status auction_entity.AuctionStatus) *auction_entity.Auction {
+	t.Helper()
+
+	auction, err := auction_entity.CreateAuction(
+		"Pocket Watch", "4619d391-857f-4036-99eb-192bf17e8313", "A description long enough",
+		auction_entity.New, fake.Now())
+	if err != nil {
+		t.Fatalf("CreateAuction entity: %v", err)
+	}
+	auction.Status = status
+	auction.EndTime = auction.StartsAt.Add(time.Hour)
+
+	if err := auctions.CreateAuction(context.Background(), auction); err != nil {
+		t.Fatalf("CreateAuction: %v", err)
+	}
+	return auction
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := png.Encode(&out, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return out.Bytes()
+}
+
+// pngHeader returns the start of a PNG file declaring an image of the given
+// size, enough for the dimensions to be read without decoding any pixel.
+func pngHeader(width, height uint32) []byte {
+	ihdr := binary.BigEndian.AppendUint32(nil, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 0, 0, 0, 0)
+
+	chunk := append([]byte("IHDR"), ihdr...)
+	header := []byte("\x89PNG\r\n\x1a\n")
+	header = binary.BigEndian.AppendUint32(header, uint32(len(ihdr)))
+	header = append(header, chunk...)
+	return binary.BigEndian.AppendUint32(header, crc32.ChecksumIEEE(chunk))
+}
+
+func TestAddAuctionImageRejectsOversizedDimensions(t *testing.T) {
+	auctionUseCase, auctions, fake := newImageTestUseCase(t)
+	auction := newImageTestAuction(t, auctions, fake, auction_entity.Active)
+
+	_, err := auctionUseCase.AddAuctionImage(context.Background(), auction.Id, bytes.NewReader(pngHeader(100000, 100000)))
+	if err == nil || !strings.Contains(err.Message, "pixels") {
+		t.Fatalf("expected an image of 10^10 pixels to be refused, got %v", err)
+	}
+
+	if _, err := auctionUseCase.AddAuctionImage(
+		context.Background(), auction.Id, bytes.NewReader(encodePNG(t, 64, 48))); err != nil {
+		t.Fatalf("AddAuctionImage: %v", err)
+	}
+}
+
+func TestAddAuctionImageAcceptsScheduledAuctions(t *testing.T) {
+	auctionUseCase, auctions, fake := newImageTestUseCase(t)
+
+	scheduled := newImageTestAuction(t, auctions, fake, auction_entity.Scheduled)
+	if _, err := auctionUseCase.AddAuctionImage(
+		context.Background(), scheduled.Id, bytes.NewReader(encodePNG(t, 64, 48))); err != nil {
+		t.Fatalf("expected an image to be added to a scheduled auction, got %v", err)
+	}
+
+	completed := newImageTestAuction(t, auctions, fake, auction_entity.Completed)
+	_, err := auctionUseCase.AddAuctionImage(
+		context.Background(), completed.Id, bytes.NewReader(encodePNG(t, 64, 48)))
+	if err == nil || err.Err != "bad_request" {
+		t.Fatalf("expected an image on a completed auction to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+)
+
+type AuctionImportInputDTO struct {
+	Rows   []AuctionImportRowDTO
+	DryRun bool
+}
+
+// AuctionImportRowDTO is an auction read from an import file. Rows that
+// could not be read or bound carry their errors and are only reported.
+type AuctionImportRowDTO struct {
+	Line    int
+	Auction AuctionInputDTO
+	Errors  []AuctionImportErrorDTO
+}
+
+type AuctionImportErrorDTO struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type AuctionImportedDTO struct {
+	Line int    `json:"line"`
+	Id   string `json:"id"`
+}
+
+type AuctionImportOutputDTO struct {
+	DryRun  bool                    `json:"dry_run"`
+	Rows    int                     `json:"rows"`
+	Valid   int                     `json:"valid"`
+	Created []AuctionImportedDTO    `json:"created"`
+	Errors  []AuctionImportErrorDTO `json:"errors"`
+}
+
+type importedAuction struct {
+	line    int
+	auction *auction_entity.Auction
+}
+
+// ImportAuctions validates every row as CreateAuction would and, unless it is
+// a dry run, stores the valid ones in batches of IMPORT_BATCH_SIZE. A batch
+// that fails to be stored is reported on each of its rows; the others are
+// kept.
+func (au *AuctionUseCase) ImportAuctions(
+	ctx context.Context,
+	importInput AuctionImportInputDTO) (*AuctionImportOutputDTO, *internal_error.InternalError) {
+	output := &AuctionImportOutputDTO{
+		DryRun:  importInput.DryRun,
+		Rows:    len(importInput.Rows),
+		Created: []AuctionImportedDTO{},
+		Errors:  []AuctionImportErrorDTO{},
+	}
+
+	categories := map[string]*category_entity.Category{}
+	var valid []importedAuction
+	for _, row := range importInput.Rows {
+		if len(row.Errors) > 0 {
+			output.Errors = append(output.Errors, row.Errors...)
+			continue
+		}
+
+		category, ok := categories[row.Auction.CategoryId]
+		if !ok {
+			var err *internal_error.InternalError
+			category, err = au.categoryRepositoryInterface.FindCategoryById(ctx, row.Auction.CategoryId)
+			if err != nil && err.Err != "not_found" {
+				return nil, err
+			}
+			categories[row.Auction.CategoryId] = category
+		}
+		if category == nil {
+			output.Errors = append(output.Errors, AuctionImportErrorDTO{
+				Line: row.Line, Field: "category_id", Message: "Category does not exist"})
+			continue
+		}
+
+		auction, err := au.newAuction(row.Auction, category)
+		if err != nil {
+			output.Errors = append(output.Errors, AuctionImportErrorDTO{
+				Line: row.Line, Message: err.Message})
+			continue
+		}
+
+		valid = append(valid, importedAuction{line: row.Line, auction: auction})
+	}
+
+	output.Valid = len(valid)
+	if importInput.DryRun {
+		return output, nil
+	}
+
+	batchSize := config.Current().ImportBatchSize
+	for start := 0; start < len(valid); start += batchSize {
+		end := start + batchSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		au.importBatch(ctx, valid[start:end], output)
+	}
+
+	sort.SliceStable(output.Errors, func(i, j int) bool {
+		return output.Errors[i].Line < output.Errors[j].Line
+	})
+
+	return output, nil
+}
+
+func (au *AuctionUseCase) importBatch(
+	ctx context.Context, batch []importedAuction, output *AuctionImportOutputDTO) {
+	auctions := make([]*auction_entity.Auction, 0, len(batch))
+	stored := make([]importedAuction, 0, len(batch))
+	for _, imported := range batch {
+		if imported.auction.Status == auction_entity.Scheduled {
+			if err := au.scheduleStart(ctx, imported.auction); err != nil {
+				output.Errors = append(output.Errors, AuctionImportErrorDTO{
+					Line: imported.line, Message: err.Message})
+				continue
+			}
+		}
+
+		auctions = append(auctions, imported.auction)
+		stored = append(stored, imported)
+	}
+
+	if err := au.auctionRepositoryInterface.CreateAuctions(ctx, auctions); err != nil {
+		for _, imported := range stored {
+			output.Errors = append(output.Errors, AuctionImportErrorDTO{
+				Line: imported.line, Message: err.Message})
+		}
+		return
+	}
+
+	for _, imported := range stored {
+		if imported.auction.Status == auction_entity.Active {
+			au.closureScheduler.Schedule(imported.auction.Id, imported.auction.EndTime)
+		}
+		output.Created = append(output.Created, AuctionImportedDTO{
+			Line: imported.line, Id: imported.auction.Id})
+	}
+}
@@ -0,0 +1,122 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"fullcycle-auction_go/internal/usecase/job_usecase"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestImportAuctions(t *testing.T) {
+	t.Setenv("IMPORT_BATCH_SIZE", "2")
+	ctx := context.Background()
+
+	fake := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	auctions := memory.NewAuctionRepository(fake)
+	categories := memory.NewCategoryRepository()
+	category, err := category_entity.CreateCategory("Watches", "", []category_entity.AttributeDefinition{
+		{Key: "year", Type: category_entity.AttributeNumber},
+	}, fake.Now())
+	if err != nil {
+		t.Fatalf("CreateCategory entity: %v", err)
+	}
+	if err := categories.CreateCategory(ctx, category); err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+
+	jobUseCase := job_usecase.NewJobUseCase(memory.NewJobRepository(), fake, "worker-1")
+	auctionUseCase := NewAuctionUseCase(auctions, memory.NewBidRepository(auctions), categories,
+		nil, NewAuctionClosureScheduler(auctions, fake), jobUseCase, fake)
+
+	row := func(line int, productName string) AuctionImportRowDTO {
+		return AuctionImportRowDTO{Line: line, Auction: AuctionInputDTO{
+			ProductName: productName,
+			CategoryId:  category.Id,
+			Description: "A description long enough",
+			Condition:   ProductCondition(auction_entity.New),
+			Attributes:  map[string]interface{}{"year": "1970"},
+		}}
+	}
+
+	past := fake.Now().Add(-time.Minute)
+	startsAt := fake.Now().Add(time.Hour)
+	rows := []AuctionImportRowDTO{
+		row(2, "Pocket Watch"),
+		row(3, "Wrist Watch"),
+		row(4, "Unknown Category"),
+		row(5, "Past Start"),
+		row(6, "Unreadable"),
+		row(7, "Scheduled Watch"),
+	}
+	rows[2].Auction.CategoryId = uuid.New().String()
+	rows[3].Auction.StartsAt = &past
+	rows[4].Errors = []AuctionImportErrorDTO{{Line: 6, Field: "condition", Message: "condition must be a number"}}
+	rows[5].Auction.StartsAt = &startsAt
+
+	wantErrors := []AuctionImportErrorDTO{
+		{Line: 4, Field: "category_id", Message: "Category does not exist"},
+		{Line: 5, Message: "Start time must be in the future"},
+		{Line: 6, Field: "condition", Message: "condition must be a number"},
+	}
+	assertErrors := func(output *AuctionImportOutputDTO) {
+		t.Helper()
+		if len(output.Errors) != len(wantErrors) {
+			t.Fatalf("expected errors %+v, got %+v", wantErrors, output.Errors)
+		}
+		for i, want := range wantErrors {
+			if output.Errors[i] != want {
+				t.Errorf("expected error %+v, got %+v", want, output.Errors[i])
+			}
+		}
+	}
+
+	dryRun, err := auctionUseCase.ImportAuctions(ctx, AuctionImportInputDTO{Rows: rows, DryRun: true})
+	if err != nil {
+		t.Fatalf("ImportAuctions dry run: %v", err)
+	}
+	if dryRun.Rows != 6 || dryRun.Valid != 3 || len(dryRun.Created) != 0 {
+		t.Errorf("unexpected dry run %+v", dryRun)
+	}
+	assertErrors(dryRun)
+
+	page, err := auctions.FindAuctions(ctx, auction_entity.AuctionFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("FindAuctions: %v", err)
+	}
+	if len(page.Auctions) != 0 {
+		t.Fatalf("expected a dry run to store nothing, got %d auctions", len(page.Auctions))
+	}
+
+	output, err := auctionUseCase.ImportAuctions(ctx, AuctionImportInputDTO{Rows: rows})
+	if err != nil {
+		t.Fatalf("ImportAuctions: %v", err)
+	}
+	if output.Valid != 3 || len(output.Created) != 3 {
+		t.Fatalf("expected 3 created auctions, got %+v", output)
+	}
+	assertErrors(output)
+
+	for i, line := range []int{2, 3, 7} {
+		created := output.Created[i]
+		if created.Line != line {
+			t.Errorf("expected auction %d from line %d, got line %d", i, line, created.Line)
+		}
+
+		auction, err := auctions.FindAuctionById(ctx, created.Id)
+		if err != nil {
+			t.Fatalf("FindAuctionById line %d: %v", line, err)
+		}
+		if auction.Attributes["year"] != float64(1970) {
+			t.Errorf("expected the year attribute to be normalized, got %v", auction.Attributes["year"])
+		}
+		if line == 7 && auction.Status != auction_entity.Scheduled {
+			t.Errorf("expected the auction with a start time to be scheduled, got %v", auction.Status)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package auction_usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/job_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+const (
+	// StartAuctionJob opens a scheduled auction at its start time.
+	StartAuctionJob = "auction.start"
+
+	// StartOverdueAuctionsJob opens, every minute, the scheduled auctions
+	// whose StartAuctionJob ran out of attempts.
+	StartOverdueAuctionsJob = "auction.start_overdue"
+)
+
+// startOverdueGrace leaves StartAuctionJob the first chance to open an
+// auction before the sweep does.
+const startOverdueGrace = time.Minute
+
+type startAuctionPayload struct {
+	AuctionId string `json:"auction_id"`
+}
+
+func (au *AuctionUseCase) scheduleStart(
+	ctx context.Context, auction *auction_entity.Auction) *internal_error.InternalError {
+	payload, _ := json.Marshal(startAuctionPayload{AuctionId: auction.Id})
+
+	_, err := au.jobUseCase.Enqueue(ctx, job_entity.JobSpec{
+		Type:           StartAuctionJob,
+		Payload:        payload,
+		RunAt:          auction.StartsAt,
+		IdempotencyKey: StartAuctionJob + ":" + auction.Id,
+	})
+	return err
+}
+
+// startAuction runs StartAuctionJob. An auction that is no longer scheduled,
+// as when it was cancelled, is left as it is.
+func (au *AuctionUseCase) startAuction(ctx context.Context, job job_entity.Job) error {
+	var payload startAuctionPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", StartAuctionJob, err)
+	}
+
+	if err := au.openAuction(ctx, payload.AuctionId); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ScheduleStartSweep enqueues StartOverdueAuctionsJob, once for every
+// instance sharing the job store.
+func (au *AuctionUseCase) ScheduleStartSweep(ctx context.Context) *internal_error.InternalError {
+	_, err := au.jobUseCase.Enqueue(ctx, job_entity.JobSpec{
+		Type:           StartOverdueAuctionsJob,
+		Cron:           "* * * * *",
+		IdempotencyKey: StartOverdueAuctionsJob,
+	})
+	return err
+}
+
+// startOverdueAuctions runs StartOverdueAuctionsJob.
+func (au *AuctionUseCase) startOverdueAuctions(ctx context.Context, job job_entity.Job) error {
+	overdue, err := au.auctionRepositoryInterface.FindOverdueScheduledAuctions(
+		ctx, au.clock.Now().Add(-startOverdueGrace))
+	if err != nil {
+		return err
+	}
+
+	for _, auctionId := range overdue {
+		logger.Info(fmt.Sprintf("Auction %s is past its start time, starting it", auctionId))
+		if err := au.openAuction(ctx, auctionId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// openAuction starts a scheduled auction and schedules its closure.
+func (au *AuctionUseCase) openAuction(ctx context.Context, auctionId string) *internal_error.InternalError {
+	started, err := au.auctionRepositoryInterface.StartAuction(ctx, auctionId)
+	if err != nil {
+		return err
+	}
+
+	if !started {
+		return nil
+	}
+
+	logger.Info(fmt.Sprintf("Auction %s started", auctionId))
+
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		// The closure scheduler picks the auction up on its next resync.
+		return nil
+	}
+
+	au.closureScheduler.Schedule(auction.Id, auction.EndTime)
+
+	return nil
+}
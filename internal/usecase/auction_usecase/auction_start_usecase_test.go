@@ -0,0 +1,182 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/entity/job_entity"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"fullcycle-auction_go/internal/usecase/job_usecase"
+	"testing"
+	"time"
+)
+
+func TestScheduledAuctionStartsAtStartTime(t *testing.T) {
+	t.Setenv("AUCTION_DURATION", "10m")
+	t.Setenv("JOB_WORKERS", "1")
+	ctx := context.Background()
+
+	fake := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	auctions := memory.NewAuctionRepository(fake)
+	categories := memory.NewCategoryRepository()
+	category, err := category_entity.CreateCategory("Watches", "", nil, fake.Now())
+	if err != nil {
+		t.Fatalf("CreateCategory entity: %v", err)
+	}
+	if err := categories.CreateCategory(ctx, category); err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+
+	jobUseCase := job_usecase.NewJobUseCase(memory.NewJobRepository(), fake, "worker-1")
+	auctionUseCase := NewAuctionUseCase(auctions, memory.NewBidRepository(auctions), categories,
+		nil, NewAuctionClosureScheduler(auctions, fake), jobUseCase, fake)
+
+	input := AuctionInputDTO{
+		ProductName: "Watch",
+		CategoryId:  category.Id,
+		Description: "A description long enough",
+		Condition:   ProductCondition(auction_entity.New),
+	}
+
+	past := fake.Now().Add(-time.Minute)
+	input.StartsAt = &past
+	if _, err := auctionUseCase.CreateAuction(ctx, input); err == nil || err.Err != "bad_request" {
+		t.Fatalf("expected a start time in the past to be refused, got %v", err)
+	}
+
+	startsAt := fake.Now().Add(time.Hour)
+	input.StartsAt = &startsAt
+	if _, err := auctionUseCase.CreateAuction(ctx, input); err != nil {
+		t.Fatalf("CreateAuction: %v", err)
+	}
+
+	scheduled := AuctionStatus(auction_entity.Scheduled)
+	upcoming, err := auctionUseCase.FindAuctions(ctx, AuctionSearchInputDTO{
+		Status: &scheduled, Sort: string(auction_entity.SortStartingSoonest)})
+	if err != nil {
+		t.Fatalf("FindAuctions: %v", err)
+	}
+	if len(upcoming.Auctions) != 1 {
+		t.Fatalf("expected one upcoming auction, got %d", len(upcoming.Auctions))
+	}
+
+	auction := upcoming.Auctions[0]
+	if !auction.EndTime.Equal(auction.StartsAt.Add(10 * time.Minute)) {
+		t.Errorf("expected the auction to end 10m after %v, got %v", auction.StartsAt, auction.EndTime)
+	}
+
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		jobUseCase.Run(runCtx)
+	}()
+	defer func() {
+		stop()
+		<-done
+	}()
+
+	waitForStatus := func(want auction_entity.AuctionStatus) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for {
+			found, err := auctions.FindAuctionById(ctx, auction.Id)
+			if err != nil {
+				t.Fatalf("FindAuctionById: %v", err)
+			}
+			if found.Status == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected status %d, got %d", want, found.Status)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitForWorker(t, fake)
+	fake.Advance(time.Minute)
+	waitForWorker(t, fake)
+	waitForStatus(auction_entity.Scheduled)
+
+	fake.Set(startsAt)
+	waitForStatus(auction_entity.Active)
+}
+
+// waitForWorker waits until the job worker blocks on the clock.
+func waitForWorker(t *testing.T, fake *clock.Fake) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for fake.Timers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("job worker never waited on the clock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStartOverdueAuctionsOpensStuckScheduledAuctions(t *testing.T) {
+	ctx := context.Background()
+	auctionUseCase, auctions, fake := newImageTestUseCase(t)
+
+	createScheduled := func(startsAt time.Time) *auction_entity.Auction {
+		t.Helper()
+
+		auction, err := auction_entity.CreateAuction(
+			"Watch", "4619d391-857f-4036-99eb-192bf17e8313", "A description long enough",
+			auction_entity.New, fake.Now())
+		if err != nil {
+			t.Fatalf("CreateAuction entity: %v", err)
+		}
+		auction.Status = auction_entity.Scheduled
+		auction.StartsAt = startsAt
+		auction.EndTime = startsAt.Add(10 * time.Minute)
+
+		if err := auctions.CreateAuction(ctx, auction); err != nil {
+			t.Fatalf("CreateAuction: %v", err)
+		}
+		return auction
+	}
+
+	// Only the first one is past the grace its start job has.
+	overdue := createScheduled(fake.Now().Add(-2 * time.Minute))
+	recent := createScheduled(fake.Now().Add(-30 * time.Second))
+	upcoming := createScheduled(fake.Now().Add(time.Hour))
+
+	for i := 0; i < 2; i++ {
+		if err := auctionUseCase.ScheduleStartSweep(ctx); err != nil {
+			t.Fatalf("ScheduleStartSweep: %v", err)
+		}
+	}
+	sweeps, err := auctionUseCase.jobUseCase.FindJobs(ctx, nil, StartOverdueAuctionsJob, 1, 10)
+	if err != nil {
+		t.Fatalf("FindJobs: %v", err)
+	}
+	if len(sweeps.Jobs) != 1 || sweeps.Jobs[0].Cron == "" {
+		t.Fatalf("expected a single recurring sweep, got %+v", sweeps.Jobs)
+	}
+
+	if err := auctionUseCase.startOverdueAuctions(ctx, job_entity.Job{}); err != nil {
+		t.Fatalf("startOverdueAuctions: %v", err)
+	}
+
+	for _, expected := range []struct {
+		auction *auction_entity.Auction
+		status  auction_entity.AuctionStatus
+	}{
+		{overdue, auction_entity.Active},
+		{recent, auction_entity.Scheduled},
+		{upcoming, auction_entity.Scheduled},
+	} {
+		found, err := auctions.FindAuctionById(ctx, expected.auction.Id)
+		if err != nil {
+			t.Fatalf("FindAuctionById: %v", err)
+		}
+		if found.Status != expected.status {
+			t.Errorf("auction starting at %v: expected status %d, got %d",
+				expected.auction.StartsAt, expected.status, found.Status)
+		}
+	}
+}
@@ -2,28 +2,73 @@ package auction_usecase
 
 import (
 	"context"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/internal/clock"
 	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/blob_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
 	"fullcycle-auction_go/internal/internal_error"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"fullcycle-auction_go/internal/usecase/job_usecase"
+	"io"
 	"time"
 )
 
 type AuctionInputDTO struct {
 	ProductName string           `json:"product_name" binding:"required,min=1"`
-	Category    string           `json:"category" binding:"required,min=2"`
+	CategoryId  string           `json:"category_id" binding:"required,uuid"`
 	Description string           `json:"description" binding:"required,min=10,max=200"`
 	Condition   ProductCondition `json:"condition" binding:"oneof=0 1 2"`
+
+	// StartsAt announces the auction ahead of time; bids are only accepted
+	// from then on. Without it the auction opens right away.
+	StartsAt *time.Time `json:"starts_at"`
+
+	Attributes map[string]interface{} `json:"attributes" binding:"omitempty,max=30"`
 }
 
 type AuctionOutputDTO struct {
-	Id          string           `json:"id"`
-	ProductName string           `json:"product_name"`
-	Category    string           `json:"category"`
-	Description string           `json:"description"`
-	Condition   ProductCondition `json:"condition"`
-	Status      AuctionStatus    `json:"status"`
-	Timestamp   time.Time        `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	Id          string                  `json:"id"`
+	ProductName string                  `json:"product_name"`
+	CategoryId  string                  `json:"category_id"`
+	Category    string                  `json:"category"`
+	Description string                  `json:"description"`
+	Condition   ProductCondition        `json:"condition"`
+	Status      AuctionStatus           `json:"status"`
+	Timestamp   time.Time               `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	StartsAt    time.Time               `json:"starts_at"`
+	EndTime     time.Time               `json:"end_time"`
+	HighestBid  float64                 `json:"highest_bid"`
+	BidCount    int64                   `json:"bid_count"`
+	Images      []AuctionImageOutputDTO `json:"images"`
+	Attributes  map[string]interface{}  `json:"attributes,omitempty"`
+}
+
+type AuctionExtendInputDTO struct {
+	EndTime time.Time `json:"end_time" binding:"required"`
+}
+
+type AuctionSearchInputDTO struct {
+	Status      *AuctionStatus `form:"status" binding:"omitempty,oneof=0 1 2 3"`
+	Query       string         `form:"q" binding:"omitempty,max=100"`
+	Category    string         `form:"category"`
+	ProductName string         `form:"productName"`
+	CreatedFrom *time.Time     `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   *time.Time     `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort        string         `form:"sort" binding:"omitempty,oneof=newest ending_soonest starting_soonest highest_bid most_bids relevance"`
+	Limit       int64          `form:"limit" binding:"omitempty,min=1,max=100"`
+	After       string         `form:"after"`
+
+	// Attributes holds the raw attr.<key>[_gt|_gte|_lt|_lte] query params,
+	// keyed without the "attr." prefix.
+	Attributes map[string][]string `form:"-"`
+}
+
+type AuctionListOutputDTO struct {
+	Auctions   []AuctionOutputDTO `json:"auctions"`
+	NextCursor string             `json:"next_cursor"`
+	Total      int64              `json:"total"`
 }
 
 type WinningInfoOutputDTO struct {
@@ -33,55 +78,214 @@ type WinningInfoOutputDTO struct {
 
 func NewAuctionUseCase(
 	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface,
-	bidRepositoryInterface bid_entity.BidEntityRepository) AuctionUseCaseInterface {
-	return &AuctionUseCase{
-		auctionRepositoryInterface: auctionRepositoryInterface,
-		bidRepositoryInterface:     bidRepositoryInterface,
+	bidRepositoryInterface bid_entity.BidEntityRepository,
+	categoryRepositoryInterface category_entity.CategoryRepositoryInterface,
+	blobStore blob_entity.BlobStore,
+	closureScheduler *AuctionClosureScheduler,
+	jobUseCase *job_usecase.JobUseCase,
+	clock clock.Clock) AuctionUseCaseInterface {
+	auctionUseCase := &AuctionUseCase{
+		auctionRepositoryInterface:  auctionRepositoryInterface,
+		bidRepositoryInterface:      bidRepositoryInterface,
+		categoryRepositoryInterface: categoryRepositoryInterface,
+		blobStore:                   blobStore,
+		closureScheduler:            closureScheduler,
+		jobUseCase:                  jobUseCase,
+		clock:                       clock,
 	}
+	jobUseCase.RegisterHandler(StartAuctionJob, auctionUseCase.startAuction)
+	jobUseCase.RegisterHandler(StartOverdueAuctionsJob, auctionUseCase.startOverdueAuctions)
+
+	return auctionUseCase
 }
 
 type AuctionUseCaseInterface interface {
 	CreateAuction(
 		ctx context.Context,
-		auctionInput AuctionInputDTO) *internal_error.InternalError
+		auctionInput AuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	ImportAuctions(
+		ctx context.Context,
+		importInput AuctionImportInputDTO) (*AuctionImportOutputDTO, *internal_error.InternalError)
 
 	FindAuctionById(
 		ctx context.Context, id string) (*AuctionOutputDTO, *internal_error.InternalError)
 
 	FindAuctions(
 		ctx context.Context,
-		status AuctionStatus,
-		category, productName string) ([]AuctionOutputDTO, *internal_error.InternalError)
+		searchInput AuctionSearchInputDTO) (*AuctionListOutputDTO, *internal_error.InternalError)
 
 	FindWinningBidByAuctionId(
 		ctx context.Context,
 		auctionId string) (*WinningInfoOutputDTO, *internal_error.InternalError)
+
+	SuggestProductNames(
+		ctx context.Context,
+		prefix string,
+		limit int64) ([]string, *internal_error.InternalError)
+
+	AddAuctionImage(
+		ctx context.Context,
+		auctionId string,
+		data io.Reader) (*AuctionImageOutputDTO, *internal_error.InternalError)
+
+	OpenAuctionImage(
+		ctx context.Context,
+		auctionId, imageId string,
+		thumbnail bool) (io.ReadCloser, string, *internal_error.InternalError)
+
+	RemoveAuctionImage(
+		ctx context.Context,
+		auctionId, imageId string) *internal_error.InternalError
+
+	CancelAuction(
+		ctx context.Context, auctionId string) *internal_error.InternalError
+
+	ForceCloseAuction(
+		ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	RecomputeWinner(
+		ctx context.Context,
+		auctionId string) (*WinningInfoOutputDTO, *internal_error.InternalError)
+
+	ExtendAuction(
+		ctx context.Context,
+		auctionId string,
+		extendInput AuctionExtendInputDTO) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	ExportAuctions(
+		ctx context.Context,
+		exportInput AuctionExportInputDTO,
+		fn func(row AuctionExportDTO) error) *internal_error.InternalError
+
+	ExportBids(
+		ctx context.Context,
+		exportInput AuctionExportInputDTO,
+		fn func(row BidExportDTO) error) *internal_error.InternalError
+
+	ScheduleStartSweep(ctx context.Context) *internal_error.InternalError
 }
 
+const defaultAuctionPageLimit = 20
+
 type ProductCondition int64
 type AuctionStatus int64
 
 type AuctionUseCase struct {
-	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface
-	bidRepositoryInterface     bid_entity.BidEntityRepository
+	auctionRepositoryInterface  auction_entity.AuctionRepositoryInterface
+	bidRepositoryInterface      bid_entity.BidEntityRepository
+	categoryRepositoryInterface category_entity.CategoryRepositoryInterface
+	blobStore                   blob_entity.BlobStore
+	closureScheduler            *AuctionClosureScheduler
+	jobUseCase                  *job_usecase.JobUseCase
+	clock                       clock.Clock
 }
 
 func (au *AuctionUseCase) CreateAuction(
 	ctx context.Context,
-	auctionInput AuctionInputDTO) *internal_error.InternalError {
+	auctionInput AuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError) {
+	category, err := au.categoryRepositoryInterface.FindCategoryById(ctx, auctionInput.CategoryId)
+	if err != nil {
+		if err.Err == "not_found" {
+			return nil, internal_error.NewBadRequestError("Category does not exist")
+		}
+		return nil, err
+	}
+
+	auction, err := au.newAuction(auctionInput, category)
+	if err != nil {
+		return nil, err
+	}
+
+	// The start is enqueued first, so a stored auction never misses it;
+	// should storing the auction fail, the job finds nothing to start.
+	if auction.Status == auction_entity.Scheduled {
+		if err := au.scheduleStart(ctx, auction); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := au.auctionRepositoryInterface.CreateAuction(
+		ctx, auction); err != nil {
+		return nil, err
+	}
+
+	if auction.Status == auction_entity.Active {
+		au.closureScheduler.Schedule(auction.Id, auction.EndTime)
+	}
+
+	auctionOutputDTO := NewAuctionOutputDTO(auction)
+	return &auctionOutputDTO, nil
+}
+
+// newAuction builds the auction described by auctionInput in category,
+// checking its attributes and start time.
+func (au *AuctionUseCase) newAuction(
+	auctionInput AuctionInputDTO,
+	category *category_entity.Category) (*auction_entity.Auction, *internal_error.InternalError) {
+	attributes, err := category.ValidateAttributes(auctionInput.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
 	auction, err := auction_entity.CreateAuction(
 		auctionInput.ProductName,
-		auctionInput.Category,
+		category.Id,
 		auctionInput.Description,
-		auction_entity.ProductCondition(auctionInput.Condition))
+		auction_entity.ProductCondition(auctionInput.Condition),
+		au.clock.Now())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := au.auctionRepositoryInterface.CreateAuction(
-		ctx, auction); err != nil {
+	auction.Category = category.Name
+	auction.Attributes = attributes
+
+	if auctionInput.StartsAt != nil {
+		if !auctionInput.StartsAt.After(auction.Timestamp) {
+			return nil, internal_error.NewBadRequestError("Start time must be in the future")
+		}
+
+		auction.Status = auction_entity.Scheduled
+		auction.StartsAt = *auctionInput.StartsAt
+	}
+
+	auction.EndTime = auction.StartsAt.Add(config.Current().AuctionDuration)
+
+	return auction, nil
+}
+
+func (au *AuctionUseCase) CancelAuction(
+	ctx context.Context, auctionId string) *internal_error.InternalError {
+	cancelledAuction, err := au.auctionRepositoryInterface.CancelAuction(ctx, auctionId)
+	if err != nil {
 		return err
 	}
 
+	au.closureScheduler.Unschedule(auctionId)
+
+	for _, auctionImage := range cancelledAuction.Images {
+		au.deleteImageBlobs(ctx, auctionImage)
+	}
+
 	return nil
 }
+
+func (au *AuctionUseCase) ExtendAuction(
+	ctx context.Context,
+	auctionId string,
+	extendInput AuctionExtendInputDTO) (*AuctionOutputDTO, *internal_error.InternalError) {
+	if !extendInput.EndTime.After(au.clock.Now()) {
+		return nil, internal_error.NewBadRequestError("End time must be in the future")
+	}
+
+	auction, err := au.auctionRepositoryInterface.ExtendAuction(ctx, auctionId, extendInput.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	au.closureScheduler.Schedule(auction.Id, auction.EndTime)
+
+	auctionOutputDTO := NewAuctionOutputDTO(auction)
+	return &auctionOutputDTO, nil
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
 	"fullcycle-auction_go/internal/internal_error"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"strings"
+
+	"github.com/google/uuid"
 )
 
 func (au *AuctionUseCase) FindAuctionById(
@@ -15,41 +19,76 @@ func (au *AuctionUseCase) FindAuctionById(
 		return nil, err
 	}
 
-	return &AuctionOutputDTO{
-		Id:          auctionEntity.Id,
-		ProductName: auctionEntity.ProductName,
-		Category:    auctionEntity.Category,
-		Description: auctionEntity.Description,
-		Condition:   ProductCondition(auctionEntity.Condition),
-		Status:      AuctionStatus(auctionEntity.Status),
-		Timestamp:   auctionEntity.Timestamp,
-	}, nil
+	auctionOutput := NewAuctionOutputDTO(auctionEntity)
+	return &auctionOutput, nil
 }
 
 func (au *AuctionUseCase) FindAuctions(
 	ctx context.Context,
-	status AuctionStatus,
-	category, productName string) ([]AuctionOutputDTO, *internal_error.InternalError) {
-	auctionEntities, err := au.auctionRepositoryInterface.FindAuctions(
-		ctx, auction_entity.AuctionStatus(status), category, productName)
+	searchInput AuctionSearchInputDTO) (*AuctionListOutputDTO, *internal_error.InternalError) {
+	filter := auction_entity.AuctionFilter{
+		Query:       strings.TrimSpace(searchInput.Query),
+		ProductName: searchInput.ProductName,
+		CreatedFrom: searchInput.CreatedFrom,
+		CreatedTo:   searchInput.CreatedTo,
+		Sort:        auction_entity.AuctionSort(searchInput.Sort),
+		Limit:       searchInput.Limit,
+		After:       searchInput.After,
+	}
+
+	if searchInput.Status != nil {
+		status := auction_entity.AuctionStatus(*searchInput.Status)
+		filter.Status = &status
+	}
+
+	var category *category_entity.Category
+	if searchInput.Category != "" {
+		var categoryIds []string
+		var err *internal_error.InternalError
+		category, categoryIds, err = au.findCategoryTree(ctx, searchInput.Category)
+		if err != nil {
+			return nil, err
+		}
+		filter.CategoryIds = categoryIds
+	}
+
+	attributeFilters, err := parseAttributeFilters(searchInput.Attributes, category)
+	if err != nil {
+		return nil, err
+	}
+	filter.Attributes = attributeFilters
+
+	if filter.Sort == "" && filter.Query != "" {
+		filter.Sort = auction_entity.SortRelevance
+	} else if filter.Sort == "" {
+		filter.Sort = auction_entity.SortNewest
+	} else if !filter.Sort.IsValid() {
+		return nil, internal_error.NewBadRequestError("Invalid auction sort option")
+	}
+
+	if filter.Sort == auction_entity.SortRelevance && filter.Query == "" {
+		return nil, internal_error.NewBadRequestError("Sorting by relevance requires a search query")
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuctionPageLimit
+	}
+
+	auctionPage, err := au.auctionRepositoryInterface.FindAuctions(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	var auctionOutputs []AuctionOutputDTO
-	for _, value := range auctionEntities {
-		auctionOutputs = append(auctionOutputs, AuctionOutputDTO{
-			Id:          value.Id,
-			ProductName: value.ProductName,
-			Category:    value.Category,
-			Description: value.Description,
-			Condition:   ProductCondition(value.Condition),
-			Status:      AuctionStatus(value.Status),
-			Timestamp:   value.Timestamp,
-		})
+	auctionOutputs := make([]AuctionOutputDTO, 0, len(auctionPage.Auctions))
+	for _, value := range auctionPage.Auctions {
+		auctionOutputs = append(auctionOutputs, NewAuctionOutputDTO(&value))
 	}
 
-	return auctionOutputs, nil
+	return &AuctionListOutputDTO{
+		Auctions:   auctionOutputs,
+		NextCursor: auctionPage.NextCursor,
+		Total:      auctionPage.Total,
+	}, nil
 }
 
 func (au *AuctionUseCase) FindWinningBidByAuctionId(
@@ -60,15 +99,7 @@ func (au *AuctionUseCase) FindWinningBidByAuctionId(
 		return nil, err
 	}
 
-	auctionOutputDTO := AuctionOutputDTO{
-		Id:          auction.Id,
-		ProductName: auction.ProductName,
-		Category:    auction.Category,
-		Description: auction.Description,
-		Condition:   ProductCondition(auction.Condition),
-		Status:      AuctionStatus(auction.Status),
-		Timestamp:   auction.Timestamp,
-	}
+	auctionOutputDTO := NewAuctionOutputDTO(auction)
 
 	bidWinning, err := au.bidRepositoryInterface.FindWinningBidByAuctionId(ctx, auction.Id)
 	if err != nil {
@@ -79,16 +110,69 @@ func (au *AuctionUseCase) FindWinningBidByAuctionId(
 		}, nil
 	}
 
-	bidOutputDTO := &bid_usecase.BidOutputDTO{
-		Id:        bidWinning.Id,
-		UserId:    bidWinning.UserId,
-		AuctionId: bidWinning.AuctionId,
-		Amount:    bidWinning.Amount,
-		Timestamp: bidWinning.Timestamp,
-	}
+	bidOutputDTO := bid_usecase.NewBidOutputDTO(bidWinning)
 
 	return &WinningInfoOutputDTO{
 		Auction: auctionOutputDTO,
-		Bid:     bidOutputDTO,
+		Bid:     &bidOutputDTO,
 	}, nil
 }
+
+func NewAuctionOutputDTO(auction *auction_entity.Auction) AuctionOutputDTO {
+	images := make([]AuctionImageOutputDTO, 0, len(auction.Images))
+	for position, auctionImage := range auction.Images {
+		images = append(images, NewAuctionImageOutputDTO(auction.Id, auctionImage, position))
+	}
+
+	return AuctionOutputDTO{
+		Id:          auction.Id,
+		ProductName: auction.ProductName,
+		CategoryId:  auction.CategoryId,
+		Category:    auction.Category,
+		Description: auction.Description,
+		Condition:   ProductCondition(auction.Condition),
+		Status:      AuctionStatus(auction.Status),
+		Timestamp:   auction.Timestamp,
+		StartsAt:    auction.StartsAt,
+		EndTime:     auction.EndTime,
+		HighestBid:  auction.HighestBid,
+		BidCount:    auction.BidCount,
+		Images:      images,
+		Attributes:  auction.Attributes,
+	}
+}
+
+func (au *AuctionUseCase) SuggestProductNames(
+	ctx context.Context,
+	prefix string,
+	limit int64) ([]string, *internal_error.InternalError) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return []string{}, nil
+	}
+
+	return au.auctionRepositoryInterface.SuggestProductNames(ctx, prefix, limit)
+}
+
+func (au *AuctionUseCase) findCategoryTree(
+	ctx context.Context,
+	categoryIdOrSlug string) (*category_entity.Category, []string, *internal_error.InternalError) {
+	var category *category_entity.Category
+	var err *internal_error.InternalError
+	if uuid.Validate(categoryIdOrSlug) == nil {
+		category, err = au.categoryRepositoryInterface.FindCategoryById(ctx, categoryIdOrSlug)
+	} else {
+		category, err = au.categoryRepositoryInterface.FindCategoryBySlug(
+			ctx, category_entity.Slugify(categoryIdOrSlug))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	categories, err := au.categoryRepositoryInterface.FindCategories(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return category, category_entity.DescendantIds(categories, category.Id), nil
+}
@@ -0,0 +1,224 @@
+package bid_usecase
+
+import (
+	"container/heap"
+	"context"
+	"expvar"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/infra/lifecycle"
+	"fullcycle-auction_go/internal/internal_error"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+var queueMetrics = expvar.NewMap("bid_queue")
+
+// bidShard batches the bids of the auctions hashed to it. Each auction maps to
+// a single shard, so its bids are stored in the order they were accepted.
+type bidShard struct {
+	queue chan journaledBid
+
+	// reserved counts queued bids plus the ones being journaled, so a bid is
+	// only journaled once it is sure to fit in the queue.
+	reserved atomic.Int64
+
+	// pending holds the bids that failed and are retried with the next batch.
+	pending []journaledBid
+}
+
+func newBidShard(queueSize int) *bidShard {
+	return &bidShard{queue: make(chan journaledBid, queueSize)}
+}
+
+func (s *bidShard) reserve() bool {
+	for {
+		reserved := s.reserved.Load()
+		if reserved >= int64(cap(s.queue)) {
+			return false
+		}
+		if s.reserved.CompareAndSwap(reserved, reserved+1) {
+			return true
+		}
+	}
+}
+
+func (s *bidShard) release() {
+	s.reserved.Add(-1)
+}
+
+// run stores the shard's bids in batches until ctx is canceled, then flushes
+// the ones still queued within the shutdown deadline.
+func (s *bidShard) run(ctx context.Context, bu *BidUseCase) {
+	timer := bu.clock.NewTimer(config.Current().BatchInsertInterval)
+	defer timer.Stop()
+
+	batch := s.pending
+	for {
+		// Bids left over by failed batches keep their place; new ones are
+		// only taken from the queue while the batch has room, so a batch
+		// never exceeds MAX_BATCH_SIZE and a full queue pushes back on
+		// clients.
+		queue := s.queue
+		if len(batch) >= config.Current().MaxBatchSize {
+			queue = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if failed := bu.flushBatch(lifecycle.ShutdownContext(ctx), s.drain(batch)); len(failed) > 0 {
+				logger.Info(fmt.Sprintf("%d bids left in the journal for the next start", len(failed)))
+			}
+			return
+		case bidEntity := <-queue:
+			s.release()
+			batch = append(batch, bidEntity)
+
+			if len(batch) >= config.Current().MaxBatchSize {
+				batch = bu.flushBatch(ctx, batch)
+				timer.Reset(config.Current().BatchInsertInterval)
+			}
+		case <-timer.C():
+			batch = bu.flushBatch(ctx, batch)
+			timer.Reset(config.Current().BatchInsertInterval)
+		}
+	}
+}
+
+// drain appends the bids already queued to batch.
+func (s *bidShard) drain(batch []journaledBid) []journaledBid {
+	for {
+		select {
+		case bidEntity := <-s.queue:
+			s.release()
+			batch = append(batch, bidEntity)
+		default:
+			return batch
+		}
+	}
+}
+
+type queueStats struct {
+	Depth    int `json:"depth"`
+	Capacity int `json:"capacity"`
+}
+
+func publishQueueMetrics(shards []*bidShard) {
+	for i, shard := range shards {
+		shard := shard
+		queueMetrics.Set(fmt.Sprintf("shard_%d", i), expvar.Func(func() interface{} {
+			return queueStats{Depth: len(shard.queue), Capacity: cap(shard.queue)}
+		}))
+	}
+}
+
+func shardIndex(auctionId string, shards int) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(auctionId))
+	return int(hash.Sum32() % uint32(shards))
+}
+
+// commitTracker works out how far the journal can be committed while shards
+// store bids out of LSN order: up to right before the oldest bid not stored.
+type commitTracker struct {
+	mutex       sync.Mutex
+	outstanding lsnHeap
+	stored      map[uint64]bool
+	highest     uint64
+	committed   uint64
+	// appending counts the appends in flight by the highest LSN tracked when
+	// they started, which their own LSN is above.
+	appending map[uint64]int
+}
+
+func newCommitTracker() *commitTracker {
+	return &commitTracker{stored: map[uint64]bool{}, appending: map[uint64]int{}}
+}
+
+// track registers the LSN returned by appendBid. The append, and its fsync,
+// run outside the lock so shards completing batches are not held up by it;
+// while it is in flight, complete stays below the LSN it will get.
+func (ct *commitTracker) track(
+	appendBid func() (uint64, *internal_error.InternalError)) (uint64, *internal_error.InternalError) {
+	ct.mutex.Lock()
+	floor := ct.highest
+	ct.appending[floor]++
+	ct.mutex.Unlock()
+
+	lsn, err := appendBid()
+
+	ct.mutex.Lock()
+	defer ct.mutex.Unlock()
+
+	if ct.appending[floor]--; ct.appending[floor] == 0 {
+		delete(ct.appending, floor)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	ct.add(lsn)
+	return lsn, nil
+}
+
+func (ct *commitTracker) add(lsn uint64) {
+	heap.Push(&ct.outstanding, lsn)
+	if lsn > ct.highest {
+		ct.highest = lsn
+	}
+}
+
+// complete marks lsns as stored and returns the LSN the journal can now be
+// committed up to, if it is past the last commit recorded by advance.
+func (ct *commitTracker) complete(lsns []uint64) (uint64, bool) {
+	ct.mutex.Lock()
+	defer ct.mutex.Unlock()
+
+	for _, lsn := range lsns {
+		ct.stored[lsn] = true
+	}
+	for ct.outstanding.Len() > 0 && ct.stored[ct.outstanding[0]] {
+		delete(ct.stored, heap.Pop(&ct.outstanding).(uint64))
+	}
+
+	watermark := ct.highest
+	if ct.outstanding.Len() > 0 {
+		watermark = ct.outstanding[0] - 1
+	}
+	for floor := range ct.appending {
+		if floor < watermark {
+			watermark = floor
+		}
+	}
+	if watermark <= ct.committed {
+		return 0, false
+	}
+
+	return watermark, true
+}
+
+// advance records that the journal was committed up to lsn. Until then,
+// complete keeps returning it so a failed commit is retried.
+func (ct *commitTracker) advance(lsn uint64) {
+	ct.mutex.Lock()
+	defer ct.mutex.Unlock()
+
+	if lsn > ct.committed {
+		ct.committed = lsn
+	}
+}
+
+type lsnHeap []uint64
+
+func (h lsnHeap) Len() int            { return len(h) }
+func (h lsnHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h lsnHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *lsnHeap) Push(x interface{}) { *h = append(*h, x.(uint64)) }
+func (h *lsnHeap) Pop() interface{} {
+	old := *h
+	lsn := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return lsn
+}
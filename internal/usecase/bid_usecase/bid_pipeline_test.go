@@ -0,0 +1,379 @@
+package bid_usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"fullcycle-auction_go/internal/infra/wal"
+	"fullcycle-auction_go/internal/internal_error"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type pipelineFixture struct {
+	useCase    *BidUseCase
+	auctions   *memory.AuctionRepository
+	bids       *memory.BidRepository
+	users      *memory.UserRepository
+	journal    *wal.BidJournal
+	journalDir string
+	clock      *clock.Fake
+	userId     string
+}
+
+func newPipelineFixture(t *testing.T) *pipelineFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	journalDir := t.TempDir()
+	journal, err := wal.NewBidJournal(journalDir, 1<<20)
+	if err != nil {
+		t.Fatalf("NewBidJournal: %v", err)
+	}
+	t.Cleanup(func() { journal.Close() })
+
+	users := memory.NewUserRepository()
+	user, _ := user_entity.CreateUser("Ana Souza", "ana@example.com")
+	if err := users.CreateUser(ctx, user); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	fake := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	auctions := memory.NewAuctionRepository(fake)
+	bids := memory.NewBidRepository(auctions)
+
+	return &pipelineFixture{
+		useCase:    NewBidUseCase(bids, users, auctions, journal, fake),
+		auctions:   auctions,
+		bids:       bids,
+		users:      users,
+		journal:    journal,
+		journalDir: journalDir,
+		clock:      fake,
+		userId:     user.Id,
+	}
+}
+
+func (f *pipelineFixture) createAuction(t *testing.T) string {
+	t.Helper()
+
+	auction, err := auction_entity.CreateAuction(
+		"Watch", uuid.New().String(), "A description long enough", auction_entity.New, f.clock.Now())
+	if err != nil {
+		t.Fatalf("CreateAuction entity: %v", err)
+	}
+	auction.EndTime = auction.Timestamp.Add(10 * time.Minute)
+	if err := f.auctions.CreateAuction(context.Background(), auction); err != nil {
+		t.Fatalf("CreateAuction: %v", err)
+	}
+
+	return auction.Id
+}
+
+func TestCommitTrackerWaitsForOldestBid(t *testing.T) {
+	tracker := newCommitTracker()
+	for lsn := uint64(1); lsn <= 4; lsn++ {
+		tracker.add(lsn)
+	}
+
+	if lsn, ok := tracker.complete([]uint64{2, 4}); ok {
+		t.Errorf("expected no commit while bid 1 is outstanding, got %d", lsn)
+	}
+	if lsn, ok := tracker.complete([]uint64{1}); !ok || lsn != 2 {
+		t.Errorf("expected commit up to 2, got %d (%v)", lsn, ok)
+	}
+
+	// A commit that failed is not recorded, so it is attempted again.
+	if lsn, ok := tracker.complete(nil); !ok || lsn != 2 {
+		t.Errorf("expected commit up to 2 to be retried, got %d (%v)", lsn, ok)
+	}
+	tracker.advance(2)
+	if lsn, ok := tracker.complete(nil); ok {
+		t.Errorf("expected no commit once 2 was committed, got %d", lsn)
+	}
+
+	if lsn, ok := tracker.complete([]uint64{3}); !ok || lsn != 4 {
+		t.Errorf("expected commit up to 4, got %d (%v)", lsn, ok)
+	}
+}
+
+func TestCommitTrackerWaitsForAppendsInFlight(t *testing.T) {
+	tracker := newCommitTracker()
+	tracker.add(1)
+	if lsn, ok := tracker.complete([]uint64{1}); !ok || lsn != 1 {
+		t.Fatalf("expected commit up to 1, got %d (%v)", lsn, ok)
+	}
+	tracker.advance(1)
+
+	// The first append is given LSN 2 but returns after the second one, which
+	// must neither wait for it nor let the journal be committed past it.
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tracker.track(func() (uint64, *internal_error.InternalError) {
+			close(started)
+			<-release
+			return 2, nil
+		})
+	}()
+	<-started
+
+	if _, err := tracker.track(func() (uint64, *internal_error.InternalError) { return 3, nil }); err != nil {
+		t.Fatalf("track: %v", err)
+	}
+	if lsn, ok := tracker.complete([]uint64{3}); ok {
+		t.Errorf("expected no commit while the append of 2 is in flight, got %d", lsn)
+	}
+
+	close(release)
+	<-done
+	if lsn, ok := tracker.complete([]uint64{2}); !ok || lsn != 3 {
+		t.Errorf("expected commit up to 3, got %d (%v)", lsn, ok)
+	}
+}
+
+func TestCreateBidRejectsWhenQueueIsFull(t *testing.T) {
+	t.Setenv("BID_WORKERS", "1")
+	t.Setenv("BID_QUEUE_SIZE", "2")
+	fixture := newPipelineFixture(t)
+	auctionId := fixture.createAuction(t)
+
+	input := BidInputDTO{UserId: fixture.userId, AuctionId: auctionId, Amount: 10}
+	for i := 0; i < 2; i++ {
+		if err := fixture.useCase.CreateBid(context.Background(), input); err != nil {
+			t.Fatalf("CreateBid: %v", err)
+		}
+	}
+
+	err := fixture.useCase.CreateBid(context.Background(), input)
+	if err == nil || err.Err != "too_many_requests" {
+		t.Fatalf("expected too_many_requests once the queue is full, got %v", err)
+	}
+}
+
+func TestProcessBidsFlushesOnInterval(t *testing.T) {
+	t.Setenv("BID_WORKERS", "1")
+	t.Setenv("MAX_BATCH_SIZE", "100")
+	t.Setenv("BATCH_INSERT_INTERVAL", "20s")
+	fixture := newPipelineFixture(t)
+	auctionId := fixture.createAuction(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fixture.useCase.ProcessBids(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitFor(t, "the batcher to wait on the clock", func() bool { return fixture.clock.Timers() == 1 })
+
+	input := BidInputDTO{UserId: fixture.userId, AuctionId: auctionId, Amount: 10}
+	for i := 0; i < 3; i++ {
+		if err := fixture.useCase.CreateBid(context.Background(), input); err != nil {
+			t.Fatalf("CreateBid: %v", err)
+		}
+	}
+
+	shard := fixture.useCase.shardFor(auctionId)
+	waitFor(t, "the bids to be batched", func() bool { return shard.reserved.Load() == 0 })
+
+	storedBids := func() int {
+		bids, _ := fixture.bids.FindBidByAuctionId(context.Background(), auctionId)
+		return len(bids)
+	}
+
+	fixture.clock.Advance(20*time.Second - time.Millisecond)
+	if stored := storedBids(); stored != 0 {
+		t.Fatalf("expected no bids stored before the interval, got %d", stored)
+	}
+
+	fixture.clock.Advance(time.Millisecond)
+	waitFor(t, "the batch to be flushed", func() bool { return storedBids() == 3 })
+}
+
+func TestProcessBidsKeepsAuctionOrder(t *testing.T) {
+	t.Setenv("BID_WORKERS", "3")
+	t.Setenv("MAX_BATCH_SIZE", "4")
+	fixture := newPipelineFixture(t)
+
+	auctionIds := make([]string, 5)
+	for i := range auctionIds {
+		auctionIds[i] = fixture.createAuction(t)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fixture.useCase.ProcessBids(ctx)
+	}()
+
+	const bidsPerAuction = 20
+	for amount := 1; amount <= bidsPerAuction; amount++ {
+		for _, auctionId := range auctionIds {
+			input := BidInputDTO{UserId: fixture.userId, AuctionId: auctionId, Amount: float64(amount)}
+			if err := fixture.useCase.CreateBid(context.Background(), input); err != nil {
+				t.Fatalf("CreateBid: %v", err)
+			}
+		}
+	}
+
+	cancel()
+	<-done
+
+	for _, auctionId := range auctionIds {
+		bids, _ := fixture.bids.FindBidByAuctionId(context.Background(), auctionId)
+		if len(bids) != bidsPerAuction {
+			t.Fatalf("expected %d bids on auction %s, got %d", bidsPerAuction, auctionId, len(bids))
+		}
+
+		for i, bid := range bids {
+			if bid.Amount != float64(i+1) {
+				t.Errorf("auction %s: expected bids in order, got %s", auctionId, fmt.Sprint(bids))
+				break
+			}
+		}
+	}
+
+	if err := fixture.useCase.CreateBid(context.Background(),
+		BidInputDTO{UserId: fixture.userId, AuctionId: auctionIds[0], Amount: 100}); err == nil ||
+		err.Err != "service_unavailable" {
+		t.Errorf("expected service_unavailable after shutdown, got %v", err)
+	}
+}
+
+// failingBidRepository fails to store the bids of a given amount.
+type failingBidRepository struct {
+	*memory.BidRepository
+	amount float64
+}
+
+func (r *failingBidRepository) CreateBid(
+	ctx context.Context, bids []bid_entity.Bid) ([]bid_entity.BidResult, *internal_error.InternalError) {
+	results := make([]bid_entity.BidResult, len(bids))
+	var stored []bid_entity.Bid
+	var storedIndexes []int
+	for i, bid := range bids {
+		if bid.Amount == r.amount {
+			results[i] = bid_entity.BidResult{BidId: bid.Id, Status: bid_entity.BidFailed,
+				Err: internal_error.NewInternalServerError("write refused")}
+			continue
+		}
+		stored = append(stored, bid)
+		storedIndexes = append(storedIndexes, i)
+	}
+
+	if len(stored) > 0 {
+		storedResults, err := r.BidRepository.CreateBid(ctx, stored)
+		if err != nil {
+			return nil, err
+		}
+		for position, i := range storedIndexes {
+			results[i] = storedResults[position]
+		}
+	}
+
+	return results, nil
+}
+
+func TestProcessBidsDeadLettersBidsThatKeepFailing(t *testing.T) {
+	t.Setenv("BID_WORKERS", "1")
+	t.Setenv("BID_QUEUE_SIZE", "1")
+	t.Setenv("MAX_BATCH_SIZE", "2")
+	t.Setenv("BATCH_INSERT_INTERVAL", "20s")
+	t.Setenv("BID_MAX_ATTEMPTS", "2")
+	fixture := newPipelineFixture(t)
+	auctionId := fixture.createAuction(t)
+
+	useCase := NewBidUseCase(&failingBidRepository{BidRepository: fixture.bids, amount: 13},
+		fixture.users, fixture.auctions, fixture.journal, fixture.clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		useCase.ProcessBids(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitFor(t, "the batcher to wait on the clock", func() bool { return fixture.clock.Timers() == 1 })
+
+	createBid := func(amount float64) *internal_error.InternalError {
+		return useCase.CreateBid(context.Background(),
+			BidInputDTO{UserId: fixture.userId, AuctionId: auctionId, Amount: amount})
+	}
+
+	// Two failing bids fill the batch, so the next bid stays queued and the
+	// one after it finds the queue full.
+	shard := useCase.shardFor(auctionId)
+	for i := 0; i < 2; i++ {
+		if err := createBid(13); err != nil {
+			t.Fatalf("CreateBid: %v", err)
+		}
+		waitFor(t, "the bid to be batched", func() bool { return shard.reserved.Load() == 0 })
+	}
+	if err := createBid(20); err != nil {
+		t.Fatalf("CreateBid: %v", err)
+	}
+	if err := createBid(30); err == nil || err.Err != "too_many_requests" {
+		t.Fatalf("expected too_many_requests while failed bids fill the batch, got %v", err)
+	}
+
+	// The second attempt moves the failing bids to the dead letters, making
+	// room for the queued one.
+	fixture.clock.Advance(20 * time.Second)
+	waitFor(t, "the queued bid to be batched", func() bool { return shard.reserved.Load() == 0 })
+	fixture.clock.Advance(20 * time.Second)
+	waitFor(t, "the queued bid to be stored", func() bool {
+		bids, _ := fixture.bids.FindBidByAuctionId(context.Background(), auctionId)
+		return len(bids) == 1 && bids[0].Amount == 20
+	})
+
+	deadLetters, err := os.ReadFile(filepath.Join(fixture.journalDir, wal.DeadLetterFile))
+	if err != nil {
+		t.Fatalf("reading dead letters: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(deadLetters)), "\n"); len(lines) != 2 ||
+		!strings.Contains(lines[0], `"reason":"write refused"`) {
+		t.Errorf("expected the two failing bids in the dead letters, got %s", deadLetters)
+	}
+
+	replayed := 0
+	fixture.journal.Replay(func(lsn uint64, bid bid_entity.Bid) *internal_error.InternalError {
+		replayed++
+		return nil
+	})
+	if replayed != 0 {
+		t.Errorf("expected the journal to be committed past the dead letters, %d bids left", replayed)
+	}
+}
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -2,11 +2,15 @@ package bid_usecase
 
 import (
 	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
 	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
 	"fullcycle-auction_go/internal/internal_error"
-	"os"
-	"strconv"
+	"sync"
 	"time"
 )
 
@@ -25,33 +29,56 @@ type BidOutputDTO struct {
 }
 
 type BidUseCase struct {
-	BidRepository bid_entity.BidEntityRepository
+	BidRepository     bid_entity.BidEntityRepository
+	UserRepository    user_entity.UserRepositoryInterface
+	AuctionRepository auction_entity.AuctionRepositoryInterface
+	BidJournal        bid_entity.BidJournal
 
-	timer               *time.Timer
-	maxBatchSize        int
-	batchInsertInterval time.Duration
-	bidChannel          chan bid_entity.Bid
+	clock   clock.Clock
+	shards  []*bidShard
+	commits *commitTracker
+	stopped chan struct{}
 }
 
-func NewBidUseCase(bidRepository bid_entity.BidEntityRepository) BidUseCaseInterface {
-	maxSizeInterval := getMaxBatchSizeInterval()
-	maxBatchSize := getMaxBatchSize()
+// journaledBid is a bid waiting in the batch along with its journal LSN and
+// the number of times it failed to be stored.
+type journaledBid struct {
+	bid      bid_entity.Bid
+	lsn      uint64
+	attempts int
+}
+
+// NewBidUseCase replays the bid journal before returning; bids are only
+// stored once ProcessBids is running.
+func NewBidUseCase(
+	bidRepository bid_entity.BidEntityRepository,
+	userRepository user_entity.UserRepositoryInterface,
+	auctionRepository auction_entity.AuctionRepositoryInterface,
+	bidJournal bid_entity.BidJournal,
+	clock clock.Clock) *BidUseCase {
+	shards := make([]*bidShard, config.Current().BidWorkers)
+	queueSize := config.Current().BidQueueSize
+	for i := range shards {
+		shards[i] = newBidShard(queueSize)
+	}
+	publishQueueMetrics(shards)
 
 	bidUseCase := &BidUseCase{
-		BidRepository:       bidRepository,
-		maxBatchSize:        maxBatchSize,
-		batchInsertInterval: maxSizeInterval,
-		timer:               time.NewTimer(maxSizeInterval),
-		bidChannel:          make(chan bid_entity.Bid, maxBatchSize),
+		BidRepository:     bidRepository,
+		UserRepository:    userRepository,
+		AuctionRepository: auctionRepository,
+		BidJournal:        bidJournal,
+		clock:             clock,
+		shards:            shards,
+		commits:           newCommitTracker(),
+		stopped:           make(chan struct{}),
 	}
 
-	bidUseCase.triggerCreateRoutine(context.Background())
+	bidUseCase.replayJournal(context.Background())
 
 	return bidUseCase
 }
 
-var bidBatch []bid_entity.Bid
-
 type BidUseCaseInterface interface {
 	CreateBid(
 		ctx context.Context,
@@ -64,72 +91,198 @@ type BidUseCaseInterface interface {
 		ctx context.Context, auctionId string) ([]BidOutputDTO, *internal_error.InternalError)
 }
 
-func (bu *BidUseCase) triggerCreateRoutine(ctx context.Context) {
-	go func() {
-		defer close(bu.bidChannel)
-
-		for {
-			select {
-			case bidEntity, ok := <-bu.bidChannel:
-				if !ok {
-					if len(bidBatch) > 0 {
-						if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-							logger.Error("error trying to process bid batch list", err)
-						}
-					}
-					return
-				}
-
-				bidBatch = append(bidBatch, bidEntity)
-
-				if len(bidBatch) >= bu.maxBatchSize {
-					if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-						logger.Error("error trying to process bid batch list", err)
-					}
-
-					bidBatch = nil
-					bu.timer.Reset(bu.batchInsertInterval)
-				}
-			case <-bu.timer.C:
-				if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-					logger.Error("error trying to process bid batch list", err)
-				}
-				bidBatch = nil
-				bu.timer.Reset(bu.batchInsertInterval)
-			}
+// ProcessBids runs a worker per shard until ctx is canceled. It then stops
+// accepting bids and waits for the workers to flush the ones still queued.
+func (bu *BidUseCase) ProcessBids(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, shard := range bu.shards {
+		wg.Add(1)
+		go func(shard *bidShard) {
+			defer wg.Done()
+			shard.run(ctx, bu)
+		}(shard)
+	}
+
+	<-ctx.Done()
+	close(bu.stopped)
+	wg.Wait()
+}
+
+// flushBatch stores a batch, MAX_BATCH_SIZE bids at a time, and commits the
+// journal as far as every earlier bid, in any shard, has been stored. The
+// failed bids are returned so they are retried with the next batch; as the
+// journal is not committed past them, a restart replays them too. A bid that
+// failed BID_MAX_ATTEMPTS times is set aside in the journal's dead letters
+// instead. A batch the repository could not process at all, such as while the
+// database is unreachable, is not counted as an attempt of its bids.
+func (bu *BidUseCase) flushBatch(ctx context.Context, batch []journaledBid) []journaledBid {
+	var failed []journaledBid
+	maxBatchSize := config.Current().MaxBatchSize
+	for start := 0; start < len(batch); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(batch) {
+			end = len(batch)
 		}
-	}()
+		failed = append(failed, bu.storeBatch(ctx, batch[start:end])...)
+	}
+
+	return failed
+}
+
+func (bu *BidUseCase) storeBatch(ctx context.Context, batch []journaledBid) []journaledBid {
+	bids := make([]bid_entity.Bid, 0, len(batch))
+	for _, journaled := range batch {
+		bids = append(bids, journaled.bid)
+	}
+
+	results, err := bu.BidRepository.CreateBid(ctx, bids)
+	if err != nil {
+		logger.Error("error trying to process bid batch list", err)
+		return batch
+	}
+
+	maxAttempts := config.Current().BidMaxAttempts
+	var failed []journaledBid
+	var completed []uint64
+	for i, result := range results {
+		journaled := batch[i]
+		if result.Status != bid_entity.BidFailed {
+			completed = append(completed, journaled.lsn)
+			continue
+		}
+
+		journaled.attempts++
+		if journaled.attempts < maxAttempts || bu.deadLetter(journaled, result) != nil {
+			logger.Error(fmt.Sprintf("error trying to store bid %s, retrying with the next batch", result.BidId), result.Err)
+			failed = append(failed, journaled)
+			continue
+		}
+
+		completed = append(completed, journaled.lsn)
+	}
+
+	if lsn, ok := bu.commits.complete(completed); ok {
+		if err := bu.BidJournal.Commit(lsn); err != nil {
+			logger.Error(fmt.Sprintf("error trying to commit the bid journal up to %d, retrying with the next batch", lsn), err)
+		} else {
+			bu.commits.advance(lsn)
+		}
+	}
+
+	return failed
+}
+
+func (bu *BidUseCase) deadLetter(journaled journaledBid, result bid_entity.BidResult) *internal_error.InternalError {
+	reason := "error trying to store bid"
+	if result.Err != nil {
+		reason = result.Err.Message
+	}
+
+	if err := bu.BidJournal.DeadLetter(journaled.bid, reason); err != nil {
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("Bid %s failed %d times and was moved to the dead letters: %s",
+		journaled.bid.Id, journaled.attempts, reason))
+	queueMetrics.Add("dead_lettered", 1)
+	return nil
+}
+
+// replayJournal stores the bids journaled but not committed before the last
+// shutdown. Bids that did reach the repository are skipped by their id, and
+// the ones that fail again are left for their shard to retry.
+func (bu *BidUseCase) replayJournal(ctx context.Context) {
+	var batch []journaledBid
+	replayed := 0
+
+	keepFailed := func(failed []journaledBid) {
+		for _, journaled := range failed {
+			shard := bu.shardFor(journaled.bid.AuctionId)
+			shard.pending = append(shard.pending, journaled)
+		}
+	}
+
+	err := bu.BidJournal.Replay(func(lsn uint64, bid bid_entity.Bid) *internal_error.InternalError {
+		bu.commits.add(lsn)
+		batch = append(batch, journaledBid{bid: bid, lsn: lsn})
+		replayed++
+
+		if len(batch) >= config.Current().MaxBatchSize {
+			keepFailed(bu.flushBatch(ctx, batch))
+			batch = nil
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("error trying to replay bid journal", err)
+	}
+
+	keepFailed(bu.flushBatch(ctx, batch))
+
+	if replayed > 0 {
+		logger.Info(fmt.Sprintf("Replayed %d bids from the journal", replayed))
+	}
+}
+
+func (bu *BidUseCase) shardFor(auctionId string) *bidShard {
+	return bu.shards[shardIndex(auctionId, len(bu.shards))]
 }
 
 func (bu *BidUseCase) CreateBid(
 	ctx context.Context,
 	bidInputDTO BidInputDTO) *internal_error.InternalError {
 
-	bidEntity, err := bid_entity.CreateBid(bidInputDTO.UserId, bidInputDTO.AuctionId, bidInputDTO.Amount)
+	bidEntity, err := bid_entity.CreateBid(
+		bidInputDTO.UserId, bidInputDTO.AuctionId, bidInputDTO.Amount, bu.clock.Now())
 	if err != nil {
 		return err
 	}
 
-	bu.bidChannel <- *bidEntity
+	user, err := bu.UserRepository.FindUserById(ctx, bidEntity.UserId)
+	if err != nil {
+		return err
+	}
 
-	return nil
-}
+	if user.Status == user_entity.Inactive {
+		return internal_error.NewForbiddenError("User is deactivated and cannot place bids")
+	}
 
-func getMaxBatchSizeInterval() time.Duration {
-	batchInsertInterval := os.Getenv("BATCH_INSERT_INTERVAL")
-	duration, err := time.ParseDuration(batchInsertInterval)
+	// Whether a bid is accepted is settled when its batch is stored; only
+	// missing auctions and those that have not opened yet are refused up
+	// front.
+	auctionState, err := bu.AuctionRepository.FindAuctionState(ctx, bidEntity.AuctionId)
 	if err != nil {
-		return 3 * time.Minute
+		return err
 	}
 
-	return duration
-}
+	if auctionState.Status == auction_entity.Scheduled && bidEntity.Timestamp.Before(auctionState.StartsAt) {
+		return internal_error.NewBadRequestError(fmt.Sprintf(
+			"Auction has not started yet, bids open at %s", auctionState.StartsAt.UTC().Format(time.RFC3339)))
+	}
+
+	select {
+	case <-bu.stopped:
+		return internal_error.NewServiceUnavailableError("Server is shutting down and not accepting bids")
+	default:
+	}
+
+	shard := bu.shardFor(bidEntity.AuctionId)
+	if !shard.reserve() {
+		queueMetrics.Add("rejected", 1)
+		return internal_error.NewTooManyRequestsError("Too many bids being processed, try again later")
+	}
 
-func getMaxBatchSize() int {
-	value, err := strconv.Atoi(os.Getenv("MAX_BATCH_SIZE"))
+	lsn, err := bu.commits.track(func() (uint64, *internal_error.InternalError) {
+		return bu.BidJournal.Append(*bidEntity)
+	})
 	if err != nil {
-		return 5
+		shard.release()
+		return err
 	}
 
-	return value
+	// The reservation guarantees room in the queue. A bid queued after its
+	// shard stopped is replayed from the journal on the next start.
+	shard.queue <- journaledBid{bid: *bidEntity, lsn: lsn}
+
+	return nil
 }
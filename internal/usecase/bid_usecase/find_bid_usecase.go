@@ -2,6 +2,7 @@ package bid_usecase
 
 import (
 	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
 	"fullcycle-auction_go/internal/internal_error"
 )
 
@@ -14,13 +15,7 @@ func (bu *BidUseCase) FindBidByAuctionId(
 
 	var bidOutputList []BidOutputDTO
 	for _, bid := range bidList {
-		bidOutputList = append(bidOutputList, BidOutputDTO{
-			Id:        bid.Id,
-			UserId:    bid.UserId,
-			AuctionId: bid.AuctionId,
-			Amount:    bid.Amount,
-			Timestamp: bid.Timestamp,
-		})
+		bidOutputList = append(bidOutputList, NewBidOutputDTO(&bid))
 	}
 
 	return bidOutputList, nil
@@ -33,13 +28,17 @@ func (bu *BidUseCase) FindWinningBidByAuctionId(
 		return nil, err
 	}
 
-	bidOutput := &BidOutputDTO{
-		Id:        bidEntity.Id,
-		UserId:    bidEntity.UserId,
-		AuctionId: bidEntity.AuctionId,
-		Amount:    bidEntity.Amount,
-		Timestamp: bidEntity.Timestamp,
-	}
+	bidOutput := NewBidOutputDTO(bidEntity)
+
+	return &bidOutput, nil
+}
 
-	return bidOutput, nil
+func NewBidOutputDTO(bid *bid_entity.Bid) BidOutputDTO {
+	return BidOutputDTO{
+		Id:        bid.Id,
+		UserId:    bid.UserId,
+		AuctionId: bid.AuctionId,
+		Amount:    bid.Amount,
+		Timestamp: bid.Timestamp,
+	}
 }
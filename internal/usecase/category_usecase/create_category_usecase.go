@@ -0,0 +1,148 @@
+package category_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type CategoryInputDTO struct {
+	Name       string                   `json:"name" binding:"required,min=2,max=60"`
+	ParentId   string                   `json:"parent_id" binding:"omitempty,uuid"`
+	Attributes []AttributeDefinitionDTO `json:"attributes" binding:"omitempty,max=30,dive"`
+}
+
+type CategoryUpdateInputDTO struct {
+	Name       *string                   `json:"name" binding:"omitempty,min=2,max=60"`
+	ParentId   *string                   `json:"parent_id" binding:"omitempty,uuid"`
+	Attributes *[]AttributeDefinitionDTO `json:"attributes" binding:"omitempty,max=30,dive"`
+}
+
+type AttributeDefinitionDTO struct {
+	Key      string   `json:"key" binding:"required,max=40"`
+	Type     string   `json:"type" binding:"required,oneof=string number boolean"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty" binding:"omitempty,max=50,dive,min=1,max=60"`
+}
+
+type CategoryOutputDTO struct {
+	Id         string                   `json:"id"`
+	Name       string                   `json:"name"`
+	Slug       string                   `json:"slug"`
+	ParentId   string                   `json:"parent_id,omitempty"`
+	Attributes []AttributeDefinitionDTO `json:"attributes"`
+	Timestamp  time.Time                `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	Children   []CategoryOutputDTO      `json:"children,omitempty"`
+}
+
+func NewCategoryUseCase(
+	categoryRepository category_entity.CategoryRepositoryInterface,
+	auctionRepository auction_entity.AuctionRepositoryInterface,
+	clock clock.Clock) CategoryUseCaseInterface {
+	return &CategoryUseCase{
+		categoryRepository: categoryRepository,
+		auctionRepository:  auctionRepository,
+		clock:              clock,
+	}
+}
+
+type CategoryUseCaseInterface interface {
+	CreateCategory(
+		ctx context.Context,
+		categoryInput CategoryInputDTO) (*CategoryOutputDTO, *internal_error.InternalError)
+
+	UpdateCategory(
+		ctx context.Context,
+		id string,
+		categoryInput CategoryUpdateInputDTO) (*CategoryOutputDTO, *internal_error.InternalError)
+
+	DeleteCategory(
+		ctx context.Context, id string) *internal_error.InternalError
+
+	FindCategoryById(
+		ctx context.Context, id string) (*CategoryOutputDTO, *internal_error.InternalError)
+
+	FindCategoryTree(
+		ctx context.Context) ([]CategoryOutputDTO, *internal_error.InternalError)
+}
+
+type CategoryUseCase struct {
+	categoryRepository category_entity.CategoryRepositoryInterface
+	auctionRepository  auction_entity.AuctionRepositoryInterface
+	clock              clock.Clock
+}
+
+func (cu *CategoryUseCase) CreateCategory(
+	ctx context.Context,
+	categoryInput CategoryInputDTO) (*CategoryOutputDTO, *internal_error.InternalError) {
+	if categoryInput.ParentId != "" {
+		if err := cu.validateParentExists(ctx, categoryInput.ParentId); err != nil {
+			return nil, err
+		}
+	}
+
+	category, err := category_entity.CreateCategory(
+		categoryInput.Name, categoryInput.ParentId, toAttributeDefinitions(categoryInput.Attributes),
+		cu.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cu.categoryRepository.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	categoryOutput := NewCategoryOutputDTO(category)
+	return &categoryOutput, nil
+}
+
+func (cu *CategoryUseCase) validateParentExists(
+	ctx context.Context, parentId string) *internal_error.InternalError {
+	if _, err := cu.categoryRepository.FindCategoryById(ctx, parentId); err != nil {
+		if err.Err == "not_found" {
+			return internal_error.NewBadRequestError("Parent category does not exist")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func NewCategoryOutputDTO(category *category_entity.Category) CategoryOutputDTO {
+	attributes := make([]AttributeDefinitionDTO, 0, len(category.Attributes))
+	for _, definition := range category.Attributes {
+		attributes = append(attributes, AttributeDefinitionDTO{
+			Key:      definition.Key,
+			Type:     string(definition.Type),
+			Required: definition.Required,
+			Options:  definition.Options,
+		})
+	}
+
+	return CategoryOutputDTO{
+		Id:         category.Id,
+		Name:       category.Name,
+		Slug:       category.Slug,
+		ParentId:   category.ParentId,
+		Attributes: attributes,
+		Timestamp:  category.Timestamp,
+	}
+}
+
+func toAttributeDefinitions(
+	attributeInputs []AttributeDefinitionDTO) []category_entity.AttributeDefinition {
+	definitions := make([]category_entity.AttributeDefinition, 0, len(attributeInputs))
+	for _, attributeInput := range attributeInputs {
+		definitions = append(definitions, category_entity.AttributeDefinition{
+			Key:      attributeInput.Key,
+			Type:     category_entity.AttributeType(attributeInput.Type),
+			Required: attributeInput.Required,
+			Options:  attributeInput.Options,
+		})
+	}
+
+	return definitions
+}
@@ -0,0 +1,46 @@
+package category_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/internal_error"
+)
+
+func (cu *CategoryUseCase) FindCategoryById(
+	ctx context.Context, id string) (*CategoryOutputDTO, *internal_error.InternalError) {
+	category, err := cu.categoryRepository.FindCategoryById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryOutput := NewCategoryOutputDTO(category)
+	return &categoryOutput, nil
+}
+
+func (cu *CategoryUseCase) FindCategoryTree(
+	ctx context.Context) ([]CategoryOutputDTO, *internal_error.InternalError) {
+	categories, err := cu.categoryRepository.FindCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := map[string][]CategoryOutputDTO{}
+	for _, category := range categories {
+		children[category.ParentId] = append(children[category.ParentId], NewCategoryOutputDTO(&category))
+	}
+
+	var buildTree func(parentId string, visited map[string]bool) []CategoryOutputDTO
+	buildTree = func(parentId string, visited map[string]bool) []CategoryOutputDTO {
+		nodes := []CategoryOutputDTO{}
+		for _, node := range children[parentId] {
+			if visited[node.Id] {
+				continue
+			}
+			visited[node.Id] = true
+			node.Children = buildTree(node.Id, visited)
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	return buildTree("", map[string]bool{}), nil
+}
@@ -0,0 +1,97 @@
+package category_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/internal_error"
+)
+
+func (cu *CategoryUseCase) UpdateCategory(
+	ctx context.Context,
+	id string,
+	categoryInput CategoryUpdateInputDTO) (*CategoryOutputDTO, *internal_error.InternalError) {
+	category, err := cu.categoryRepository.FindCategoryById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	previousName := category.Name
+	if categoryInput.Name != nil {
+		category.Rename(*categoryInput.Name)
+	}
+
+	if categoryInput.Attributes != nil {
+		category.Attributes = toAttributeDefinitions(*categoryInput.Attributes)
+	}
+
+	if categoryInput.ParentId != nil && *categoryInput.ParentId != category.ParentId {
+		if *categoryInput.ParentId != "" {
+			if err := cu.validateParentExists(ctx, *categoryInput.ParentId); err != nil {
+				return nil, err
+			}
+
+			categories, err := cu.categoryRepository.FindCategories(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, descendantId := range category_entity.DescendantIds(categories, category.Id) {
+				if descendantId == *categoryInput.ParentId {
+					return nil, internal_error.NewBadRequestError(
+						"Category cannot be moved under one of its descendants")
+				}
+			}
+		}
+
+		category.ParentId = *categoryInput.ParentId
+	}
+
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := cu.categoryRepository.UpdateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	// Auctions keep a copy of the category name for search and listing. Should
+	// this fail, repeating the request brings them up to date.
+	if category.Name != previousName {
+		if err := cu.auctionRepository.RenameCategory(ctx, category.Id, category.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	categoryOutput := NewCategoryOutputDTO(category)
+	return &categoryOutput, nil
+}
+
+func (cu *CategoryUseCase) DeleteCategory(
+	ctx context.Context, id string) *internal_error.InternalError {
+	categories, err := cu.categoryRepository.FindCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, category := range categories {
+		if category.ParentId == id {
+			return internal_error.NewConflictError("Category has subcategories and cannot be deleted")
+		}
+	}
+
+	auctionPage, err := cu.auctionRepository.FindAuctions(ctx, auction_entity.AuctionFilter{
+		CategoryIds: []string{id},
+		Sort:        auction_entity.SortNewest,
+		Limit:       1,
+	})
+	if err != nil {
+		return err
+	}
+
+	if auctionPage.Total > 0 {
+		return internal_error.NewConflictError("Category is used by auctions and cannot be deleted")
+	}
+
+	return cu.categoryRepository.DeleteCategory(ctx, id)
+}
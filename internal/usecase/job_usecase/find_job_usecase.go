@@ -0,0 +1,123 @@
+package job_usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fullcycle-auction_go/internal/entity/job_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type JobOutputDTO struct {
+	Id             string          `json:"id"`
+	Type           string          `json:"type"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	Status         JobStatus       `json:"status"`
+	RunAt          time.Time       `json:"run_at" time_format:"2006-01-02 15:04:05"`
+	Cron           string          `json:"cron,omitempty"`
+	IdempotencyKey string          `json:"idempotency_key,omitempty"`
+	Attempts       int             `json:"attempts"`
+	MaxAttempts    int             `json:"max_attempts"`
+	LastError      string          `json:"last_error,omitempty"`
+	ClaimedBy      string          `json:"claimed_by,omitempty"`
+	CreatedAt      time.Time       `json:"created_at" time_format:"2006-01-02 15:04:05"`
+	UpdatedAt      time.Time       `json:"updated_at" time_format:"2006-01-02 15:04:05"`
+}
+
+type JobListOutputDTO struct {
+	Jobs  []JobOutputDTO `json:"jobs"`
+	Page  int64          `json:"page"`
+	Limit int64          `json:"limit"`
+	Total int64          `json:"total"`
+}
+
+type JobStatus int64
+
+func (ju *JobUseCase) FindJobs(
+	ctx context.Context,
+	status *JobStatus,
+	jobType string,
+	page, limit int64) (*JobListOutputDTO, *internal_error.InternalError) {
+	filter := job_entity.JobFilter{
+		Type:  jobType,
+		Page:  page,
+		Limit: limit,
+	}
+
+	if status != nil {
+		jobStatus := job_entity.JobStatus(*status)
+		filter.Status = &jobStatus
+	}
+
+	jobs, total, err := ju.JobRepository.FindJobs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	jobOutputs := make([]JobOutputDTO, 0, len(jobs))
+	for i := range jobs {
+		jobOutputs = append(jobOutputs, *toJobOutputDTO(&jobs[i]))
+	}
+
+	return &JobListOutputDTO{
+		Jobs:  jobOutputs,
+		Page:  page,
+		Limit: limit,
+		Total: total,
+	}, nil
+}
+
+func (ju *JobUseCase) FindJobById(
+	ctx context.Context, id string) (*JobOutputDTO, *internal_error.InternalError) {
+	job, err := ju.JobRepository.FindJobById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toJobOutputDTO(job), nil
+}
+
+// RetryJob runs a failed or cancelled job again right away.
+func (ju *JobUseCase) RetryJob(
+	ctx context.Context, id string) (*JobOutputDTO, *internal_error.InternalError) {
+	job, err := ju.JobRepository.RetryJob(ctx, id, ju.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return toJobOutputDTO(job), nil
+}
+
+func (ju *JobUseCase) CancelJob(
+	ctx context.Context, id string) (*JobOutputDTO, *internal_error.InternalError) {
+	job, err := ju.JobRepository.CancelJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toJobOutputDTO(job), nil
+}
+
+func toJobOutputDTO(job *job_entity.Job) *JobOutputDTO {
+	output := &JobOutputDTO{
+		Id:             job.Id,
+		Type:           job.Type,
+		Status:         JobStatus(job.Status),
+		RunAt:          job.RunAt,
+		Cron:           job.Cron,
+		IdempotencyKey: job.IdempotencyKey,
+		Attempts:       job.Attempts,
+		MaxAttempts:    job.MaxAttempts,
+		LastError:      job.LastError,
+		ClaimedBy:      job.ClaimedBy,
+		CreatedAt:      job.CreatedAt,
+		UpdatedAt:      job.UpdatedAt,
+	}
+
+	// Payloads are opaque to the scheduler; only JSON ones can be shown.
+	if json.Valid(job.Payload) {
+		output.Payload = job.Payload
+	}
+
+	return output
+}
@@ -0,0 +1,219 @@
+package job_usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/job_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sync"
+	"time"
+)
+
+// maxRetryBackoff caps the exponential delay between attempts of a job.
+const maxRetryBackoff = time.Hour
+
+// JobHandler runs a claimed job. Its context is canceled when the job's lease
+// expires, after which another worker may run the job again.
+type JobHandler func(ctx context.Context, job job_entity.Job) error
+
+type JobUseCase struct {
+	JobRepository job_entity.JobRepositoryInterface
+
+	clock    clock.Clock
+	workerId string
+	workers  int
+	leaseTTL time.Duration
+
+	mutex    sync.RWMutex
+	handlers map[string]JobHandler
+}
+
+type JobUseCaseInterface interface {
+	FindJobs(
+		ctx context.Context,
+		status *JobStatus,
+		jobType string,
+		page, limit int64) (*JobListOutputDTO, *internal_error.InternalError)
+
+	FindJobById(
+		ctx context.Context, id string) (*JobOutputDTO, *internal_error.InternalError)
+
+	RetryJob(
+		ctx context.Context, id string) (*JobOutputDTO, *internal_error.InternalError)
+
+	CancelJob(
+		ctx context.Context, id string) (*JobOutputDTO, *internal_error.InternalError)
+}
+
+func NewJobUseCase(
+	jobRepository job_entity.JobRepositoryInterface, clock clock.Clock, workerId string) *JobUseCase {
+	return &JobUseCase{
+		JobRepository: jobRepository,
+		clock:         clock,
+		workerId:      workerId,
+		workers:       config.Current().JobWorkers,
+		leaseTTL:      config.Current().JobLeaseTTL,
+		handlers:      map[string]JobHandler{},
+	}
+}
+
+// RegisterHandler makes jobType runnable by this instance. Jobs of a type
+// with no handler fail their attempts.
+func (ju *JobUseCase) RegisterHandler(jobType string, handler JobHandler) {
+	ju.mutex.Lock()
+	defer ju.mutex.Unlock()
+
+	ju.handlers[jobType] = handler
+}
+
+// Enqueue stores a job built from spec. A spec whose idempotency key was
+// already used returns the job created for it instead.
+func (ju *JobUseCase) Enqueue(
+	ctx context.Context, spec job_entity.JobSpec) (*job_entity.Job, *internal_error.InternalError) {
+	job, err := job_entity.CreateJob(spec, ju.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	job, _, err = ju.JobRepository.CreateJob(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Run claims and runs due jobs on the configured number of workers until ctx
+// is canceled. Each worker drains the due jobs, then waits for the poll
+// interval before looking again.
+func (ju *JobUseCase) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < ju.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ju.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (ju *JobUseCase) work(ctx context.Context) {
+	for {
+		for ju.runNext(ctx) {
+		}
+
+		timer := ju.clock.NewTimer(config.Current().JobPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+	}
+}
+
+// runNext claims the job due the earliest and runs it, reporting whether
+// there was one.
+func (ju *JobUseCase) runNext(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	job, err := ju.JobRepository.ClaimJob(ctx, ju.workerId, ju.clock.Now(), ju.leaseTTL)
+	if err != nil {
+		logger.Error("Error trying to claim job", err)
+		return false
+	}
+
+	if job == nil {
+		return false
+	}
+
+	ju.execute(ctx, job)
+	return true
+}
+
+func (ju *JobUseCase) execute(ctx context.Context, job *job_entity.Job) {
+	runErr := ju.handle(ctx, *job)
+
+	now := ju.clock.Now()
+	job.UpdatedAt = now
+	job.LastError = ""
+	if runErr != nil {
+		job.LastError = runErr.Error()
+	}
+
+	switch {
+	case runErr != nil && job.Attempts < job.MaxAttempts:
+		job.Status = job_entity.Pending
+		job.RunAt = now.Add(ju.backoff(job.Attempts))
+	case job.Cron != "":
+		// A recurring job starts over at its next run, whether this one
+		// succeeded or ran out of attempts.
+		job.Status = job_entity.Pending
+		job.RunAt = job.NextRun(now)
+		job.Attempts = 0
+		if job.RunAt.IsZero() {
+			job.Status = job_entity.Succeeded
+		}
+	case runErr != nil:
+		job.Status = job_entity.Failed
+	default:
+		job.Status = job_entity.Succeeded
+	}
+
+	if runErr != nil {
+		logger.Error(fmt.Sprintf("Job %s (%s) failed attempt %d of %d",
+			job.Id, job.Type, job.Attempts, job.MaxAttempts), runErr)
+	}
+
+	// The outcome is stored even when ctx was canceled mid-run, so the job is
+	// released instead of waiting for its lease to expire.
+	finished, err := ju.JobRepository.FinishJob(context.Background(), job)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to finish job %s", job.Id), err)
+		return
+	}
+
+	if !finished {
+		logger.Info(fmt.Sprintf("Job %s lost its claim before finishing", job.Id))
+	}
+}
+
+func (ju *JobUseCase) handle(ctx context.Context, job job_entity.Job) (err error) {
+	ju.mutex.RLock()
+	handler, ok := ju.handlers[job.Type]
+	ju.mutex.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %s", job.Type)
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, ju.leaseTTL)
+	defer cancel()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job handler panicked: %v", recovered)
+		}
+	}()
+
+	return handler(jobCtx, job)
+}
+
+// backoff doubles the retry delay with every attempt made.
+func (ju *JobUseCase) backoff(attempts int) time.Duration {
+	delay := config.Current().JobRetryBackoff
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return delay
+}
@@ -0,0 +1,165 @@
+package job_usecase
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/job_entity"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"testing"
+	"time"
+)
+
+func newTestJobUseCase(t *testing.T, now time.Time) (*JobUseCase, *clock.Fake) {
+	t.Setenv("JOB_RETRY_BACKOFF", "1s")
+	t.Setenv("JOB_LEASE_TTL", "1m")
+
+	fake := clock.NewFake(now)
+	jobUseCase := NewJobUseCase(memory.NewJobRepository(), fake, "worker-1")
+	return jobUseCase, fake
+}
+
+func TestJobRetriesWithBackoffUntilFailed(t *testing.T) {
+	ctx := context.Background()
+	jobUseCase, fake := newTestJobUseCase(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	calls := 0
+	jobUseCase.RegisterHandler("flaky", func(ctx context.Context, job job_entity.Job) error {
+		calls++
+		return errors.New("boom")
+	})
+
+	job, err := jobUseCase.Enqueue(ctx, job_entity.JobSpec{Type: "flaky", MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	for _, wait := range []time.Duration{0, time.Second, 2 * time.Second} {
+		fake.Advance(wait - time.Millisecond)
+		if wait > 0 && jobUseCase.runNext(ctx) {
+			t.Fatalf("job ran before its backoff of %s elapsed", wait)
+		}
+
+		fake.Advance(time.Millisecond)
+		if !jobUseCase.runNext(ctx) {
+			t.Fatalf("job did not run after a backoff of %s", wait)
+		}
+	}
+
+	stored, err := jobUseCase.JobRepository.FindJobById(ctx, job.Id)
+	if err != nil {
+		t.Fatalf("FindJobById: %v", err)
+	}
+
+	if stored.Status != job_entity.Failed || stored.Attempts != 3 || stored.LastError != "boom" {
+		t.Fatalf("job = %+v, want failed after 3 attempts", stored)
+	}
+
+	fake.Advance(time.Hour)
+	if jobUseCase.runNext(ctx) || calls != 3 {
+		t.Fatalf("failed job ran again, calls = %d", calls)
+	}
+}
+
+func TestRecurringJobIsRescheduled(t *testing.T) {
+	ctx := context.Background()
+	jobUseCase, fake := newTestJobUseCase(t, time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC))
+
+	var runs []time.Time
+	jobUseCase.RegisterHandler("sweep", func(ctx context.Context, job job_entity.Job) error {
+		runs = append(runs, fake.Now())
+		return nil
+	})
+
+	spec := job_entity.JobSpec{Type: "sweep", Cron: "*/5 * * * *", IdempotencyKey: "sweep"}
+	job, err := jobUseCase.Enqueue(ctx, spec)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	again, err := jobUseCase.Enqueue(ctx, spec)
+	if err != nil || again.Id != job.Id {
+		t.Fatalf("Enqueue with the same idempotency key created %v, %v", again, err)
+	}
+
+	if jobUseCase.runNext(ctx) {
+		t.Fatalf("recurring job ran before its first scheduled time")
+	}
+
+	fake.Set(time.Date(2024, 1, 1, 12, 5, 0, 0, time.UTC))
+	if !jobUseCase.runNext(ctx) {
+		t.Fatalf("recurring job did not run at 12:05")
+	}
+
+	stored, err := jobUseCase.JobRepository.FindJobById(ctx, job.Id)
+	if err != nil {
+		t.Fatalf("FindJobById: %v", err)
+	}
+
+	want := time.Date(2024, 1, 1, 12, 10, 0, 0, time.UTC)
+	if stored.Status != job_entity.Pending || !stored.RunAt.Equal(want) || stored.Attempts != 0 {
+		t.Fatalf("job = %+v, want pending at %s", stored, want)
+	}
+
+	fake.Set(want)
+	if !jobUseCase.runNext(ctx) || len(runs) != 2 {
+		t.Fatalf("recurring job ran %d times, want 2", len(runs))
+	}
+}
+
+func TestRunPollsWithClock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Setenv("JOB_WORKERS", "1")
+	t.Setenv("JOB_POLL_INTERVAL", "1s")
+	jobUseCase, fake := newTestJobUseCase(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	ran := make(chan string, 1)
+	jobUseCase.RegisterHandler("later", func(ctx context.Context, job job_entity.Job) error {
+		ran <- job.Id
+		return nil
+	})
+
+	job, err := jobUseCase.Enqueue(ctx, job_entity.JobSpec{
+		Type: "later", RunAt: fake.Now().Add(10 * time.Second)})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		jobUseCase.Run(ctx)
+	}()
+
+	for i := 0; i < 10; i++ {
+		waitForTimer(t, fake)
+		select {
+		case id := <-ran:
+			t.Fatalf("job %s ran after %d polls, before it was due", id, i)
+		default:
+		}
+		fake.Advance(time.Second)
+	}
+
+	select {
+	case id := <-ran:
+		if id != job.Id {
+			t.Fatalf("ran job %s, want %s", id, job.Id)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("job did not run once due")
+	}
+
+	cancel()
+	<-done
+}
+
+func waitForTimer(t *testing.T, fake *clock.Fake) {
+	deadline := time.Now().Add(time.Second)
+	for fake.Timers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("worker never waited on the clock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,150 @@
+package leader_usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type LeaderOutputDTO struct {
+	Name       string    `json:"name"`
+	LeaderId   string    `json:"leader_id"`
+	Token      int64     `json:"token"`
+	ExpiresAt  time.Time `json:"expires_at" time_format:"2006-01-02 15:04:05"`
+	InstanceId string    `json:"instance_id"`
+	IsLeader   bool      `json:"is_leader"`
+}
+
+type LeaderUseCase struct {
+	LeaseRepository lease_entity.LeaseRepositoryInterface
+
+	clock      clock.Clock
+	name       string
+	instanceId string
+	ttl        time.Duration
+}
+
+type LeaderUseCaseInterface interface {
+	FindLeader(ctx context.Context) (*LeaderOutputDTO, *internal_error.InternalError)
+}
+
+func NewLeaderUseCase(
+	leaseRepository lease_entity.LeaseRepositoryInterface,
+	name, instanceId string,
+	clock clock.Clock) *LeaderUseCase {
+	return &LeaderUseCase{
+		LeaseRepository: leaseRepository,
+		clock:           clock,
+		name:            name,
+		instanceId:      instanceId,
+		ttl:             config.Current().LeaderLeaseTTL,
+	}
+}
+
+// term is a period during which this instance holds the lease with token.
+type term struct {
+	token  int64
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (t *term) stop() {
+	t.cancel()
+	<-t.done
+}
+
+// RunElected campaigns for the lease until ctx is canceled, renewing it every
+// third of its TTL and running job while this instance holds it. The job's
+// context is canceled as soon as the lease is lost, or when it could not be
+// renewed in time to be sure it is still held; a later term runs job again.
+// The job gets the term's fence to make its writes under, so those of a job
+// that outlives its lease are refused once the next leader took over. On
+// return the lease is released so another instance takes over right away.
+func (lu *LeaderUseCase) RunElected(
+	ctx context.Context, job func(ctx context.Context, fence lease_entity.Fence)) {
+	interval := lu.ttl / 3
+	timer := lu.clock.NewTimer(interval)
+	defer timer.Stop()
+
+	var current *term
+	var heldUntil time.Time
+	for {
+		attemptedAt := lu.clock.Now()
+		lease, held, err := lu.LeaseRepository.AcquireLease(ctx, lu.name, lu.instanceId, lu.ttl)
+
+		switch {
+		case err != nil:
+			if current != nil && !attemptedAt.Add(interval).Before(heldUntil) {
+				logger.Info(fmt.Sprintf("Could not renew lease %s in time, stepping down", lu.name))
+				current.stop()
+				current = nil
+			}
+		case !held:
+			if current != nil {
+				logger.Info(fmt.Sprintf("Lost lease %s to %s", lu.name, lease.HolderId))
+				current.stop()
+				current = nil
+			}
+		default:
+			heldUntil = attemptedAt.Add(lu.ttl)
+			if current != nil && current.token != lease.Token {
+				current.stop()
+				current = nil
+			}
+			if current == nil {
+				logger.Info(fmt.Sprintf("Elected leader of %s with token %d", lu.name, lease.Token))
+				current = lu.startTerm(lease.Token, job)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if current != nil {
+				current.stop()
+				lu.LeaseRepository.ReleaseLease(context.Background(), lu.name, lu.instanceId, current.token)
+			}
+			return
+		case <-timer.C():
+			timer.Reset(interval)
+		}
+	}
+}
+
+func (lu *LeaderUseCase) startTerm(
+	token int64, job func(ctx context.Context, fence lease_entity.Fence)) *term {
+	ctx, cancel := context.WithCancel(context.Background())
+	current := &term{token: token, cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(current.done)
+		job(ctx, lease_entity.Fence{Lease: lu.name, Token: token})
+	}()
+
+	return current
+}
+
+func (lu *LeaderUseCase) FindLeader(ctx context.Context) (*LeaderOutputDTO, *internal_error.InternalError) {
+	lease, err := lu.LeaseRepository.FindLease(ctx, lu.name)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderId := lease.HolderId
+	if !lease.ExpiresAt.After(lu.clock.Now()) {
+		leaderId = ""
+	}
+
+	return &LeaderOutputDTO{
+		Name:       lease.Name,
+		LeaderId:   leaderId,
+		Token:      lease.Token,
+		ExpiresAt:  lease.ExpiresAt,
+		InstanceId: lu.instanceId,
+		IsLeader:   leaderId == lu.instanceId,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package leader_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunElectedFailsOver(t *testing.T) {
+	t.Setenv("LEADER_LEASE_TTL", "90ms")
+	leases := memory.NewLeaseRepository(clock.Real)
+
+	var running atomic.Int32
+	var mutex sync.Mutex
+	var ranOn []string
+	var fences []lease_entity.Fence
+	job := func(instanceId string) func(ctx context.Context, fence lease_entity.Fence) {
+		return func(ctx context.Context, fence lease_entity.Fence) {
+			if running.Add(1) > 1 {
+				t.Errorf("%s started while another instance was running the job", instanceId)
+			}
+			mutex.Lock()
+			ranOn = append(ranOn, instanceId)
+			fences = append(fences, fence)
+			mutex.Unlock()
+
+			<-ctx.Done()
+			running.Add(-1)
+		}
+	}
+
+	first := NewLeaderUseCase(leases, "closure", "first", clock.Real)
+	second := NewLeaderUseCase(leases, "closure", "second", clock.Real)
+
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	firstDone := make(chan struct{})
+	go func() {
+		defer close(firstDone)
+		first.RunElected(firstCtx, job("first"))
+	}()
+
+	waitForLeader(t, first, "first")
+
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	go second.RunElected(secondCtx, job("second"))
+
+	time.Sleep(200 * time.Millisecond)
+	stopFirst()
+	<-firstDone
+
+	leader := waitForLeader(t, second, "second")
+	if leader.Token != 2 {
+		t.Errorf("expected the failover to bump the token to 2, got %d", leader.Token)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(ranOn) != 2 || ranOn[0] != "first" || ranOn[1] != "second" {
+		t.Errorf("expected the job to run on first then second, got %v", ranOn)
+	}
+	for i, fence := range fences {
+		if want := (lease_entity.Fence{Lease: "closure", Token: int64(i + 1)}); fence != want {
+			t.Errorf("expected term %d to run under %+v, got %+v", i+1, want, fence)
+		}
+	}
+}
+
+func waitForLeader(t *testing.T, useCase *LeaderUseCase, instanceId string) *LeaderOutputDTO {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		leader, err := useCase.FindLeader(context.Background())
+		if err == nil && leader.LeaderId == instanceId && leader.IsLeader {
+			return leader
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("%s was not elected", instanceId)
+	return nil
+}
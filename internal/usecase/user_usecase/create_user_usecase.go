@@ -0,0 +1,111 @@
+package user_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+)
+
+type UserInputDTO struct {
+	Name  string `json:"name" binding:"required,min=2,max=100"`
+	Email string `json:"email" binding:"required,email"`
+}
+
+type UserUpdateInputDTO struct {
+	Name  *string `json:"name" binding:"omitempty,min=2,max=100"`
+	Email *string `json:"email" binding:"omitempty,email"`
+}
+
+type UserOutputDTO struct {
+	Id     string     `json:"id"`
+	Name   string     `json:"name"`
+	Email  string     `json:"email"`
+	Status UserStatus `json:"status"`
+}
+
+type UserListOutputDTO struct {
+	Users []UserOutputDTO `json:"users"`
+	Page  int64           `json:"page"`
+	Limit int64           `json:"limit"`
+	Total int64           `json:"total"`
+}
+
+type UserStatus int64
+
+func NewUserUseCase(
+	userRepository user_entity.UserRepositoryInterface,
+	auctionRepository auction_entity.AuctionRepositoryInterface,
+	bidRepository bid_entity.BidEntityRepository) UserUseCaseInterface {
+	return &UserUseCase{
+		UserRepository:    userRepository,
+		AuctionRepository: auctionRepository,
+		BidRepository:     bidRepository,
+	}
+}
+
+type UserUseCase struct {
+	UserRepository    user_entity.UserRepositoryInterface
+	AuctionRepository auction_entity.AuctionRepositoryInterface
+	BidRepository     bid_entity.BidEntityRepository
+}
+
+type UserUseCaseInterface interface {
+	FindUserById(
+		ctx context.Context,
+		id string) (*UserOutputDTO, *internal_error.InternalError)
+
+	FindUsers(
+		ctx context.Context,
+		name, email string,
+		status *UserStatus,
+		page, limit int64) (*UserListOutputDTO, *internal_error.InternalError)
+
+	CreateUser(
+		ctx context.Context,
+		userInput UserInputDTO) (*UserOutputDTO, *internal_error.InternalError)
+
+	UpdateUser(
+		ctx context.Context,
+		id string,
+		userInput UserUpdateInputDTO) (*UserOutputDTO, *internal_error.InternalError)
+
+	DeactivateUser(
+		ctx context.Context, id string) *internal_error.InternalError
+
+	FindUserBids(
+		ctx context.Context,
+		id string,
+		auctionStatus *auction_usecase.AuctionStatus,
+		page, limit int64) (*UserBidListOutputDTO, *internal_error.InternalError)
+
+	FindUserWonAuctions(
+		ctx context.Context,
+		id string,
+		page, limit int64) (*UserWonAuctionListOutputDTO, *internal_error.InternalError)
+}
+
+func (u *UserUseCase) CreateUser(
+	ctx context.Context, userInput UserInputDTO) (*UserOutputDTO, *internal_error.InternalError) {
+	user, err := user_entity.CreateUser(userInput.Name, userInput.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.UserRepository.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return toUserOutputDTO(user), nil
+}
+
+func toUserOutputDTO(user *user_entity.User) *UserOutputDTO {
+	return &UserOutputDTO{
+		Id:     user.Id,
+		Name:   user.Name,
+		Email:  user.Email,
+		Status: UserStatus(user.Status),
+	}
+}
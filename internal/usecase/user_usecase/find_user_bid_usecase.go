@@ -0,0 +1,158 @@
+package user_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"fullcycle-auction_go/internal/usecase/bid_usecase"
+)
+
+type BidOutcome string
+
+const (
+	Pending   BidOutcome = "pending"
+	Won       BidOutcome = "won"
+	Lost      BidOutcome = "lost"
+	Cancelled BidOutcome = "cancelled"
+)
+
+type UserBidOutputDTO struct {
+	Bid     bid_usecase.BidOutputDTO          `json:"bid"`
+	Auction *auction_usecase.AuctionOutputDTO `json:"auction,omitempty"`
+	Leading bool                              `json:"leading"`
+	Outcome BidOutcome                        `json:"outcome"`
+}
+
+type UserBidListOutputDTO struct {
+	Bids  []UserBidOutputDTO `json:"bids"`
+	Page  int64              `json:"page"`
+	Limit int64              `json:"limit"`
+	Total int64              `json:"total"`
+}
+
+type UserWonAuctionListOutputDTO struct {
+	Auctions []auction_usecase.WinningInfoOutputDTO `json:"auctions"`
+	Page     int64                                  `json:"page"`
+	Limit    int64                                  `json:"limit"`
+	Total    int64                                  `json:"total"`
+}
+
+func (u *UserUseCase) FindUserBids(
+	ctx context.Context,
+	id string,
+	auctionStatus *auction_usecase.AuctionStatus,
+	page, limit int64) (*UserBidListOutputDTO, *internal_error.InternalError) {
+	if _, err := u.UserRepository.FindUserById(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var statusFilter *auction_entity.AuctionStatus
+	if auctionStatus != nil {
+		status := auction_entity.AuctionStatus(*auctionStatus)
+		statusFilter = &status
+	}
+
+	bids, total, err := u.BidRepository.FindBidsByUserId(ctx, id, statusFilter, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	auctions := map[string]*auction_entity.Auction{}
+	winningBids := map[string]*bid_entity.Bid{}
+
+	userBids := make([]UserBidOutputDTO, 0, len(bids))
+	for _, bid := range bids {
+		auction, ok := auctions[bid.AuctionId]
+		if !ok {
+			auction, err = u.AuctionRepository.FindAuctionById(ctx, bid.AuctionId)
+			if err != nil && err.Err != "not_found" {
+				return nil, err
+			}
+
+			auctions[bid.AuctionId] = auction
+		}
+
+		winningBid, ok := winningBids[bid.AuctionId]
+		if !ok {
+			winningBid, err = u.BidRepository.FindWinningBidByAuctionId(ctx, bid.AuctionId)
+			if err != nil && err.Err != "not_found" {
+				return nil, err
+			}
+
+			winningBids[bid.AuctionId] = winningBid
+		}
+
+		userBid := UserBidOutputDTO{
+			Bid:     bid_usecase.NewBidOutputDTO(&bid),
+			Outcome: Pending,
+		}
+
+		isWinning := winningBid != nil && winningBid.Id == bid.Id
+
+		if auction != nil {
+			auctionOutput := auction_usecase.NewAuctionOutputDTO(auction)
+			userBid.Auction = &auctionOutput
+
+			// Only completed auctions have a winner, as in FindUserWonAuctions;
+			// scheduled ones stay pending.
+			switch auction.Status {
+			case auction_entity.Active:
+				userBid.Leading = isWinning
+			case auction_entity.Completed:
+				if isWinning {
+					userBid.Outcome = Won
+				} else {
+					userBid.Outcome = Lost
+				}
+			case auction_entity.Cancelled:
+				userBid.Outcome = Cancelled
+			}
+		}
+
+		userBids = append(userBids, userBid)
+	}
+
+	return &UserBidListOutputDTO{
+		Bids:  userBids,
+		Page:  page,
+		Limit: limit,
+		Total: total,
+	}, nil
+}
+
+func (u *UserUseCase) FindUserWonAuctions(
+	ctx context.Context,
+	id string,
+	page, limit int64) (*UserWonAuctionListOutputDTO, *internal_error.InternalError) {
+	if _, err := u.UserRepository.FindUserById(ctx, id); err != nil {
+		return nil, err
+	}
+
+	winningBids, total, err := u.BidRepository.FindWinningBidsByUserId(ctx, id, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	wonAuctions := make([]auction_usecase.WinningInfoOutputDTO, 0, len(winningBids))
+	for _, winningBid := range winningBids {
+		auction, err := u.AuctionRepository.FindAuctionById(ctx, winningBid.AuctionId)
+		if err != nil {
+			return nil, err
+		}
+
+		bidOutput := bid_usecase.NewBidOutputDTO(&winningBid)
+		wonAuctions = append(wonAuctions, auction_usecase.WinningInfoOutputDTO{
+			Auction: auction_usecase.NewAuctionOutputDTO(auction),
+			Bid:     &bidOutput,
+		})
+	}
+
+	return &UserWonAuctionListOutputDTO{
+		Auctions: wonAuctions,
+		Page:     page,
+		Limit:    limit,
+		Total:    total,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package user_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/clock"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFindUserBidsOutcomes(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	users := memory.NewUserRepository()
+	auctions := memory.NewAuctionRepository(fake)
+	bids := memory.NewBidRepository(auctions)
+	userUseCase := NewUserUseCase(users, auctions, bids)
+
+	user, _ := user_entity.CreateUser("Alice", "alice@example.com")
+	if err := users.CreateUser(ctx, user); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	newAuction := func(product string) *auction_entity.Auction {
+		auction, err := auction_entity.CreateAuction(
+			product, uuid.New().String(), "A description long enough", auction_entity.New, fake.Now())
+		if err != nil {
+			t.Fatalf("CreateAuction entity: %v", err)
+		}
+		auction.EndTime = fake.Now().Add(time.Hour)
+		if err := auctions.CreateAuction(ctx, auction); err != nil {
+			t.Fatalf("CreateAuction: %v", err)
+		}
+
+		if _, err := bids.CreateBid(ctx, []bid_entity.Bid{{
+			Id: uuid.New().String(), UserId: user.Id, AuctionId: auction.Id, Amount: 100, Timestamp: fake.Now(),
+		}}); err != nil {
+			t.Fatalf("CreateBid: %v", err)
+		}
+		return auction
+	}
+
+	active := newAuction("Active Watch")
+	cancelled := newAuction("Cancelled Watch")
+	completed := newAuction("Completed Watch")
+
+	if _, err := auctions.CancelAuction(ctx, cancelled.Id); err != nil {
+		t.Fatalf("CancelAuction: %v", err)
+	}
+	if _, err := auctions.ForceCloseAuction(ctx, completed.Id); err != nil {
+		t.Fatalf("ForceCloseAuction: %v", err)
+	}
+
+	output, err := userUseCase.FindUserBids(ctx, user.Id, nil, 1, 10)
+	if err != nil {
+		t.Fatalf("FindUserBids: %v", err)
+	}
+
+	want := map[string]struct {
+		leading bool
+		outcome BidOutcome
+	}{
+		active.Id:    {true, Pending},
+		cancelled.Id: {false, Cancelled},
+		completed.Id: {false, Won},
+	}
+	if len(output.Bids) != len(want) {
+		t.Fatalf("expected %d bids, got %d", len(want), len(output.Bids))
+	}
+	for _, bid := range output.Bids {
+		expected := want[bid.Bid.AuctionId]
+		if bid.Leading != expected.leading || bid.Outcome != expected.outcome {
+			t.Errorf("auction %s: expected leading %v and outcome %s, got %v and %s",
+				bid.Auction.ProductName, expected.leading, expected.outcome, bid.Leading, bid.Outcome)
+		}
+	}
+}
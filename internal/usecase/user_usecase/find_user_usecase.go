@@ -6,30 +6,6 @@ import (
 	"fullcycle-auction_go/internal/internal_error"
 )
 
-func NewUserUseCase(userRepository user_entity.UserRepositoryInterface) UserUseCaseInterface {
-	return &UserUseCase{
-		userRepository,
-	}
-}
-
-type UserUseCase struct {
-	UserRepository user_entity.UserRepositoryInterface
-}
-
-type UserOutputDTO struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
-}
-
-type UserUseCaseInterface interface {
-	FindUserById(
-		ctx context.Context,
-		id string) (*UserOutputDTO, *internal_error.InternalError)
-	CreateUser(
-		ctx context.Context,
-		user *UserOutputDTO) *internal_error.InternalError
-}
-
 func (u *UserUseCase) FindUserById(
 	ctx context.Context, id string) (*UserOutputDTO, *internal_error.InternalError) {
 	userEntity, err := u.UserRepository.FindUserById(ctx, id)
@@ -37,21 +13,40 @@ func (u *UserUseCase) FindUserById(
 		return nil, err
 	}
 
-	return &UserOutputDTO{
-		Id:   userEntity.Id,
-		Name: userEntity.Name,
-	}, nil
+	return toUserOutputDTO(userEntity), nil
 }
 
-func (u *UserUseCase) CreateUser(
-	ctx context.Context, user *UserOutputDTO) *internal_error.InternalError {
+func (u *UserUseCase) FindUsers(
+	ctx context.Context,
+	name, email string,
+	status *UserStatus,
+	page, limit int64) (*UserListOutputDTO, *internal_error.InternalError) {
+	filter := user_entity.UserFilter{
+		Name:  name,
+		Email: email,
+		Page:  page,
+		Limit: limit,
+	}
 
-	err := u.UserRepository.CreateUser(ctx, &user_entity.User{
-		Id:   user.Id,
-		Name: user.Name,
-	})
+	if status != nil {
+		userStatus := user_entity.UserStatus(*status)
+		filter.Status = &userStatus
+	}
+
+	userEntities, total, err := u.UserRepository.FindUsers(ctx, filter)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+
+	userOutputs := make([]UserOutputDTO, 0, len(userEntities))
+	for _, userEntity := range userEntities {
+		userOutputs = append(userOutputs, *toUserOutputDTO(&userEntity))
+	}
+
+	return &UserListOutputDTO{
+		Users: userOutputs,
+		Page:  page,
+		Limit: limit,
+		Total: total,
+	}, nil
 }
@@ -0,0 +1,63 @@
+package user_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+)
+
+// UpdateUser writes only the fields in the request, and only while the user
+// is active, so it never undoes a concurrent DeactivateUser.
+func (u *UserUseCase) UpdateUser(
+	ctx context.Context,
+	id string,
+	userInput UserUpdateInputDTO) (*UserOutputDTO, *internal_error.InternalError) {
+	var update user_entity.UserProfileUpdate
+
+	// Only the fields in the request are validated: legacy users may lack an
+	// email and still have their name changed.
+	if userInput.Name != nil {
+		name := strings.TrimSpace(*userInput.Name)
+		if err := user_entity.ValidateName(name); err != nil {
+			return nil, err
+		}
+		update.Name = &name
+	}
+
+	if userInput.Email != nil {
+		email := user_entity.NormalizeEmail(*userInput.Email)
+		if err := user_entity.ValidateEmail(email); err != nil {
+			return nil, err
+		}
+		update.Email = &email
+	}
+
+	if err := u.UserRepository.UpdateUserProfile(ctx, id, update); err != nil {
+		if err.Err != "not_found" {
+			return nil, err
+		}
+
+		// Tell a missing user from a deactivated one.
+		user, findErr := u.UserRepository.FindUserById(ctx, id)
+		if findErr != nil {
+			return nil, findErr
+		}
+		if user.Status == user_entity.Inactive {
+			return nil, internal_error.NewBadRequestError("Deactivated users cannot be updated")
+		}
+		return nil, err
+	}
+
+	user, err := u.UserRepository.FindUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUserOutputDTO(user), nil
+}
+
+func (u *UserUseCase) DeactivateUser(
+	ctx context.Context, id string) *internal_error.InternalError {
+	return u.UserRepository.DeactivateUser(ctx, id)
+}